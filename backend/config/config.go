@@ -1,14 +1,100 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	// база часовых поясов для REPORT_TIMEZONE: в образе alpine её нет
+	_ "time/tzdata"
 )
 
 type Config struct {
 	ServerPort  string
 	DatabaseURL string
 	JWTSecret   string
+	// MaxDetourMeters — на сколько метров можно удлинить маршрут курьера,
+	// чтобы добавить ему заказ «по пути».
+	MaxDetourMeters float64
+	// DefaultSpeedKmh — скорость курьера для ETA, пока нет истории координат.
+	DefaultSpeedKmh float64
+	// StopDwell — сколько курьер проводит на каждой точке маршрута.
+	StopDwell time.Duration
+	// QuoteTTL — сколько действует котировка цены.
+	QuoteTTL time.Duration
+	// ZonePolicy — off, flag или reject для заказов вне зон доставки.
+	ZonePolicy string
+	// DispatchInZone — автоназначение только курьерам зоны заказа.
+	DispatchInZone bool
+	// GeofenceRadius — радиус в метрах, внутри которого курьер считается
+	// прибывшим на точку.
+	GeofenceRadius float64
+	// GeofenceDwell — сколько курьер должен пробыть в радиусе.
+	GeofenceDwell time.Duration
+	// GeofenceAutoAdvance — отмечать точку выполненной при прибытии.
+	GeofenceAutoAdvance bool
+	// LocationMaxSpeedKmh — скорость между фиксами, выше которой координаты
+	// считаются подделанными.
+	LocationMaxSpeedKmh float64
+	// LocationTeleportMeters — скачок, который считается телепортом.
+	LocationTeleportMeters float64
+	// LocationMaxClockSkew — насколько время фикса с устройства может
+	// опережать время сервера.
+	LocationMaxClockSkew time.Duration
+	// LocationAnomalyPolicy — flag или reject для подозрительных координат.
+	LocationAnomalyPolicy string
+	// CourierOfflineAfter — через сколько без heartbeat курьер уходит в OFFLINE.
+	CourierOfflineAfter time.Duration
+	// CourierReapInterval — как часто искать пропавших курьеров.
+	CourierReapInterval time.Duration
+	// CourierRetention — сколько хранить данные деактивированного курьера
+	// до безвозвратного удаления.
+	CourierRetention time.Duration
+	// ProofStorageDir — каталог для подписей и фото вручения.
+	ProofStorageDir string
+	// ProofMaxBytes — предельный размер файла подтверждения.
+	ProofMaxBytes int64
+	// TrackingSecret подписывает публичные ссылки отслеживания.
+	TrackingSecret string
+	// TrackingLinkTTL — срок действия ссылки отслеживания.
+	TrackingLinkTTL time.Duration
+	// TrackingLocationCell — размер ячейки в метрах, до которой огрубляется
+	// положение курьера на публичной странице.
+	TrackingLocationCell float64
+	// PublicBaseURL — внешний адрес сервиса для публичных ссылок.
+	PublicBaseURL string
+	// DispatchLeadTime — за сколько до окна доставки заказ отпускается
+	// в автоназначение.
+	DispatchLeadTime time.Duration
+	// DispatchInterval — как часто планировщик ищет заказы к назначению.
+	DispatchInterval time.Duration
+	// SLACheckInterval — как часто сверять заказы с политиками SLA.
+	SLACheckInterval time.Duration
+	// ReportLocation — часовой пояс, в котором отчёты делятся на дни.
+	ReportLocation *time.Location
+	// ReportRefreshInterval — как часто пересчитывать витрину отчётов.
+	ReportRefreshInterval time.Duration
+	// SurgeInterval — как часто пересчитывать множители спроса по зонам.
+	SurgeInterval time.Duration
+	// EarningsBasePay и EarningsPerKm — ставка курьера за заказ и за
+	// километр маршрута, в минимальных единицах EarningsCurrency.
+	EarningsBasePay  int64
+	EarningsPerKm    int64
+	EarningsCurrency string
+	// EarningsReconcileInterval — как часто досчитывать заработок по
+	// доставленным заказам, для которых начисление не записалось.
+	EarningsReconcileInterval time.Duration
+	// IdempotencyTTL — сколько хранится ответ на запрос с
+	// Idempotency-Key.
+	IdempotencyTTL time.Duration
+	// DBQueryTimeout — предел для одного вызова репозитория заказов,
+	// курьеров, пользователей, смен, цен, геозон, проводок и ключей
+	// идемпотентности; 0 — без предела.
+	DBQueryTimeout time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -24,9 +110,283 @@ func LoadConfig() (*Config, error) {
 	if jwt == "" {
 		return nil, errors.New("JWT_SECRET is not set")
 	}
+	maxDetour, err := getFloat("DISPATCH_MAX_DETOUR_M", 2000)
+	if err != nil {
+		return nil, err
+	}
+	speed, err := getFloat("ETA_DEFAULT_SPEED_KMH", 15)
+	if err != nil {
+		return nil, err
+	}
+	dwell, err := getDuration("ETA_STOP_DWELL", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	quoteTTL, err := getDuration("QUOTE_TTL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	zonePolicy := os.Getenv("ZONE_POLICY")
+	switch zonePolicy {
+	case "":
+		zonePolicy = "flag"
+	case "off", "flag", "reject":
+	default:
+		return nil, errors.New("ZONE_POLICY must be one of off, flag, reject")
+	}
+	dispatchInZone, err := getBool("DISPATCH_IN_ZONE", false)
+	if err != nil {
+		return nil, err
+	}
+	geofenceRadius, err := getFloat("GEOFENCE_RADIUS_M", 50)
+	if err != nil {
+		return nil, err
+	}
+	geofenceDwell, err := getDuration("GEOFENCE_DWELL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	geofenceAdvance, err := getBool("GEOFENCE_AUTO_ADVANCE", false)
+	if err != nil {
+		return nil, err
+	}
+	maxSpeed, err := getFloat("LOCATION_MAX_SPEED_KMH", 150)
+	if err != nil {
+		return nil, err
+	}
+	teleport, err := getFloat("LOCATION_TELEPORT_M", 5000)
+	if err != nil {
+		return nil, err
+	}
+	clockSkew, err := getDuration("LOCATION_MAX_CLOCK_SKEW", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if clockSkew < 0 {
+		return nil, errors.New("LOCATION_MAX_CLOCK_SKEW must not be negative")
+	}
+	anomalyPolicy := os.Getenv("LOCATION_ANOMALY_POLICY")
+	switch anomalyPolicy {
+	case "":
+		anomalyPolicy = "reject"
+	case "flag", "reject":
+	default:
+		return nil, errors.New("LOCATION_ANOMALY_POLICY must be one of flag, reject")
+	}
+	offlineAfter, err := getDuration("COURIER_OFFLINE_AFTER", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	reapInterval, err := getDuration("COURIER_REAP_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	retention, err := getDuration("COURIER_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	proofDir := os.Getenv("PROOF_STORAGE_DIR")
+	if proofDir == "" {
+		proofDir = "./data/proofs"
+	}
+	proofMaxMB, err := getFloat("PROOF_MAX_MB", 5)
+	if err != nil {
+		return nil, err
+	}
+	if proofMaxMB <= 0 {
+		return nil, errors.New("PROOF_MAX_MB must be positive")
+	}
+	// ключ ссылок отслеживания не совпадает с ключом JWT: без явного
+	// значения он выводится из JWT_SECRET, а не берётся как есть
+	trackingSecret := os.Getenv("TRACKING_SECRET")
+	switch trackingSecret {
+	case "":
+		trackingSecret = deriveSecret(jwt, "tracking")
+	case jwt:
+		return nil, errors.New("TRACKING_SECRET must differ from JWT_SECRET")
+	}
+	trackingTTL, err := getDuration("TRACKING_LINK_TTL", 72*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if trackingTTL <= 0 {
+		return nil, errors.New("TRACKING_LINK_TTL must be positive")
+	}
+	trackingCell, err := getFloat("TRACKING_LOCATION_CELL_M", 250)
+	if err != nil {
+		return nil, err
+	}
+	leadTime, err := getDuration("DISPATCH_LEAD_TIME", 45*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	dispatchInterval, err := getDuration("DISPATCH_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if dispatchInterval <= 0 {
+		return nil, errors.New("DISPATCH_INTERVAL must be positive")
+	}
+	slaInterval, err := getDuration("SLA_CHECK_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if slaInterval <= 0 {
+		return nil, errors.New("SLA_CHECK_INTERVAL must be positive")
+	}
+	reportTZ := os.Getenv("REPORT_TIMEZONE")
+	if reportTZ == "" {
+		reportTZ = "UTC"
+	}
+	reportLoc, err := time.LoadLocation(reportTZ)
+	if err != nil {
+		return nil, errors.New("REPORT_TIMEZONE must be an IANA time zone, e.g. Europe/Moscow")
+	}
+	reportInterval, err := getDuration("REPORT_REFRESH_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if reportInterval <= 0 {
+		return nil, errors.New("REPORT_REFRESH_INTERVAL must be positive")
+	}
+	surgeInterval, err := getDuration("SURGE_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if surgeInterval <= 0 {
+		return nil, errors.New("SURGE_INTERVAL must be positive")
+	}
+	basePay, err := getFloat("EARNINGS_BASE_PAY", 15000)
+	if err != nil {
+		return nil, err
+	}
+	if basePay < 0 {
+		return nil, errors.New("EARNINGS_BASE_PAY must not be negative")
+	}
+	perKm, err := getFloat("EARNINGS_PER_KM", 1500)
+	if err != nil {
+		return nil, err
+	}
+	if perKm < 0 {
+		return nil, errors.New("EARNINGS_PER_KM must not be negative")
+	}
+	earningsCurrency := os.Getenv("EARNINGS_CURRENCY")
+	if earningsCurrency == "" {
+		earningsCurrency = "RUB"
+	}
+	if len(earningsCurrency) != 3 {
+		return nil, errors.New("EARNINGS_CURRENCY must be a 3-letter currency code")
+	}
+	reconcileInterval, err := getDuration("EARNINGS_RECONCILE_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if reconcileInterval <= 0 {
+		return nil, errors.New("EARNINGS_RECONCILE_INTERVAL must be positive")
+	}
+	idempotencyTTL, err := getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if idempotencyTTL <= 0 {
+		return nil, errors.New("IDEMPOTENCY_TTL must be positive")
+	}
+	queryTimeout, err := getDuration("DB_QUERY_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if queryTimeout < 0 {
+		return nil, errors.New("DB_QUERY_TIMEOUT must not be negative")
+	}
 	return &Config{
-		ServerPort:  port,
-		DatabaseURL: dbURL,
-		JWTSecret:   jwt,
+		ServerPort:      port,
+		DatabaseURL:     dbURL,
+		JWTSecret:       jwt,
+		MaxDetourMeters: maxDetour,
+		DefaultSpeedKmh: speed,
+		StopDwell:       dwell,
+		QuoteTTL:        quoteTTL,
+		ZonePolicy:      zonePolicy,
+		DispatchInZone:  dispatchInZone,
+
+		GeofenceRadius:      geofenceRadius,
+		GeofenceDwell:       geofenceDwell,
+		GeofenceAutoAdvance: geofenceAdvance,
+
+		LocationMaxSpeedKmh:    maxSpeed,
+		LocationTeleportMeters: teleport,
+		LocationMaxClockSkew:   clockSkew,
+		LocationAnomalyPolicy:  anomalyPolicy,
+
+		CourierOfflineAfter: offlineAfter,
+		CourierReapInterval: reapInterval,
+		CourierRetention:    retention,
+
+		ProofStorageDir: proofDir,
+		ProofMaxBytes:   int64(proofMaxMB * (1 << 20)),
+
+		TrackingSecret:       trackingSecret,
+		TrackingLinkTTL:      trackingTTL,
+		TrackingLocationCell: trackingCell,
+		PublicBaseURL:        strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/"),
+
+		DispatchLeadTime: leadTime,
+		DispatchInterval: dispatchInterval,
+		SLACheckInterval: slaInterval,
+
+		ReportLocation:        reportLoc,
+		ReportRefreshInterval: reportInterval,
+		SurgeInterval:         surgeInterval,
+
+		EarningsBasePay:           int64(basePay),
+		EarningsPerKm:             int64(perKm),
+		EarningsCurrency:          strings.ToUpper(earningsCurrency),
+		EarningsReconcileInterval: reconcileInterval,
+
+		IdempotencyTTL: idempotencyTTL,
+		DBQueryTimeout: queryTimeout,
 	}, nil
 }
+
+// deriveSecret выводит из secret отдельный ключ для назначения purpose.
+func deriveSecret(secret, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getFloat(key string, def float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.New(key + " must be a number")
+	}
+	return f, nil
+}
+
+func getDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.New(key + " must be a duration, e.g. 2m")
+	}
+	return d, nil
+}
+
+func getBool(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New(key + " must be true or false")
+	}
+	return b, nil
+}
@@ -3,16 +3,22 @@ package app
 import (
 	"context"
 	"database/sql"
+	"net"
 	"net/http"
 	"time"
 
 	"backend/config"
 	"backend/internal/controller"
+	"backend/internal/entity"
 	"backend/internal/middleware"
 	"backend/internal/repository"
+	"backend/internal/route"
 	"backend/internal/service"
+	"backend/internal/spoofcheck"
+	"backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -21,11 +27,27 @@ type Server struct {
 	logger *zap.Logger
 	db     *sql.DB
 	srv    *http.Server
+
+	reaper     *service.CourierReaper
+	scheduler  *service.DispatchScheduler
+	slaMonitor *service.SLAMonitor
+	refresher  *service.ReportRefresher
+	surge      *service.SurgeUpdater
+	purger     *service.IdempotencyPurger
+	reconciler *service.EarningsReconciler
+	// jobs живёт, пока не вызван stopReaper; создаётся в NewServer, чтобы
+	// Shutdown не гонялся со Start, запущенным в отдельной горутине
+	jobs       context.Context
+	stopReaper context.CancelFunc
+	// cancelRequests отменяет запросы, которые Shutdown не дождался;
+	// тоже создаётся в NewServer
+	cancelRequests context.CancelFunc
 }
 
 func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	router.Use(middleware.RequestID())
 	router.Use(middleware.ZapLogger(logger))
 	router.Use(gin.Recovery())
 
@@ -34,23 +56,148 @@ func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
 	})
 
 
-	userRepo    := repository.NewUserRepository(db)
-	orderRepo   := repository.NewOrderRepository(db, logger)
-	courierRepo := repository.NewCourierRepository(db, logger)
+	userRepo    := repository.NewUserRepository(db, cfg.DBQueryTimeout)
+	orderRepo   := repository.NewOrderRepository(db, cfg.DBQueryTimeout, logger)
+	courierRepo := repository.NewCourierRepository(db, cfg.DBQueryTimeout, logger)
+	pricingRepo := repository.NewPricingRepository(db, cfg.DBQueryTimeout, logger)
+	zoneRepo    := repository.NewZoneRepository(db, logger)
+	geofenceRepo := repository.NewGeofenceRepository(db, cfg.DBQueryTimeout, logger)
+	anomalyRepo  := repository.NewLocationAnomalyRepository(db, logger)
+	shiftRepo    := repository.NewShiftRepository(db, cfg.DBQueryTimeout, logger)
+	clientRepo   := repository.NewClientRepository(db, logger)
+	adminRepo    := repository.NewAdminRepository(db, logger)
+	auditRepo    := repository.NewAuditRepository(db, logger)
+	proofRepo    := repository.NewProofRepository(db, logger)
+	trackingRepo := repository.NewTrackingRepository(db, logger)
+	slaRepo      := repository.NewSLARepository(db, logger)
+	notificationRepo := repository.NewNotificationRepository(db, logger)
+	reportRepo   := repository.NewReportRepository(db, logger)
+	heatmapRepo  := repository.NewHeatmapRepository(db, logger)
+	surgeRepo    := repository.NewSurgeRepository(db, cfg.DBQueryTimeout, logger)
+	ledgerRepo   := repository.NewLedgerRepository(db, cfg.DBQueryTimeout, logger)
+	idempotencyRepo := repository.NewIdempotencyRepository(db, cfg.DBQueryTimeout, logger)
 
 
 	userSvc    := service.NewUserService(userRepo)
-	orderSvc   := service.NewOrderService(orderRepo, courierRepo) 
-	courierSvc := service.NewCourierService(courierRepo, logger)
+	routeSvc   := service.NewRouteService(orderRepo, courierRepo, route.NewPlanner(route.HaversineMatrix{}), logger)
+	etaSvc     := service.NewEtaService(orderRepo, courierRepo, routeSvc, cfg.DefaultSpeedKmh, cfg.StopDwell, logger)
+	zoneSvc    := service.NewZoneService(zoneRepo, logger)
+	pricingSvc := service.NewPricingService(pricingRepo, surgeRepo, zoneSvc, cfg.QuoteTTL, logger)
+	clientSvc  := service.NewClientService(clientRepo, logger)
+	adminSvc   := service.NewAdminService(adminRepo, logger)
+	auditSvc   := service.NewAuditService(auditRepo, logger)
+	proofSvc   := service.NewProofService(proofRepo, storage.NewLocal(cfg.ProofStorageDir), cfg.ProofMaxBytes, logger)
+	earningsSvc := service.NewEarningsService(ledgerRepo, orderRepo, courierRepo, service.EarningsOptions{
+		BasePay:  cfg.EarningsBasePay,
+		PerKm:    cfg.EarningsPerKm,
+		Currency: cfg.EarningsCurrency,
+		Location: cfg.ReportLocation,
+	}, logger)
+	orderSvc   := service.NewOrderService(orderRepo, courierRepo, shiftRepo, routeSvc, etaSvc, pricingSvc, zoneSvc, clientSvc, proofSvc, earningsSvc, service.OrderOptions{
+		MaxDetourMeters:  cfg.MaxDetourMeters,
+		ZonePolicy:       service.ZonePolicy(cfg.ZonePolicy),
+		DispatchInZone:   cfg.DispatchInZone,
+		DispatchLeadTime: cfg.DispatchLeadTime,
+	}, logger)
+	geofenceSvc := service.NewGeofenceService(geofenceRepo, orderRepo, orderSvc, service.GeofenceOptions{
+		Radius:      cfg.GeofenceRadius,
+		Dwell:       cfg.GeofenceDwell,
+		AutoAdvance: cfg.GeofenceAutoAdvance,
+	}, logger)
+	courierSvc := service.NewCourierService(courierRepo, anomalyRepo, etaSvc, geofenceSvc, orderSvc, service.CourierOptions{
+		Limits: spoofcheck.Limits{
+			MaxSpeed:       cfg.LocationMaxSpeedKmh / 3.6,
+			TeleportMeters: cfg.LocationTeleportMeters,
+			MaxClockSkew:   cfg.LocationMaxClockSkew,
+		},
+		RejectAnomalies: cfg.LocationAnomalyPolicy == "reject",
+		Retention:       cfg.CourierRetention,
+	}, logger)
+	shiftSvc := service.NewShiftService(shiftRepo, courierRepo, orderSvc, logger)
+	trackingSvc := service.NewTrackingService(trackingRepo, orderRepo, courierRepo, service.TrackingOptions{
+		Secret:       []byte(cfg.TrackingSecret),
+		TTL:          cfg.TrackingLinkTTL,
+		LocationCell: cfg.TrackingLocationCell,
+	}, logger)
+	reaper := service.NewCourierReaper(courierRepo, orderSvc, cfg.CourierOfflineAfter, cfg.CourierReapInterval, logger)
+	scheduler := service.NewDispatchScheduler(orderRepo, orderSvc, cfg.DispatchLeadTime, cfg.DispatchInterval, logger)
+	notificationSvc := service.NewNotificationService(notificationRepo, adminRepo, logger)
+	slaSvc := service.NewSLAService(slaRepo, logger)
+	slaMonitor := service.NewSLAMonitor(slaRepo, notificationSvc, cfg.SLACheckInterval, logger)
+	reportSvc := service.NewReportService(reportRepo, cfg.ReportLocation, logger)
+	refresher := service.NewReportRefresher(reportRepo, cfg.ReportRefreshInterval, logger)
+	heatmapSvc := service.NewHeatmapService(heatmapRepo, zoneSvc, logger)
+	surge := service.NewSurgeUpdater(surgeRepo, pricingRepo, cfg.SurgeInterval, logger)
+	purger := service.NewIdempotencyPurger(idempotencyRepo, time.Hour, logger)
+	reconciler := service.NewEarningsReconciler(ledgerRepo, orderRepo, earningsSvc, cfg.EarningsReconcileInterval, logger)
 
 
 	userCtrl    := controller.NewUserController(userSvc, cfg.JWTSecret)
 	orderCtrl   := controller.NewOrderController(orderSvc)
-	courierCtrl := controller.NewCourierController(courierSvc)
+	courierCtrl := controller.NewCourierController(courierSvc, routeSvc)
+	pricingCtrl := controller.NewPricingController(pricingSvc)
+	zoneCtrl    := controller.NewZoneController(zoneSvc)
+	shiftCtrl   := controller.NewShiftController(shiftSvc)
+	clientCtrl  := controller.NewClientController(clientSvc)
+	adminCtrl   := controller.NewAdminController(adminSvc)
+	auditCtrl   := controller.NewAuditController(auditSvc)
+	proofCtrl   := controller.NewProofController(proofSvc)
+	trackingCtrl := controller.NewTrackingController(trackingSvc, cfg.PublicBaseURL)
+	slaCtrl     := controller.NewSLAController(slaSvc)
+	notificationCtrl := controller.NewNotificationController(notificationSvc)
+	reportCtrl  := controller.NewReportController(reportSvc)
+	heatmapCtrl := controller.NewHeatmapController(heatmapSvc)
+	earningsCtrl := controller.NewEarningsController(earningsSvc)
+
+	// журнал пишется для всех маршрутов ниже; состояние до и после
+	// запроса снимается для сущностей из списка
+	router.Use(middleware.Identify(cfg.JWTSecret))
+	// повтор по ключу идемпотентности отдаётся до журнала: он ничего не
+	// меняет
+	// самый крупный запрос — загрузка фото подтверждения; 1 МиБ сверху —
+	// на поля и разметку multipart
+	router.Use(middleware.Idempotency(idempotencyRepo, cfg.IdempotencyTTL, cfg.ProofMaxBytes+1<<20, logger))
+	router.Use(middleware.Audit(auditSvc, map[string]middleware.Snapshot{
+		"orders":   func(ctx context.Context, id uuid.UUID) (any, error) { return orderSvc.GetOrderByID(ctx, id) },
+		"couriers": func(ctx context.Context, id uuid.UUID) (any, error) { return courierSvc.GetCourierByID(ctx, id) },
+		"shifts":   func(ctx context.Context, id uuid.UUID) (any, error) { return shiftSvc.GetShift(ctx, id) },
+		"zones":    func(ctx context.Context, id uuid.UUID) (any, error) { return zoneSvc.GetZone(ctx, id) },
+		"admins":   func(ctx context.Context, id uuid.UUID) (any, error) { return adminSvc.GetAdmin(ctx, id) },
+	}, logger))
 
 	registerUserRoutes(router, userCtrl)
 	registerOrderRoutes(router, orderCtrl)
-	registerCourierRoutes(router, courierCtrl)
+	registerCourierRoutes(router, courierCtrl, shiftCtrl)
+	registerPricingRoutes(router, pricingCtrl)
+	router.GET("/track/:token", trackingCtrl.Track)
+
+	me := router.Group("/me", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(entity.RoleClient))
+	registerClientRoutes(me, clientCtrl, proofCtrl, trackingCtrl)
+	me.POST("/orders/:id/tip", earningsCtrl.Tip)
+
+	courierMe := router.Group("/me", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(entity.RoleCourier))
+	courierMe.GET("/earnings", earningsCtrl.MyEarnings)
+	// точки закрывает только курьер, назначенный на заказ
+	courierMe.POST("/orders/:id/stops/:stop_id/complete", orderCtrl.CompleteStop)
+
+	admin := router.Group("/admin", middleware.Auth(cfg.JWTSecret), middleware.RequireRole(entity.RoleAdmin))
+	can := func(p entity.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(adminSvc, p)
+	}
+	registerAdminOrderRoutes(admin, orderCtrl, proofCtrl, can)
+	registerAdminPricingRoutes(admin, pricingCtrl, can)
+	registerAdminZoneRoutes(admin, zoneCtrl, can)
+	admin.GET("/location-anomalies", can(entity.PermCouriersRead), courierCtrl.GetLocationAnomalies)
+	registerAdminCourierRoutes(admin, courierCtrl, can)
+	registerAdminShiftRoutes(admin, shiftCtrl, can)
+	registerAdminAccessRoutes(admin, adminCtrl, can)
+	admin.GET("/audit", can(entity.PermAuditRead), auditCtrl.ListEvents)
+	registerAdminSLARoutes(admin, slaCtrl, can)
+	admin.GET("/notifications", can(entity.PermOrdersRead), notificationCtrl.List)
+	admin.POST("/notifications/:id/read", can(entity.PermOrdersRead), notificationCtrl.MarkRead)
+	registerAdminReportRoutes(admin, reportCtrl, can)
+	admin.GET("/heatmap", can(entity.PermOrdersRead), heatmapCtrl.GetHeatmap)
+	registerAdminEarningsRoutes(admin, earningsCtrl, can)
 
 
 	httpSrv := &http.Server{
@@ -61,17 +208,48 @@ func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
 		MaxHeaderBytes: 1 << 20,
 	}
 
+	jobs, stopReaper := context.WithCancel(context.Background())
+	// контекст запросов живёт отдельно от фоновых задач: при остановке
+	// запросам дают завершиться, и только потом обрывают их обращения к базе
+	reqCtx, cancelRequests := context.WithCancel(context.Background())
+	httpSrv.BaseContext = func(net.Listener) context.Context { return reqCtx }
+
 	return &Server{
-		cfg:    cfg,
-		logger: logger,
-		db:     db,
-		srv:    httpSrv,
+		cfg:        cfg,
+		logger:     logger,
+		db:         db,
+		srv:        httpSrv,
+		reaper:     reaper,
+		scheduler:  scheduler,
+		slaMonitor: slaMonitor,
+		refresher:  refresher,
+		surge:      surge,
+		purger:     purger,
+		reconciler: reconciler,
+		jobs:       jobs,
+		stopReaper: stopReaper,
+		cancelRequests: cancelRequests,
 	}
 }
 
 
-func (s *Server) Start() error               { return s.srv.ListenAndServe() }
-func (s *Server) Shutdown(ctx context.Context) error { return s.srv.Shutdown(ctx) }
+func (s *Server) Start() error {
+	go s.reaper.Run(s.jobs)
+	go s.scheduler.Run(s.jobs)
+	go s.slaMonitor.Run(s.jobs)
+	go s.refresher.Run(s.jobs)
+	go s.surge.Run(s.jobs)
+	go s.purger.Run(s.jobs)
+	go s.reconciler.Run(s.jobs)
+	return s.srv.ListenAndServe()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopReaper()
+	err := s.srv.Shutdown(ctx)
+	s.cancelRequests()
+	return err
+}
 
 
 
@@ -86,17 +264,150 @@ func registerOrderRoutes(r *gin.Engine, oc *controller.OrderController) {
 		orders.POST("", oc.CreateOrder)
 		orders.GET("", oc.GetOrders)
 		orders.GET("/:id", oc.GetOrder)
+		orders.GET("/:id/timeline", oc.GetTimeline)
 		orders.PUT("/:id", oc.UpdateOrder)
 		orders.DELETE("/:id", oc.DeleteOrder)
+		orders.POST("/:id/assign", oc.AssignCourier)
 	}
 }
 
-func registerCourierRoutes(r *gin.Engine, cc *controller.CourierController) {
+func registerCourierRoutes(r *gin.Engine, cc *controller.CourierController, sc *controller.ShiftController) {
 	couriers := r.Group("/couriers")
 	{
 		couriers.GET("/nearest", cc.FindNearestCouriers)
 		couriers.GET("/:id", cc.GetCourier)
 		couriers.PUT("/:id/status", cc.UpdateStatus)
 		couriers.PUT("/:id/location", cc.UpdateLocation)
+		couriers.POST("/:id/heartbeat", cc.Heartbeat)
+		couriers.POST("/:id/shift/start", sc.StartShift)
+		couriers.POST("/:id/shift/end", sc.EndShift)
+		couriers.GET("/:id/route", cc.GetRoute)
+	}
+}
+
+func registerPricingRoutes(r *gin.Engine, pc *controller.PricingController) {
+	quotes := r.Group("/quotes")
+	{
+		quotes.POST("", pc.CreateQuote)
+		quotes.GET("/:id", pc.GetQuote)
+	}
+}
+
+func registerClientRoutes(me *gin.RouterGroup, cc *controller.ClientController, pc *controller.ProofController, tc *controller.TrackingController) {
+	me.GET("/profile", cc.GetProfile)
+	me.PUT("/profile", cc.UpdateProfile)
+	addresses := me.Group("/addresses")
+	{
+		addresses.GET("", cc.ListAddresses)
+		addresses.POST("", cc.CreateAddress)
+		addresses.PUT("/:id", cc.UpdateAddress)
+		addresses.DELETE("/:id", cc.DeleteAddress)
+	}
+	me.GET("/orders/:id/delivery-code", pc.GetDeliveryCode)
+	me.POST("/orders/:id/tracking-link", tc.CreateLink)
+	me.DELETE("/orders/:id/tracking-link", tc.RevokeLinks)
+}
+
+// permCheck строит middleware проверки права администратора.
+type permCheck func(entity.Permission) gin.HandlerFunc
+
+func registerAdminOrderRoutes(admin *gin.RouterGroup, oc *controller.OrderController, pc *controller.ProofController, can permCheck) {
+	orders := admin.Group("/orders")
+	{
+		orders.GET("", can(entity.PermOrdersRead), oc.GetOrders)
+		orders.GET("/:id", can(entity.PermOrdersRead), oc.GetOrder)
+		orders.GET("/:id/timeline", can(entity.PermOrdersRead), oc.GetTimeline)
+		orders.POST("/:id/cancel", can(entity.PermOrdersCancel), oc.CancelOrder)
+		// подпись и фото получателя — персональные данные, без прав не отдаём
+		orders.GET("/:id/proof", can(entity.PermOrdersRead), pc.GetProofs)
+		orders.GET("/:id/proof/:proof_id/file", can(entity.PermOrdersRead), pc.GetProofFile)
+	}
+}
+
+func registerAdminPricingRoutes(admin *gin.RouterGroup, pc *controller.PricingController, can permCheck) {
+	admin.GET("/pricing/rules", can(entity.PermPricingRead), pc.GetRules)
+	admin.PUT("/pricing/rules", can(entity.PermPricingEdit), pc.UpdateRules)
+	admin.GET("/pricing/surge", can(entity.PermPricingRead), pc.ListSurge)
+	admin.PUT("/pricing/surge/:zone_id", can(entity.PermPricingEdit), pc.SetSurgeOverride)
+	admin.DELETE("/pricing/surge/:zone_id", can(entity.PermPricingEdit), pc.ClearSurgeOverride)
+}
+
+func registerAdminZoneRoutes(admin *gin.RouterGroup, zc *controller.ZoneController, can permCheck) {
+	zones := admin.Group("/zones")
+	{
+		zones.GET("", can(entity.PermZonesRead), zc.GetZones)
+		zones.POST("", can(entity.PermZonesManage), zc.CreateZone)
+		zones.POST("/import", can(entity.PermZonesManage), zc.ImportZones)
+		zones.GET("/:id", can(entity.PermZonesRead), zc.GetZone)
+		zones.PUT("/:id", can(entity.PermZonesManage), zc.UpdateZone)
+		zones.DELETE("/:id", can(entity.PermZonesManage), zc.DeleteZone)
+	}
+	admin.PUT("/couriers/:id/zones", can(entity.PermCouriersManage), zc.SetCourierZones)
+}
+
+func registerAdminShiftRoutes(admin *gin.RouterGroup, sc *controller.ShiftController, can permCheck) {
+	shifts := admin.Group("/shifts")
+	{
+		shifts.GET("", can(entity.PermCouriersRead), sc.ListShifts)
+		shifts.POST("", can(entity.PermShiftsManage), sc.PlanShift)
+		shifts.GET("/:id", can(entity.PermCouriersRead), sc.GetShift)
+		shifts.PUT("/:id", can(entity.PermShiftsManage), sc.UpdateShift)
+		shifts.DELETE("/:id", can(entity.PermShiftsManage), sc.DeleteShift)
+		shifts.GET("/:id/summary", can(entity.PermCouriersRead), sc.GetSummary)
+	}
+}
+
+func registerAdminCourierRoutes(admin *gin.RouterGroup, cc *controller.CourierController, can permCheck) {
+	couriers := admin.Group("/couriers")
+	{
+		couriers.GET("", can(entity.PermCouriersRead), cc.ListCouriers)
+		couriers.POST("", can(entity.PermCouriersManage), cc.CreateCourier)
+		couriers.PUT("/:id/vehicle", can(entity.PermCouriersManage), cc.SetVehicle)
+		couriers.POST("/:id/suspend", can(entity.PermCouriersManage), cc.SuspendCourier)
+		couriers.POST("/:id/deactivate", can(entity.PermCouriersManage), cc.DeactivateCourier)
+		couriers.POST("/:id/reactivate", can(entity.PermCouriersManage), cc.ReactivateCourier)
+		couriers.DELETE("/:id", can(entity.PermCouriersManage), cc.DeleteCourier)
+	}
+}
+
+func registerAdminSLARoutes(admin *gin.RouterGroup, sc *controller.SLAController, can permCheck) {
+	sla := admin.Group("/sla")
+	{
+		sla.GET("/policies", can(entity.PermOrdersRead), sc.ListPolicies)
+		sla.PUT("/policies/:metric", can(entity.PermSLAManage), sc.UpdatePolicy)
+		sla.GET("/breaches", can(entity.PermOrdersRead), sc.ListBreaches)
+		sla.GET("/at-risk", can(entity.PermOrdersRead), sc.ListAtRisk)
+	}
+}
+
+func registerAdminReportRoutes(admin *gin.RouterGroup, rc *controller.ReportController, can permCheck) {
+	reports := admin.Group("/reports", can(entity.PermReportsRead))
+	{
+		reports.GET("/volume", rc.Volume)
+		reports.GET("/timing", rc.Timing)
+		reports.GET("/cancellations", rc.Cancellations)
+	}
+}
+
+func registerAdminEarningsRoutes(admin *gin.RouterGroup, ec *controller.EarningsController, can permCheck) {
+	admin.GET("/couriers/:id/earnings", can(entity.PermCouriersRead), ec.GetCourierEarnings)
+	admin.POST("/couriers/:id/adjustments", can(entity.PermPayoutsManage), ec.Adjust)
+	payouts := admin.Group("/payouts")
+	{
+		payouts.GET("", can(entity.PermPayoutsManage), ec.ListPayouts)
+		payouts.POST("", can(entity.PermPayoutsManage), ec.CreatePayout)
+		payouts.GET("/:id", can(entity.PermPayoutsManage), ec.GetPayout)
+		payouts.GET("/:id/export", can(entity.PermPayoutsManage), ec.ExportPayout)
+	}
+}
+
+func registerAdminAccessRoutes(admin *gin.RouterGroup, ac *controller.AdminController, can permCheck) {
+	admins := admin.Group("/admins", can(entity.PermUsersManage))
+	{
+		admins.GET("", ac.ListAdmins)
+		admins.POST("", ac.CreateAdmin)
+		admins.GET("/:id", ac.GetAdmin)
+		admins.POST("/:id/permissions", ac.GrantPermission)
+		admins.DELETE("/:id/permissions/:permission", ac.RevokePermission)
 	}
 }
@@ -0,0 +1,77 @@
+// Package audit сравнивает состояния сущностей для журнала изменений.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"backend/internal/entity"
+)
+
+// ignored — поля, которые меняются при любой записи и только засоряют
+// журнал.
+var ignored = map[string]bool{
+	"updated_at": true,
+}
+
+// secret — поля, значения которых не должны попасть в журнал: он только
+// дополняется и доступен всем администраторам с audit:read.
+var secret = map[string]bool{
+	"delivery_code": true,
+	"password":      true,
+	"token":         true,
+}
+
+// Diff возвращает поля верхнего уровня JSON-представления, которые
+// отличаются в before и after. nil означает, что сущности не было (до
+// создания или после удаления).
+func Diff(before, after any) (map[string]entity.Change, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entity.Change)
+	for k, v := range from {
+		if ignored[k] || secret[k] {
+			continue
+		}
+		if w, ok := to[k]; !ok || !reflect.DeepEqual(v, w) {
+			changes[k] = entity.Change{From: v, To: to[k]}
+		}
+	}
+	for k, w := range to {
+		if _, ok := from[k]; ok || ignored[k] || secret[k] {
+			continue
+		}
+		changes[k] = entity.Change{From: nil, To: w}
+	}
+	return changes, nil
+}
+
+func fields(v any) (map[string]any, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	var raw []byte
+	switch b := v.(type) {
+	case []byte:
+		raw = b
+	case json.RawMessage:
+		raw = b
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
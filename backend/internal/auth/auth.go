@@ -6,17 +6,20 @@ import (
 
 	"github.com/golang-jwt/jwt"
 	"backend/config"
+	"backend/internal/entity"
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID string      `json:"user_id"`
+	Role   entity.Role `json:"role"`
 	jwt.StandardClaims
 }
 
-func GenerateToken(cfg *config.Config, userID string) (string, error) {
+func GenerateToken(cfg *config.Config, userID string, role entity.Role) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
 		UserID: userID,
+		Role:   role,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 			IssuedAt:  time.Now().Unix(),
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminController struct {
+	adminService service.AdminService
+}
+
+func NewAdminController(adminService service.AdminService) *AdminController {
+	return &AdminController{adminService: adminService}
+}
+
+type CreateAdminRequest struct {
+	Email       string              `json:"email" binding:"required,email"`
+	Password    string              `json:"password" binding:"required,min=8"`
+	Permissions []entity.Permission `json:"permissions"`
+}
+
+func (ac *AdminController) CreateAdmin(c *gin.Context) {
+	var req CreateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	admin, err := ac.adminService.CreateAdmin(c.Request.Context(), req.Email, req.Password, req.Permissions)
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, admin)
+}
+
+func (ac *AdminController) ListAdmins(c *gin.Context) {
+	list, err := ac.adminService.ListAdmins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (ac *AdminController) GetAdmin(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid admin id"})
+		return
+	}
+	admin, err := ac.adminService.GetAdmin(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, admin)
+}
+
+type GrantPermissionRequest struct {
+	Permission entity.Permission `json:"permission" binding:"required"`
+}
+
+func (ac *AdminController) GrantPermission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid admin id"})
+		return
+	}
+	var req GrantPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	admin, err := ac.adminService.Grant(c.Request.Context(), id, req.Permission)
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, admin)
+}
+
+func (ac *AdminController) RevokePermission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid admin id"})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+	admin, err := ac.adminService.Revoke(c.Request.Context(), actorID, id, entity.Permission(c.Param("permission")))
+	if err != nil {
+		c.JSON(adminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, admin)
+}
+
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUnknownPermission):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrAdminNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrEmailTaken),
+		errors.Is(err, service.ErrSelfLockout):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
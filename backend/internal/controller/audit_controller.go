@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditController struct {
+	auditService service.AuditService
+}
+
+func NewAuditController(auditService service.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+type ListAuditRequest struct {
+	ActorID    string     `form:"actor_id" binding:"omitempty,uuid"`
+	EntityType string     `form:"entity_type"`
+	EntityID   string     `form:"entity_id" binding:"omitempty,uuid"`
+	Action     string     `form:"action"`
+	RequestID  string     `form:"request_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int        `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+	Offset     int        `form:"offset" binding:"gte=0"`
+}
+
+func (ac *AuditController) ListEvents(c *gin.Context) {
+	var req ListAuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := repository.AuditFilter{
+		EntityType: req.EntityType,
+		Action:     req.Action,
+		RequestID:  req.RequestID,
+		From:       req.From,
+		To:         req.To,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}
+	if req.ActorID != "" {
+		id := uuid.MustParse(req.ActorID)
+		filter.ActorID = &id
+	}
+	if req.EntityID != "" {
+		id := uuid.MustParse(req.EntityID)
+		filter.EntityID = &id
+	}
+	events, err := ac.auditService.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
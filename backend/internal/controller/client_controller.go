@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ClientController обслуживает /me: клиент видит и меняет только свои
+// данные, идентификатор берётся из токена.
+type ClientController struct {
+	clientService service.ClientService
+}
+
+func NewClientController(clientService service.ClientService) *ClientController {
+	return &ClientController{clientService: clientService}
+}
+
+func (cc *ClientController) GetProfile(c *gin.Context) {
+	userID, _ := middleware.CurrentUserID(c)
+	profile, err := cc.clientService.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(clientErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+type ProfileRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Phone   string `json:"phone" binding:"omitempty,max=50"`
+	Address string `json:"address" binding:"omitempty,max=255"`
+}
+
+func (cc *ClientController) UpdateProfile(c *gin.Context) {
+	var req ProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	profile, err := cc.clientService.UpdateProfile(c.Request.Context(), &entity.Client{
+		UserID:  userID,
+		Name:    req.Name,
+		Phone:   req.Phone,
+		Address: req.Address,
+	})
+	if err != nil {
+		c.JSON(clientErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+type AddressRequest struct {
+	Label     string  `json:"label" binding:"required,max=100"`
+	Address   string  `json:"address" binding:"required,max=255"`
+	Latitude  float64 `json:"latitude" binding:"required"`
+	Longitude float64 `json:"longitude" binding:"required"`
+	Notes     string  `json:"notes"`
+}
+
+func (req AddressRequest) address(clientID uuid.UUID) *entity.ClientAddress {
+	return &entity.ClientAddress{
+		ClientID: clientID,
+		Label:    req.Label,
+		Address:  req.Address,
+		Location: entity.Coordinates{Latitude: req.Latitude, Longitude: req.Longitude},
+		Notes:    req.Notes,
+	}
+}
+
+func (cc *ClientController) ListAddresses(c *gin.Context) {
+	userID, _ := middleware.CurrentUserID(c)
+	list, err := cc.clientService.ListAddresses(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (cc *ClientController) CreateAddress(c *gin.Context) {
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	a := req.address(userID)
+	if err := cc.clientService.CreateAddress(c.Request.Context(), a); err != nil {
+		c.JSON(clientErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+func (cc *ClientController) UpdateAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address id"})
+		return
+	}
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	a := req.address(userID)
+	a.ID = id
+	if err := cc.clientService.UpdateAddress(c.Request.Context(), a); err != nil {
+		c.JSON(clientErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+func (cc *ClientController) DeleteAddress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address id"})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	if err := cc.clientService.DeleteAddress(c.Request.Context(), userID, id); err != nil {
+		c.JSON(clientErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "address deleted"})
+}
+
+func clientErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidProfile),
+		errors.Is(err, service.ErrInvalidAddress):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrClientNotFound),
+		errors.Is(err, repository.ErrAddressNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
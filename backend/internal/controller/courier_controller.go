@@ -1,8 +1,13 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
+
 	"backend/internal/entity"
+	"backend/internal/repository"
 	"backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,11 +15,13 @@ import (
 
 type CourierController struct {
 	service service.CourierService
+	routes  service.RouteService
 }
 
-func NewCourierController(s service.CourierService) *CourierController {
+func NewCourierController(s service.CourierService, routes service.RouteService) *CourierController {
 	return &CourierController{
 		service: s,
+		routes:  routes,
 	}
 }
 
@@ -25,11 +32,12 @@ func (cc *CourierController) GetCourier(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
 		return
 	}
-	courier, err := cc.service.GetCourierByID(id)
+	courier, err := cc.service.GetCourierByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	setETag(c, courier.Version)
 	c.JSON(http.StatusOK, courier)
 }
 
@@ -49,16 +57,24 @@ func (cc *CourierController) UpdateStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := cc.service.UpdateCourierStatus(id, req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if err := cc.service.UpdateCourierStatus(c.Request.Context(), id, req.Status, version); err != nil {
+		c.JSON(preconditionErrorStatus(err, courierErrorStatus), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "status updated"})
 }
 
 type UpdateCourierLocationRequest struct {
-	Latitude  float64 `json:"latitude" binding:"required"`
-	Longitude float64 `json:"longitude" binding:"required"`
+	// указатели: (0,0) — допустимый ввод, его разбирает проверка
+	// правдоподобия, а не валидация
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	// RecordedAt — время фикса на устройстве; без него берётся время сервера.
+	RecordedAt *time.Time `json:"recorded_at"`
 }
 
 func (cc *CourierController) UpdateLocation(c *gin.Context) {
@@ -75,21 +91,80 @@ func (cc *CourierController) UpdateLocation(c *gin.Context) {
 	}
 
 	location := &entity.Coordinates{
-        Latitude: req.Latitude,
-        Longitude: req.Longitude,
+        Latitude: *req.Latitude,
+        Longitude: *req.Longitude,
     }
 
-	if err := cc.service.UpdateCourierLocation(id, location); err != nil {
+	var recordedAt time.Time
+	if req.RecordedAt != nil {
+		recordedAt = *req.RecordedAt
+	}
+
+	if err := cc.service.UpdateCourierLocation(c.Request.Context(), id, location, recordedAt); err != nil {
+		if errors.Is(err, service.ErrLocationRejected) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "location updated"})
 }
 
+func (cc *CourierController) Heartbeat(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	if err := cc.service.Heartbeat(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrCourierNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "heartbeat recorded"})
+}
+
+type SetVehicleRequest struct {
+	VehicleType entity.VehicleType `json:"vehicle_type" binding:"required"`
+	MaxWeightKg float64            `json:"max_weight_kg" binding:"gte=0"`
+	MaxVolumeL  float64            `json:"max_volume_l" binding:"gte=0"`
+}
+
+func (cc *CourierController) SetVehicle(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	var req SetVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	courier, err := cc.service.SetVehicle(c.Request.Context(), id, req.VehicleType, req.MaxWeightKg, req.MaxVolumeL, version)
+	if err != nil {
+		c.JSON(preconditionErrorStatus(err, courierErrorStatus), gin.H{"error": err.Error()})
+		return
+	}
+	setETag(c, courier.Version)
+	c.JSON(http.StatusOK, courier)
+}
+
 type FindNearestRequest struct {
 	Latitude  float64 `form:"latitude" binding:"required"`
 	Longitude float64 `form:"longitude" binding:"required"`
 	Radius    float64 `form:"radius" binding:"required,gt=0"`
+	ZoneID    string  `form:"zone_id" binding:"omitempty,uuid"`
+	WeightKg  float64 `form:"weight_kg" binding:"gte=0"`
+	VolumeL   float64 `form:"volume_l" binding:"gte=0"`
 }
 
 func (cc *CourierController) FindNearestCouriers(c *gin.Context) {
@@ -99,11 +174,216 @@ func (cc *CourierController) FindNearestCouriers(c *gin.Context) {
 		return
 	}
 
-	couriers, err := cc.service.FindNearestAvailable(req.Latitude, req.Longitude, req.Radius)
+	filter := repository.CourierFilter{WeightKg: req.WeightKg, VolumeL: req.VolumeL}
+	if req.ZoneID != "" {
+		id := uuid.MustParse(req.ZoneID)
+		filter.ZoneID = &id
+	}
+
+	couriers, err := cc.service.FindNearestAvailable(c.Request.Context(), req.Latitude, req.Longitude, req.Radius, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, couriers)
-}
\ No newline at end of file
+}
+
+func (cc *CourierController) GetRoute(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+
+	route, err := cc.routes.GetCourierRoute(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCourierNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCourierLocationUnknown) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+type LocationAnomaliesRequest struct {
+	CourierID string     `form:"courier_id" binding:"omitempty,uuid"`
+	Since     *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int        `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+}
+
+func (cc *CourierController) GetLocationAnomalies(c *gin.Context) {
+	var req LocationAnomaliesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := repository.AnomalyFilter{Since: req.Since, Limit: req.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+	if req.CourierID != "" {
+		id := uuid.MustParse(req.CourierID)
+		filter.CourierID = &id
+	}
+
+	anomalies, err := cc.service.ListLocationAnomalies(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, anomalies)
+}
+
+type CreateCourierRequest struct {
+	Email       string             `json:"email" binding:"required,email"`
+	Password    string             `json:"password" binding:"required,min=8"`
+	Name        string             `json:"name" binding:"required"`
+	VehicleType entity.VehicleType `json:"vehicle_type"`
+	Capacity    int                `json:"capacity" binding:"gte=0"`
+}
+
+func (cc *CourierController) CreateCourier(c *gin.Context) {
+	var req CreateCourierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	courier, err := cc.service.CreateCourier(c.Request.Context(), req.Email, req.Password, req.Name, req.VehicleType, req.Capacity)
+	if err != nil {
+		c.JSON(courierErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, courier)
+}
+
+type ListCouriersRequest struct {
+	Status        entity.CourierStatus `form:"status"`
+	AccountStatus entity.AccountStatus `form:"account_status"`
+	ZoneID        string               `form:"zone_id" binding:"omitempty,uuid"`
+	MinRating     *float64             `form:"min_rating"`
+	MaxRating     *float64             `form:"max_rating"`
+	SeenAfter     *time.Time           `form:"seen_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	SeenBefore    *time.Time           `form:"seen_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Query         string               `form:"q"`
+	Limit         int                  `form:"limit" binding:"omitempty,gt=0,lte=500"`
+	Offset        int                  `form:"offset" binding:"gte=0"`
+}
+
+func (cc *CourierController) ListCouriers(c *gin.Context) {
+	var req ListCouriersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := repository.CourierListFilter{
+		Status:        req.Status,
+		AccountStatus: req.AccountStatus,
+		MinRating:     req.MinRating,
+		MaxRating:     req.MaxRating,
+		SeenAfter:     req.SeenAfter,
+		SeenBefore:    req.SeenBefore,
+		Query:         req.Query,
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	if req.ZoneID != "" {
+		id := uuid.MustParse(req.ZoneID)
+		filter.ZoneID = &id
+	}
+	couriers, err := cc.service.ListCouriers(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, couriers)
+}
+
+type AccountStatusRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (cc *CourierController) SuspendCourier(c *gin.Context) {
+	cc.changeAccountStatus(c, cc.service.SuspendCourier)
+}
+
+func (cc *CourierController) DeactivateCourier(c *gin.Context) {
+	cc.changeAccountStatus(c, cc.service.DeactivateCourier)
+}
+
+func (cc *CourierController) changeAccountStatus(
+	c *gin.Context,
+	change func(ctx context.Context, id uuid.UUID, reason string) (*entity.Courier, error),
+) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	var req AccountStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	courier, err := change(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		c.JSON(courierErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, courier)
+}
+
+func (cc *CourierController) ReactivateCourier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	courier, err := cc.service.ReactivateCourier(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(courierErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, courier)
+}
+
+func (cc *CourierController) DeleteCourier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	if err := cc.service.DeleteCourier(c.Request.Context(), id); err != nil {
+		c.JSON(courierErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "courier deleted"})
+}
+
+func courierErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidVehicle):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrCourierNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrEmailTaken),
+		errors.Is(err, service.ErrCourierSuspended),
+		errors.Is(err, service.ErrRetentionNotElapsed),
+		errors.Is(err, service.ErrCourierHasOrders),
+		errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
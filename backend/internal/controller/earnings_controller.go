@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EarningsController struct {
+	earningsService service.EarningsService
+}
+
+func NewEarningsController(earningsService service.EarningsService) *EarningsController {
+	return &EarningsController{earningsService: earningsService}
+}
+
+type EarningsRequest struct {
+	From   *time.Time                `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time                `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Period entity.EarningsPeriodKind `form:"period" binding:"omitempty,oneof=day week"`
+}
+
+// MyEarnings — сводка заработка для самого курьера.
+func (ec *EarningsController) MyEarnings(c *gin.Context) {
+	courierID, _ := middleware.CurrentUserID(c)
+	ec.summary(c, courierID)
+}
+
+func (ec *EarningsController) GetCourierEarnings(c *gin.Context) {
+	courierID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	ec.summary(c, courierID)
+}
+
+func (ec *EarningsController) summary(c *gin.Context, courierID uuid.UUID) {
+	var req EarningsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := entity.EarningsQuery{Period: req.Period}
+	if req.From != nil {
+		q.From = *req.From
+	}
+	if req.To != nil {
+		q.To = *req.To
+	}
+	sum, err := ec.earningsService.Summary(c.Request.Context(), courierID, q)
+	if err != nil {
+		c.JSON(earningsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sum)
+}
+
+type TipRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// Tip — чаевые клиента курьеру за доставленный заказ.
+func (ec *EarningsController) Tip(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	var req TipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	clientID, _ := middleware.CurrentUserID(c)
+
+	t, err := ec.earningsService.Tip(c.Request.Context(), clientID, orderID, req.Amount)
+	if err != nil {
+		c.JSON(earningsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"order_id": orderID,
+		"amount":   req.Amount,
+		"currency": t.Currency,
+	})
+}
+
+// AdjustmentRequest — ручная корректировка; отрицательная сумма —
+// удержание.
+type AdjustmentRequest struct {
+	Amount      int64                   `json:"amount" binding:"required"`
+	Component   entity.EarningComponent `json:"component" binding:"omitempty,oneof=ADJUSTMENT BONUS"`
+	Description string                  `json:"description" binding:"required,max=500"`
+}
+
+func (ec *EarningsController) Adjust(c *gin.Context) {
+	courierID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	var req AdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+
+	t, err := ec.earningsService.Adjust(c.Request.Context(), courierID, service.AdjustmentInput{
+		Amount:      req.Amount,
+		Component:   req.Component,
+		Description: req.Description,
+	}, actorID)
+	if err != nil {
+		c.JSON(earningsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+type PayoutRequest struct {
+	// Cutoff — по какой момент выплатить начисления; пусто — по текущий.
+	Cutoff *time.Time `json:"cutoff"`
+}
+
+func (ec *EarningsController) CreatePayout(c *gin.Context) {
+	var req PayoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+
+	b, err := ec.earningsService.CreatePayout(c.Request.Context(), req.Cutoff, actorID)
+	if err != nil {
+		c.JSON(earningsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, b)
+}
+
+type ListPayoutsRequest struct {
+	Limit  int `form:"limit" binding:"omitempty,gt=0,lte=500"`
+	Offset int `form:"offset" binding:"gte=0"`
+}
+
+func (ec *EarningsController) ListPayouts(c *gin.Context) {
+	var req ListPayoutsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+	batches, err := ec.earningsService.ListPayouts(c.Request.Context(), req.Limit, req.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, batches)
+}
+
+func (ec *EarningsController) GetPayout(c *gin.Context) {
+	b, ok := ec.payout(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// ExportPayout отдаёт реестр файлом для бухгалтерии; суммы — в основных
+// единицах валюты.
+func (ec *EarningsController) ExportPayout(c *gin.Context) {
+	b, ok := ec.payout(c)
+	if !ok {
+		return
+	}
+	filename := fmt.Sprintf("payout-%s-%s.csv", b.Cutoff.Format("20060102"), b.ID.String()[:8])
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"courier_id", "courier_name", "amount", "currency"})
+	for _, line := range b.Lines {
+		w.Write([]string{line.CourierID.String(), line.CourierName, formatMinorUnits(line.Amount), b.Currency})
+	}
+	w.Flush()
+}
+
+func (ec *EarningsController) payout(c *gin.Context) (*entity.PayoutBatch, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payout id"})
+		return nil, false
+	}
+	b, err := ec.earningsService.GetPayout(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(earningsErrorStatus(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return b, true
+}
+
+// formatMinorUnits переводит копейки в рубли: 150050 → "1500.50".
+func formatMinorUnits(v int64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return sign + strconv.FormatInt(v/100, 10) + fmt.Sprintf(".%02d", v%100)
+}
+
+func earningsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidEarningsPeriod),
+		errors.Is(err, service.ErrInvalidAdjustment),
+		errors.Is(err, service.ErrInvalidTip),
+		errors.Is(err, service.ErrInvalidPayoutCutoff):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrOrderNotFound),
+		errors.Is(err, repository.ErrCourierNotFound),
+		errors.Is(err, repository.ErrPayoutBatchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrOrderNotDelivered),
+		errors.Is(err, service.ErrAlreadyTipped),
+		errors.Is(err, repository.ErrPayoutCutoff),
+		errors.Is(err, repository.ErrPayoutEmpty):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
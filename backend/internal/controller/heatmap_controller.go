@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HeatmapController struct {
+	heatmapService service.HeatmapService
+}
+
+func NewHeatmapController(heatmapService service.HeatmapService) *HeatmapController {
+	return &HeatmapController{heatmapService: heatmapService}
+}
+
+type HeatmapRequest struct {
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Precision int        `form:"precision" binding:"omitempty,min=4,max=8"`
+	ZoneID    string     `form:"zone_id" binding:"omitempty,uuid"`
+}
+
+// GetHeatmap — спрос и предложение по ячейкам геохеша в GeoJSON.
+func (hc *HeatmapController) GetHeatmap(c *gin.Context) {
+	var req HeatmapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := entity.HeatmapQuery{Precision: req.Precision}
+	if req.From != nil {
+		q.From = *req.From
+	}
+	if req.To != nil {
+		q.To = *req.To
+	}
+	if req.ZoneID != "" {
+		id := uuid.MustParse(req.ZoneID)
+		q.ZoneID = &id
+	}
+
+	fc, err := hc.heatmapService.Heatmap(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(heatmapErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fc)
+}
+
+func heatmapErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidHeatmapPeriod):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrZoneNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationController отдаёт пользователю его уведомления.
+type NotificationController struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationController(notificationService service.NotificationService) *NotificationController {
+	return &NotificationController{notificationService: notificationService}
+}
+
+type ListNotificationsRequest struct {
+	Unread bool `form:"unread"`
+	Limit  int  `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+}
+
+func (nc *NotificationController) List(c *gin.Context) {
+	var req ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	list, err := nc.notificationService.List(c.Request.Context(), userID, req.Unread, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+	userID, _ := middleware.CurrentUserID(c)
+	if err := nc.notificationService.MarkRead(c.Request.Context(), userID, id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
@@ -1,9 +1,16 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
 	"backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -18,10 +25,29 @@ func NewOrderController(orderService service.OrderService) *OrderController {
 	return &OrderController{orderService: orderService}
 }
 
+type StopRequest struct {
+	Kind      entity.StopKind `json:"kind" binding:"required,oneof=PICKUP DROPOFF"`
+	Address   string          `json:"address" binding:"required"`
+	Latitude  float64         `json:"latitude" binding:"required"`
+	Longitude float64         `json:"longitude" binding:"required"`
+}
+
 type CreateOrderRequest struct {
-	ClientID        uuid.UUID `json:"client_id" binding:"required"`
-	DeliveryAddress string    `json:"delivery_address" binding:"required"`
-	DeliveryCoords  string    `json:"delivery_coords" binding:"required"`
+	ClientID        uuid.UUID     `json:"client_id" binding:"required"`
+	DeliveryAddress string        `json:"delivery_address" binding:"required_without_all=Stops AddressID"`
+	DeliveryCoords  string        `json:"delivery_coords" binding:"required_without_all=Stops AddressID"`
+	DeliveryNotes   string        `json:"delivery_notes"`
+	Stops           []StopRequest `json:"stops" binding:"omitempty,dive"`
+	// AddressID — сохранённый адрес клиента вместо delivery_address/coords.
+	AddressID *uuid.UUID `json:"address_id"`
+	QuoteID         *uuid.UUID    `json:"quote_id"`
+	WeightKg        float64       `json:"weight_kg" binding:"gte=0"`
+	LengthCm        float64       `json:"length_cm" binding:"gte=0"`
+	WidthCm         float64       `json:"width_cm" binding:"gte=0"`
+	HeightCm        float64       `json:"height_cm" binding:"gte=0"`
+	// WindowStart и WindowEnd — желаемое окно доставки, RFC 3339.
+	WindowStart *time.Time `json:"window_start"`
+	WindowEnd   *time.Time `json:"window_end"`
 }
 
 func (oc *OrderController) CreateOrder(c *gin.Context) {
@@ -37,11 +63,32 @@ func (oc *OrderController) CreateOrder(c *gin.Context) {
 		Status:          entity.StatusCreated,
 		DeliveryAddress: req.DeliveryAddress,
 		DeliveryCoords:  req.DeliveryCoords,
+		DeliveryNotes:   req.DeliveryNotes,
+		AddressID:       req.AddressID,
+		QuoteID:         req.QuoteID,
+		WeightKg:        req.WeightKg,
+		LengthCm:        req.LengthCm,
+		WidthCm:         req.WidthCm,
+		HeightCm:        req.HeightCm,
+		WindowStart:     req.WindowStart,
+		WindowEnd:       req.WindowEnd,
+	}
+	for _, s := range req.Stops {
+		order.Stops = append(order.Stops, entity.OrderStop{
+			Kind:     s.Kind,
+			Address:  s.Address,
+			Location: entity.Coordinates{Latitude: s.Latitude, Longitude: s.Longitude},
+		})
+		// адрес доставки заказа — последняя точка выдачи
+		if s.Kind == entity.StopDropoff && req.DeliveryCoords == "" {
+			order.DeliveryAddress = s.Address
+			order.DeliveryCoords = fmt.Sprintf("%f,%f", s.Latitude, s.Longitude)
+		}
 	}
 
 	created, err := oc.orderService.CreateOrder(c.Request.Context(), order)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(createOrderErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, created)
@@ -55,16 +102,36 @@ func (oc *OrderController) GetOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := oc.orderService.GetOrderByID(id)
+	order, err := oc.orderService.GetOrderByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	setETag(c, order.Version)
 	c.JSON(http.StatusOK, order)
 }
 
+func (oc *OrderController) GetTimeline(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	events, err := oc.orderService.GetTimeline(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
 func (oc *OrderController) GetOrders(c *gin.Context) {
-	list, err := oc.orderService.GetAllOrders()
+	list, err := oc.orderService.GetAllOrders(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,7 +140,9 @@ func (oc *OrderController) GetOrders(c *gin.Context) {
 }
 
 type UpdateOrderRequest struct {
-	Status          entity.OrderStatus `json:"status" binding:"required"`
+	// Status можно не передавать; сменить его здесь нельзя — для этого
+	// есть назначение, отметка точек и отмена с их проверками
+	Status          entity.OrderStatus `json:"status"`
 	DeliveryAddress string             `json:"delivery_address" binding:"required"`
 	DeliveryCoords  string             `json:"delivery_coords" binding:"required"`
 }
@@ -92,20 +161,36 @@ func (oc *OrderController) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := oc.orderService.GetOrderByID(id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	order, err := oc.orderService.GetOrderByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	// сохраняется загруженная версия: если заказ изменится между чтением
+	// и записью, Update вернёт ErrVersionConflict
+	if version != 0 && order.Version != version {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": repository.ErrVersionConflict.Error()})
+		return
+	}
+
+	if req.Status != "" && req.Status != order.Status {
+		c.JSON(http.StatusConflict, gin.H{"error": "order status cannot be changed with PUT"})
+		return
+	}
 
-	order.Status = req.Status
 	order.DeliveryAddress = req.DeliveryAddress
 	order.DeliveryCoords = req.DeliveryCoords
 
-	if err := oc.orderService.UpdateOrder(order); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := oc.orderService.UpdateOrder(c.Request.Context(), order); err != nil {
+		c.JSON(preconditionErrorStatus(err, dispatchErrorStatus), gin.H{"error": err.Error()})
 		return
 	}
+	setETag(c, order.Version)
 	c.JSON(http.StatusOK, order)
 }
 
@@ -116,9 +201,245 @@ func (oc *OrderController) DeleteOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
 		return
 	}
-	if err := oc.orderService.DeleteOrder(id); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if err := oc.orderService.DeleteOrder(c.Request.Context(), id); err != nil {
+		c.JSON(dispatchErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "order deleted"})
 }
+
+func (oc *OrderController) CancelOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	order, err := oc.orderService.CancelOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(dispatchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+type AssignCourierRequest struct {
+	CourierID *uuid.UUID `json:"courier_id"`
+}
+
+// AssignCourier назначает курьера на заказ: явно указанного в теле запроса
+// или, если тело пустое, подобранного автоматически.
+func (oc *OrderController) AssignCourier(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+
+	var req AssignCourierRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.CourierID != nil {
+		err = oc.orderService.AssignCourier(c.Request.Context(), id, *req.CourierID)
+	} else {
+		err = oc.orderService.AssignCourierToOrder(c.Request.Context(), id)
+	}
+	if err != nil {
+		c.JSON(dispatchErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := oc.orderService.GetOrderByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// ProofRequest — подтверждение вручения в JSON (код). Подпись и фото
+// присылаются multipart-формой с полями kind и file. Место вручения
+// берётся из последней координаты курьера.
+type ProofRequest struct {
+	Kind       entity.ProofKind `json:"kind" form:"kind"`
+	Code       string           `json:"code" form:"code"`
+	RecordedAt *time.Time       `json:"recorded_at" form:"recorded_at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (oc *OrderController) CompleteStop(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	stopID, err := uuid.Parse(c.Param("stop_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop id"})
+		return
+	}
+
+	proof, closeFile, err := bindProof(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeFile()
+
+	courierID, _ := middleware.CurrentUserID(c)
+	order, err := oc.orderService.CompleteStop(c.Request.Context(), courierID, id, stopID, proof)
+	if err != nil {
+		c.JSON(proofErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// bindProof читает подтверждение вручения; для точек забора тело пустое.
+// Загруженный файл закрывается вызовом closeFile после обработки.
+func bindProof(c *gin.Context) (in *service.ProofInput, closeFile func(), err error) {
+	closeFile = func() {}
+	if c.Request.ContentLength == 0 {
+		return nil, closeFile, nil
+	}
+	var req ProofRequest
+	in = &service.ProofInput{}
+	if c.ContentType() == "multipart/form-data" {
+		if err := c.ShouldBind(&req); err != nil {
+			return nil, closeFile, err
+		}
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, closeFile, err
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, closeFile, err
+		}
+		in.File = f
+		closeFile = func() { f.Close() }
+	} else {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, closeFile, err
+		}
+		if req.Kind == "" {
+			req.Kind = entity.ProofOTP
+		}
+	}
+	in.Kind = req.Kind
+	in.Code = req.Code
+	if req.RecordedAt != nil {
+		in.RecordedAt = req.RecordedAt.UTC()
+	}
+	return in, closeFile, nil
+}
+
+func proofErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidProof):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrWrongDeliveryCode):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrProofTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedProofType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, repository.ErrProofNotFound):
+		return http.StatusNotFound
+	default:
+		return dispatchErrorStatus(err)
+	}
+}
+
+func createOrderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidStops),
+		errors.Is(err, service.ErrInvalidWindow),
+		errors.Is(err, service.ErrQuoteMismatch),
+		errors.Is(err, service.ErrQuoteTooLight):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrQuoteNotFound),
+		errors.Is(err, repository.ErrAddressNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrQuoteUnavailable):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrOutsideServiceArea):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func dispatchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound),
+		errors.Is(err, repository.ErrStopNotFound),
+		errors.Is(err, repository.ErrCourierNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrNoCourierAvailable),
+		errors.Is(err, service.ErrCourierAtCapacity),
+		errors.Is(err, service.ErrCourierOffline),
+		errors.Is(err, service.ErrNotOnTheWay),
+		errors.Is(err, service.ErrVehicleTooSmall),
+		errors.Is(err, service.ErrOrderNotCancelable),
+		errors.Is(err, service.ErrProofRequired),
+		errors.Is(err, repository.ErrCodeAttemptsExceeded),
+		errors.Is(err, service.ErrOrderScheduled),
+		errors.Is(err, service.ErrOrderNotAssignable),
+		errors.Is(err, service.ErrOrderNotInProgress),
+		errors.Is(err, service.ErrCourierLocationUnknown),
+		errors.Is(err, service.ErrOrderNotDeletable),
+		errors.Is(err, service.ErrCourierSuspended),
+		errors.Is(err, service.ErrNoActiveShift),
+		errors.Is(err, service.ErrCourierOutOfZone),
+		errors.Is(err, repository.ErrStopCompleted),
+		errors.Is(err, repository.ErrVersionConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// setETag отдаёт версию записи в ETag, чтобы клиент вернул её в If-Match.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion читает версию из обязательного If-Match. «*» разрешает
+// запись поверх любой версии — тогда возвращается 0. Если заголовка нет
+// или он не разбирается, отвечает сам и возвращает false.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	h := strings.TrimSpace(c.GetHeader("If-Match"))
+	if h == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+	if h == "*" {
+		return 0, true
+	}
+	// слабый ETag сравниваем как сильный: версия одна на запись
+	tag := strings.TrimPrefix(h, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		unquoted = tag
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match does not match any version"})
+		return 0, false
+	}
+	return version, true
+}
+
+// preconditionErrorStatus — конфликт версий при записи с If-Match
+// означает 412, остальные ошибки отображает fallback.
+func preconditionErrorStatus(err error, fallback func(error) int) int {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	return fallback(err)
+}
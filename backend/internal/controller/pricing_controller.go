@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/pricing"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PricingController struct {
+	pricingService service.PricingService
+}
+
+func NewPricingController(pricingService service.PricingService) *PricingController {
+	return &PricingController{pricingService: pricingService}
+}
+
+type PointRequest struct {
+	Latitude  float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude float64 `json:"longitude" binding:"required,min=-180,max=180"`
+}
+
+func (p PointRequest) Coordinates() entity.Coordinates {
+	return entity.Coordinates{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+type CreateQuoteRequest struct {
+	Pickup   PointRequest `json:"pickup" binding:"required"`
+	Dropoff  PointRequest `json:"dropoff" binding:"required"`
+	WeightKg float64      `json:"weight_kg" binding:"gte=0"`
+}
+
+func (pc *PricingController) CreateQuote(c *gin.Context) {
+	var req CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := pc.pricingService.CreateQuote(c.Request.Context(), req.Pickup.Coordinates(), req.Dropoff.Coordinates(), req.WeightKg)
+	if err != nil {
+		if errors.Is(err, pricing.ErrTooHeavy) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, quote)
+}
+
+func (pc *PricingController) GetQuote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote id"})
+		return
+	}
+	quote, err := pc.pricingService.GetQuote(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+func (pc *PricingController) GetRules(c *gin.Context) {
+	rules, err := pc.pricingService.GetRules(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, repository.ErrPricingRulesNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+func (pc *PricingController) UpdateRules(c *gin.Context) {
+	var rules entity.PricingRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+
+	saved, err := pc.pricingService.UpdateRules(c.Request.Context(), &rules, actorID)
+	if err != nil {
+		if errors.Is(err, pricing.ErrInvalidRules) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+func (pc *PricingController) ListSurge(c *gin.Context) {
+	zones, err := pc.pricingService.ListSurge(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+type SurgeOverrideRequest struct {
+	Multiplier float64    `json:"multiplier" binding:"required,gt=0,lte=10"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+func (pc *PricingController) SetSurgeOverride(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("zone_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+	var req SurgeOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+
+	surge, err := pc.pricingService.SetSurgeOverride(c.Request.Context(), zoneID, req.Multiplier, req.ExpiresAt, actorID)
+	if err != nil {
+		c.JSON(surgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, surge)
+}
+
+func (pc *PricingController) ClearSurgeOverride(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("zone_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+
+	if err := pc.pricingService.ClearSurgeOverride(c.Request.Context(), zoneID, actorID); err != nil {
+		c.JSON(surgeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "surge override cleared"})
+}
+
+func surgeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidSurgeOverride):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrZoneNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
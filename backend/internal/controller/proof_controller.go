@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"backend/internal/middleware"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProofController struct {
+	proofService service.ProofService
+}
+
+func NewProofController(proofService service.ProofService) *ProofController {
+	return &ProofController{proofService: proofService}
+}
+
+// GetProofs — подтверждения вручения заказа для разбора споров.
+func (pc *ProofController) GetProofs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	proofs, err := pc.proofService.List(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, proofs)
+}
+
+func (pc *ProofController) GetProofFile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	proofID, err := uuid.Parse(c.Param("proof_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proof id"})
+		return
+	}
+	proof, f, err := pc.proofService.OpenFile(c.Request.Context(), id, proofID)
+	if err != nil {
+		c.JSON(proofErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Type", proof.ContentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, f)
+}
+
+// GetDeliveryCode показывает клиенту код, который он называет курьеру.
+func (pc *ProofController) GetDeliveryCode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	clientID, _ := middleware.CurrentUserID(c)
+	code, err := pc.proofService.DeliveryCode(c.Request.Context(), clientID, id)
+	if err != nil {
+		c.JSON(proofErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order_id": id, "delivery_code": code})
+}
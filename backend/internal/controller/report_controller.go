@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const mimeCSV = "text/csv"
+
+type ReportController struct {
+	reportService service.ReportService
+}
+
+func NewReportController(reportService service.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
+}
+
+// ReportRequest — общие параметры отчётов. Формат выбирается параметром
+// format или заголовком Accept.
+type ReportRequest struct {
+	GroupBy entity.ReportGroup `form:"group_by" binding:"omitempty,oneof=day zone courier"`
+	From    *time.Time         `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      *time.Time         `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format  string             `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+func (rc *ReportController) Volume(c *gin.Context) {
+	q, asCSV, ok := bindReport(c)
+	if !ok {
+		return
+	}
+	rows, q, err := rc.reportService.Volume(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !asCSV {
+		c.JSON(http.StatusOK, reportBody(q, rows))
+		return
+	}
+	records := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		records = append(records, []string{r.Key, r.Label,
+			strconv.Itoa(r.Orders), strconv.Itoa(r.Delivered), strconv.Itoa(r.Canceled), strconv.Itoa(r.InProgress)})
+	}
+	writeReportCSV(c, "volume", q, []string{"orders", "delivered", "canceled", "in_progress"}, records)
+}
+
+func (rc *ReportController) Timing(c *gin.Context) {
+	q, asCSV, ok := bindReport(c)
+	if !ok {
+		return
+	}
+	rows, q, err := rc.reportService.Timing(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !asCSV {
+		c.JSON(http.StatusOK, reportBody(q, rows))
+		return
+	}
+	records := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		records = append(records, []string{r.Key, r.Label,
+			strconv.Itoa(r.Assigned), formatSeconds(r.AvgAssignSeconds),
+			strconv.Itoa(r.Delivered), formatSeconds(r.AvgDeliverSeconds)})
+	}
+	writeReportCSV(c, "timing", q, []string{"assigned", "avg_assign_seconds", "delivered", "avg_deliver_seconds"}, records)
+}
+
+func (rc *ReportController) Cancellations(c *gin.Context) {
+	q, asCSV, ok := bindReport(c)
+	if !ok {
+		return
+	}
+	rows, q, err := rc.reportService.Cancellations(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !asCSV {
+		c.JSON(http.StatusOK, reportBody(q, rows))
+		return
+	}
+	records := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		records = append(records, []string{r.Key, r.Label,
+			strconv.Itoa(r.Orders), strconv.Itoa(r.Canceled), strconv.Itoa(r.CanceledUnassigned),
+			strconv.FormatFloat(r.CancellationRate, 'f', 4, 64)})
+	}
+	writeReportCSV(c, "cancellations", q, []string{"orders", "canceled", "canceled_unassigned", "cancellation_rate"}, records)
+}
+
+// bindReport разбирает параметры отчёта; при ошибке ответ уже отправлен.
+func bindReport(c *gin.Context) (entity.ReportQuery, bool, bool) {
+	var req ReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return entity.ReportQuery{}, false, false
+	}
+	q := entity.ReportQuery{GroupBy: req.GroupBy}
+	if req.From != nil {
+		q.From = *req.From
+	}
+	if req.To != nil {
+		q.To = *req.To
+	}
+	asCSV := req.Format == "csv" ||
+		req.Format == "" && c.NegotiateFormat(gin.MIMEJSON, mimeCSV) == mimeCSV
+	return q, asCSV, true
+}
+
+func reportBody(q entity.ReportQuery, rows any) gin.H {
+	return gin.H{
+		"group_by": q.GroupBy,
+		"from":     q.From,
+		"to":       q.To,
+		"timezone": q.Location,
+		"rows":     rows,
+	}
+}
+
+// writeReportCSV отдаёт отчёт файлом; первые колонки — группа и её имя.
+func writeReportCSV(c *gin.Context, name string, q entity.ReportQuery, columns []string, records [][]string) {
+	filename := fmt.Sprintf("%s-by-%s-%s-%s.csv", name, q.GroupBy, q.From.Format("20060102"), q.To.Format("20060102"))
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(append([]string{string(q.GroupBy), "name"}, columns...))
+	w.WriteAll(records)
+}
+
+func formatSeconds(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 0, 64)
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportGroup),
+		errors.Is(err, service.ErrInvalidReportPeriod):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ShiftController struct {
+	shiftService service.ShiftService
+}
+
+func NewShiftController(shiftService service.ShiftService) *ShiftController {
+	return &ShiftController{shiftService: shiftService}
+}
+
+type ShiftRequest struct {
+	CourierID    string    `json:"courier_id" binding:"required,uuid"`
+	ZoneID       string    `json:"zone_id" binding:"omitempty,uuid"`
+	PlannedStart time.Time `json:"planned_start" binding:"required"`
+	PlannedEnd   time.Time `json:"planned_end" binding:"required"`
+}
+
+func (req ShiftRequest) shift() *entity.Shift {
+	s := &entity.Shift{
+		CourierID:    uuid.MustParse(req.CourierID),
+		PlannedStart: req.PlannedStart.UTC(),
+		PlannedEnd:   req.PlannedEnd.UTC(),
+	}
+	if req.ZoneID != "" {
+		zoneID := uuid.MustParse(req.ZoneID)
+		s.ZoneID = &zoneID
+	}
+	return s
+}
+
+func (sc *ShiftController) PlanShift(c *gin.Context) {
+	var req ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	shift := req.shift()
+	if err := sc.shiftService.PlanShift(c.Request.Context(), shift); err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shift)
+}
+
+type ListShiftsRequest struct {
+	CourierID string     `form:"courier_id" binding:"omitempty,uuid"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (sc *ShiftController) ListShifts(c *gin.Context) {
+	var req ListShiftsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := repository.ShiftFilter{From: req.From, To: req.To}
+	if req.CourierID != "" {
+		id := uuid.MustParse(req.CourierID)
+		filter.CourierID = &id
+	}
+	shifts, err := sc.shiftService.ListShifts(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shifts)
+}
+
+func (sc *ShiftController) GetShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid shift id"})
+		return
+	}
+	shift, err := sc.shiftService.GetShift(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
+
+func (sc *ShiftController) UpdateShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid shift id"})
+		return
+	}
+	var req ShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	shift := req.shift()
+	shift.ID = id
+	if err := sc.shiftService.UpdateShift(c.Request.Context(), shift); err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
+
+func (sc *ShiftController) DeleteShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid shift id"})
+		return
+	}
+	if err := sc.shiftService.DeleteShift(c.Request.Context(), id); err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "shift deleted"})
+}
+
+func (sc *ShiftController) GetSummary(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid shift id"})
+		return
+	}
+	summary, err := sc.shiftService.GetSummary(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+func (sc *ShiftController) StartShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	shift, err := sc.shiftService.StartShift(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
+
+func (sc *ShiftController) EndShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	summary, err := sc.shiftService.EndShift(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(shiftErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+func shiftErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidShift):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrShiftNotFound),
+		errors.Is(err, repository.ErrCourierNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrShiftOverlap),
+		errors.Is(err, service.ErrShiftStarted),
+		errors.Is(err, service.ErrShiftAlreadyActive),
+		errors.Is(err, service.ErrNoPlannedShift),
+		errors.Is(err, service.ErrCourierSuspended),
+		errors.Is(err, service.ErrNoActiveShift):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SLAController struct {
+	slaService service.SLAService
+}
+
+func NewSLAController(slaService service.SLAService) *SLAController {
+	return &SLAController{slaService: slaService}
+}
+
+func (sc *SLAController) ListPolicies(c *gin.Context) {
+	policies, err := sc.slaService.ListPolicies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+type SLAPolicyRequest struct {
+	ThresholdSeconds  int64 `json:"threshold_seconds" binding:"required,gt=0"`
+	WarnBeforeSeconds int64 `json:"warn_before_seconds" binding:"gte=0"`
+	Enabled           *bool `json:"enabled" binding:"required"`
+}
+
+func (sc *SLAController) UpdatePolicy(c *gin.Context) {
+	var req SLAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actorID, _ := middleware.CurrentUserID(c)
+	policy, err := sc.slaService.UpdatePolicy(c.Request.Context(), &entity.SLAPolicy{
+		Metric:            entity.SLAMetric(c.Param("metric")),
+		ThresholdSeconds:  req.ThresholdSeconds,
+		WarnBeforeSeconds: req.WarnBeforeSeconds,
+		Enabled:           *req.Enabled,
+	}, actorID)
+	if err != nil {
+		c.JSON(slaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+type ListBreachesRequest struct {
+	Metric  entity.SLAMetric `form:"metric" binding:"omitempty,oneof=TIME_TO_ASSIGN TIME_TO_PICKUP TIME_TO_DELIVER"`
+	OrderID string           `form:"order_id" binding:"omitempty,uuid"`
+	From    *time.Time       `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      *time.Time       `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit   int              `form:"limit" binding:"omitempty,gt=0,lte=1000"`
+	Offset  int              `form:"offset" binding:"gte=0"`
+}
+
+// ListBreaches — отчёт о нарушениях SLA за период.
+func (sc *SLAController) ListBreaches(c *gin.Context) {
+	var req ListBreachesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := repository.SLABreachFilter{
+		Metric: req.Metric,
+		From:   req.From,
+		To:     req.To,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	if req.OrderID != "" {
+		id := uuid.MustParse(req.OrderID)
+		filter.OrderID = &id
+	}
+	report, err := sc.slaService.Report(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (sc *SLAController) ListAtRisk(c *gin.Context) {
+	risks, err := sc.slaService.AtRisk(c.Request.Context(), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, risks)
+}
+
+func slaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidSLAPolicy):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrSLAPolicyNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrackingController struct {
+	trackingService service.TrackingService
+	// baseURL — внешний адрес сервиса для ссылок в ответе; без него
+	// отдаётся только путь.
+	baseURL string
+}
+
+func NewTrackingController(trackingService service.TrackingService, baseURL string) *TrackingController {
+	return &TrackingController{trackingService: trackingService, baseURL: baseURL}
+}
+
+// CreateLink выдаёт клиенту ссылку, которую он пересылает получателю.
+func (tc *TrackingController) CreateLink(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	clientID, _ := middleware.CurrentUserID(c)
+	token, link, err := tc.trackingService.CreateLink(c.Request.Context(), clientID, id)
+	if err != nil {
+		c.JSON(trackingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"token":      token,
+		"url":        tc.baseURL + "/track/" + token,
+		"expires_at": link.ExpiresAt,
+	})
+}
+
+func (tc *TrackingController) RevokeLinks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	clientID, _ := middleware.CurrentUserID(c)
+	n, err := tc.trackingService.RevokeLinks(c.Request.Context(), clientID, id)
+	if err != nil {
+		c.JSON(trackingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "tracking links revoked", "revoked": n})
+}
+
+// Track — публичная страница получателя. Браузеру отдаётся HTML, всем
+// остальным — JSON.
+func (tc *TrackingController) Track(c *gin.Context) {
+	// токен в адресе — единственный ключ доступа: не отдаём его в Referer,
+	// кэши и поисковики
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex")
+
+	view, err := tc.trackingService.Track(c.Request.Context(), c.Param("token"))
+	html := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+	if err != nil {
+		status := trackingErrorStatus(err)
+		if html {
+			c.Status(status)
+			trackingPage.Execute(c.Writer, gin.H{"Error": trackingErrorText(status)})
+			return
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if html {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		trackingPage.Execute(c.Writer, gin.H{"View": view, "Status": trackingStatusText[view.Status]})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+func trackingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound),
+		errors.Is(err, repository.ErrTrackingLinkNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrTrackingLinkExpired):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func trackingErrorText(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return "Ссылка не найдена."
+	case http.StatusGone:
+		return "Срок действия ссылки истёк."
+	default:
+		return "Не удалось загрузить данные, попробуйте позже."
+	}
+}
+
+var trackingStatusText = map[entity.OrderStatus]string{
+	entity.StatusCreated:   "Заказ оформлен",
+	entity.StatusAssigned:  "Курьер назначен",
+	entity.StatusInTransit: "Курьер в пути",
+	entity.StatusDelivered: "Доставлено",
+	entity.StatusCanceled:  "Заказ отменён",
+}
+
+var trackingPage = template.Must(template.New("track").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if .View}}<meta http-equiv="refresh" content="30">{{end}}
+<title>Отслеживание доставки</title>
+<style>
+body { font-family: sans-serif; max-width: 32rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.muted { color: #777; font-size: .9rem; }
+li.done { color: #777; text-decoration: line-through; }
+</style>
+</head>
+<body>
+{{with .Error}}
+<h1>Отслеживание доставки</h1>
+<p>{{.}}</p>
+{{else}}
+<h1>{{.Status}}</h1>
+{{with .View}}
+{{with .EstimatedDeliveryAt}}<p>Ожидаемое время доставки: <b>{{.Local.Format "15:04"}}</b></p>{{end}}
+<ol>
+{{range .Stops}}<li{{if .CompletedAt}} class="done"{{end}}>{{if eq .Kind "PICKUP"}}Забор посылки{{else}}Вручение{{end}}</li>
+{{end}}
+</ol>
+{{with .CourierLocation}}<p>Курьер рядом с точкой <a href="https://www.openstreetmap.org/?mlat={{.Latitude}}&amp;mlon={{.Longitude}}#map=15/{{.Latitude}}/{{.Longitude}}" rel="noreferrer">на карте</a>.</p>{{end}}
+<p class="muted">Обновлено {{.UpdatedAt.Local.Format "02.01 15:04"}}. Ссылка действует до {{.ExpiresAt.Local.Format "02.01 15:04"}}.</p>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
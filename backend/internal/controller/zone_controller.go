@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ZoneController struct {
+	zoneService service.ZoneService
+}
+
+func NewZoneController(zoneService service.ZoneService) *ZoneController {
+	return &ZoneController{zoneService: zoneService}
+}
+
+type ZoneRequest struct {
+	Name     string          `json:"name" binding:"required"`
+	Boundary json.RawMessage `json:"boundary" binding:"required"`
+	Active   *bool           `json:"active"`
+}
+
+func (req ZoneRequest) active() bool {
+	return req.Active == nil || *req.Active
+}
+
+func (zc *ZoneController) CreateZone(c *gin.Context) {
+	var req ZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zone, err := zc.zoneService.CreateZone(c.Request.Context(), req.Name, req.Boundary, req.active())
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, zone)
+}
+
+// ImportZones принимает GeoJSON FeatureCollection как есть.
+func (zc *ZoneController) ImportZones(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zones, err := zc.zoneService.ImportZones(c.Request.Context(), data)
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, zones)
+}
+
+func (zc *ZoneController) GetZones(c *gin.Context) {
+	zones, err := zc.zoneService.GetAllZones(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+func (zc *ZoneController) GetZone(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+	zone, err := zc.zoneService.GetZone(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+func (zc *ZoneController) UpdateZone(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+	var req ZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	zone := &entity.Zone{ID: id, Name: req.Name, Boundary: req.Boundary, Active: req.active()}
+	if err := zc.zoneService.UpdateZone(c.Request.Context(), zone); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+func (zc *ZoneController) DeleteZone(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+	if err := zc.zoneService.DeleteZone(c.Request.Context(), id); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "zone deleted"})
+}
+
+type SetCourierZonesRequest struct {
+	ZoneIDs []uuid.UUID `json:"zone_ids" binding:"required"`
+}
+
+func (zc *ZoneController) SetCourierZones(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courier id"})
+		return
+	}
+	var req SetCourierZonesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := zc.zoneService.SetCourierZones(c.Request.Context(), id, req.ZoneIDs); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "courier zones updated"})
+}
+
+func zoneErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidZoneGeometry):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrZoneNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
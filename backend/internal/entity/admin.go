@@ -0,0 +1,71 @@
+package entity
+
+import "github.com/google/uuid"
+
+// Permission — право администратора вида "<ресурс>:<действие>".
+type Permission string
+
+const (
+	PermOrdersRead     Permission = "orders:read"
+	PermOrdersCancel   Permission = "orders:cancel"
+	PermCouriersRead   Permission = "couriers:read"
+	PermCouriersManage Permission = "couriers:manage"
+	PermShiftsManage   Permission = "shifts:manage"
+	PermZonesRead      Permission = "zones:read"
+	PermZonesManage    Permission = "zones:manage"
+	PermPricingRead    Permission = "pricing:read"
+	PermPricingEdit    Permission = "pricing:edit"
+	PermUsersManage    Permission = "users:manage"
+	PermAuditRead      Permission = "audit:read"
+	PermSLAManage      Permission = "sla:manage"
+	PermReportsRead    Permission = "reports:read"
+	PermPayoutsManage  Permission = "payouts:manage"
+	// PermAll — все права, включая появившиеся позже.
+	PermAll Permission = "*"
+)
+
+// AllPermissions — права, которые можно выдать администратору.
+var AllPermissions = []Permission{
+	PermOrdersRead,
+	PermOrdersCancel,
+	PermCouriersRead,
+	PermCouriersManage,
+	PermShiftsManage,
+	PermZonesRead,
+	PermZonesManage,
+	PermPricingRead,
+	PermPricingEdit,
+	PermUsersManage,
+	PermAuditRead,
+	PermSLAManage,
+	PermReportsRead,
+	PermPayoutsManage,
+	PermAll,
+}
+
+func (p Permission) Valid() bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions — набор прав из admins.permissions.
+type Permissions []Permission
+
+func (ps Permissions) Has(p Permission) bool {
+	for _, have := range ps {
+		if have == p || have == PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+type Admin struct {
+	UserID      uuid.UUID   `json:"user_id"`
+	Email       string      `json:"email"`
+	Permissions Permissions `json:"permissions"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Change — значение поля до и после запроса.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// AuditEvent — запись журнала изменяющих запросов. Action — метод и
+// шаблон маршрута, например "POST /admin/couriers/:id/suspend".
+type AuditEvent struct {
+	ID         uuid.UUID         `json:"id"`
+	ActorID    *uuid.UUID        `json:"actor_id,omitempty"`
+	ActorRole  Role              `json:"actor_role,omitempty"`
+	Action     string            `json:"action"`
+	Path       string            `json:"path"`
+	EntityType string            `json:"entity_type"`
+	EntityID   *uuid.UUID        `json:"entity_id,omitempty"`
+	Status     int               `json:"status"`
+	RequestID  string            `json:"request_id"`
+	Changes    map[string]Change `json:"changes"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client — профиль клиента; Email берётся из учётной записи.
+type Client struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	Name    string    `json:"name"`
+	Phone   string    `json:"phone"`
+	Address string    `json:"address"`
+}
+
+// ClientAddress — сохранённый адрес из адресной книги клиента.
+type ClientAddress struct {
+	ID        uuid.UUID   `json:"id"`
+	ClientID  uuid.UUID   `json:"client_id"`
+	Label     string      `json:"label"`
+	Address   string      `json:"address"`
+	Location  Coordinates `json:"location"`
+	Notes     string      `json:"notes"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
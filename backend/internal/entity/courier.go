@@ -3,6 +3,7 @@ package entity
 import (
 	"encoding/binary"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/twpayne/go-geom"
@@ -17,6 +18,16 @@ const (
 	CourierStatusOffline   CourierStatus = "OFFLINE"
 )
 
+// AccountStatus — состояние учётной записи курьера, независимое от
+// рабочего CourierStatus.
+type AccountStatus string
+
+const (
+	AccountActive      AccountStatus = "ACTIVE"
+	AccountSuspended   AccountStatus = "SUSPENDED"
+	AccountDeactivated AccountStatus = "DEACTIVATED"
+)
+
 type Coordinates struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
@@ -28,6 +39,51 @@ type Courier struct {
 	Status   CourierStatus `db:"status" json:"status"`
 	Location *Coordinates  `db:"location" json:"location"`
 	Rating   float64       `db:"rating" json:"rating"`
+	// Capacity — сколько заказов курьер может везти одновременно.
+	Capacity     int `db:"capacity" json:"capacity"`
+	ActiveOrders int `db:"-" json:"active_orders"`
+	// ZoneIDs — домашние зоны курьера.
+	ZoneIDs []uuid.UUID `db:"-" json:"zone_ids"`
+	VehicleType VehicleType `db:"vehicle_type" json:"vehicle_type"`
+	// MaxWeightKg и MaxVolumeL — сколько курьер может везти за раз.
+	MaxWeightKg float64 `db:"max_weight_kg" json:"max_weight_kg"`
+	MaxVolumeL  float64 `db:"max_volume_l" json:"max_volume_l"`
+	// ActiveWeightKg и ActiveVolumeL — груз активных заказов.
+	ActiveWeightKg float64 `db:"-" json:"active_weight_kg"`
+	ActiveVolumeL  float64 `db:"-" json:"active_volume_l"`
+	// LastSeenAt — последний heartbeat или координата от приложения.
+	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
+	Email      string    `db:"-" json:"email,omitempty"`
+	// AccountStatus и StatusReason — блокировка курьера администратором.
+	AccountStatus AccountStatus `db:"account_status" json:"account_status"`
+	StatusReason  string        `db:"status_reason" json:"status_reason,omitempty"`
+	// AccountStatusChangedAt — когда менялся AccountStatus; от него
+	// отсчитывается срок хранения данных деактивированного курьера.
+	AccountStatusChangedAt *time.Time `db:"account_status_changed_at" json:"account_status_changed_at,omitempty"`
+	// Version растёт при каждом изменении профиля; отдаётся как ETag.
+	Version int `db:"version" json:"version"`
+}
+
+// CanCarry — поместится ли посылка в транспорт вместе с уже взятыми заказами.
+func (c *Courier) CanCarry(weightKg, volumeL float64) bool {
+	return c.ActiveWeightKg+weightKg <= c.MaxWeightKg &&
+		c.ActiveVolumeL+volumeL <= c.MaxVolumeL
+}
+
+func (c *Courier) HasCapacity() bool {
+	return c.ActiveOrders < c.Capacity
+}
+
+// LoadStatus — статус, соответствующий текущей загрузке: BUSY только при
+// заполненной вместимости. OFFLINE не трогаем — его выставляет сам курьер.
+func (c *Courier) LoadStatus() CourierStatus {
+	if c.Status == CourierStatusOffline {
+		return c.Status
+	}
+	if c.HasCapacity() {
+		return CourierStatusAvailable
+	}
+	return CourierStatusBusy
 }
 
 
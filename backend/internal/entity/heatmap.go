@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeatmapQuery — спрос за период [From, To) в ячейках геохеша длины
+// Precision; ZoneID ограничивает карту одной зоной.
+type HeatmapQuery struct {
+	From      time.Time
+	To        time.Time
+	Precision int
+	ZoneID    *uuid.UUID
+}
+
+// HeatmapCell — ячейка карты: Demand — заказы с адресом доставки в ней
+// за период, Supply — свободные курьеры в ней сейчас.
+type HeatmapCell struct {
+	Geohash string
+	Demand  int
+	Supply  int
+}
+
+// Ratio — курьеров на заказ; nil, если заказов в ячейке не было.
+func (c *HeatmapCell) Ratio() *float64 {
+	if c.Demand == 0 {
+		return nil
+	}
+	r := float64(c.Supply) / float64(c.Demand)
+	return &r
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// IdempotencyRecord — запрос с заголовком Idempotency-Key и сохранённый
+// ответ на него.
+type IdempotencyRecord struct {
+	// Scope — пользователь, отправивший запрос; пустой для запросов без
+	// токена.
+	Scope       string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	// Status 0 — запрос ещё выполняется.
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
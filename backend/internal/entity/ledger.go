@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerKind — вид проводки по счёту курьера.
+type LedgerKind string
+
+const (
+	LedgerDelivery   LedgerKind = "DELIVERY"
+	LedgerTip        LedgerKind = "TIP"
+	LedgerAdjustment LedgerKind = "ADJUSTMENT"
+	LedgerPayout     LedgerKind = "PAYOUT"
+)
+
+// LedgerAccount — счёт двойной записи. COURIER_PAYABLE — наш долг перед
+// курьером, остальные — откуда деньги пришли или куда ушли.
+type LedgerAccount string
+
+const (
+	AccountCourierPayable  LedgerAccount = "COURIER_PAYABLE"
+	AccountEarningsExpense LedgerAccount = "EARNINGS_EXPENSE"
+	AccountTipsClearing    LedgerAccount = "TIPS_CLEARING"
+	AccountPayoutsClearing LedgerAccount = "PAYOUTS_CLEARING"
+)
+
+// EarningComponent — из чего сложился заработок.
+type EarningComponent string
+
+const (
+	EarningBase       EarningComponent = "BASE"
+	EarningDistance   EarningComponent = "DISTANCE"
+	EarningTip        EarningComponent = "TIP"
+	EarningBonus      EarningComponent = "BONUS"
+	EarningAdjustment EarningComponent = "ADJUSTMENT"
+	EarningPayout     EarningComponent = "PAYOUT"
+)
+
+// LedgerEntry — строка проводки: дебет положительный, кредит
+// отрицательный. Суммы — в минимальных единицах валюты.
+type LedgerEntry struct {
+	Account   LedgerAccount    `json:"account"`
+	Component EarningComponent `json:"component"`
+	Amount    int64            `json:"amount"`
+}
+
+type LedgerTransaction struct {
+	ID          uuid.UUID     `json:"id"`
+	Kind        LedgerKind    `json:"kind"`
+	CourierID   uuid.UUID     `json:"courier_id"`
+	OrderID     *uuid.UUID    `json:"order_id,omitempty"`
+	PayoutID    *uuid.UUID    `json:"payout_id,omitempty"`
+	Currency    string        `json:"currency"`
+	Description string        `json:"description,omitempty"`
+	CreatedBy   *uuid.UUID    `json:"created_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Entries     []LedgerEntry `json:"entries"`
+}
+
+// Credit начисляет курьеру amount за счёт from: дебет from, кредит
+// COURIER_PAYABLE. Отрицательная сумма — удержание. Нулевые суммы не
+// записываются.
+func (t *LedgerTransaction) Credit(from LedgerAccount, c EarningComponent, amount int64) {
+	if amount == 0 {
+		return
+	}
+	t.Entries = append(t.Entries,
+		LedgerEntry{Account: from, Component: c, Amount: amount},
+		LedgerEntry{Account: AccountCourierPayable, Component: c, Amount: -amount},
+	)
+}
+
+// Balanced — дебет равен кредиту.
+func (t *LedgerTransaction) Balanced() bool {
+	var sum int64
+	for _, e := range t.Entries {
+		sum += e.Amount
+	}
+	return sum == 0 && len(t.Entries) > 0
+}
+
+// Earned — сколько проводка добавила к долгу перед курьером по
+// компонентам.
+func (t *LedgerTransaction) Earned() map[EarningComponent]int64 {
+	ret := make(map[EarningComponent]int64)
+	for _, e := range t.Entries {
+		if e.Account == AccountCourierPayable {
+			ret[e.Component] -= e.Amount
+		}
+	}
+	return ret
+}
+
+// EarningsPeriodKind — на какие отрезки делится сводка заработка.
+type EarningsPeriodKind string
+
+const (
+	EarningsByDay  EarningsPeriodKind = "day"
+	EarningsByWeek EarningsPeriodKind = "week"
+)
+
+type EarningsQuery struct {
+	From   time.Time
+	To     time.Time
+	Period EarningsPeriodKind
+}
+
+// EarningsSummary — заработок курьера за период. Total не включает
+// выплаты; Balance — сколько мы должны курьеру сейчас.
+type EarningsSummary struct {
+	CourierID  uuid.UUID                  `json:"courier_id"`
+	From       time.Time                  `json:"from"`
+	To         time.Time                  `json:"to"`
+	Period     EarningsPeriodKind         `json:"period"`
+	Currency   string                     `json:"currency"`
+	Total      int64                      `json:"total"`
+	Orders     int                        `json:"orders"`
+	Components map[EarningComponent]int64 `json:"components"`
+	Periods    []EarningsPeriod           `json:"periods"`
+	Balance    int64                      `json:"balance"`
+}
+
+type EarningsPeriod struct {
+	Start  time.Time `json:"start"`
+	Total  int64     `json:"total"`
+	Orders int       `json:"orders"`
+}
+
+// PayoutBatch — реестр выплат: каждому курьеру с положительным балансом
+// на Cutoff — одна строка.
+type PayoutBatch struct {
+	ID        uuid.UUID    `json:"id"`
+	Cutoff    time.Time    `json:"cutoff"`
+	Currency  string       `json:"currency"`
+	Total     int64        `json:"total"`
+	Couriers  int          `json:"couriers"`
+	CreatedBy *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	Lines     []PayoutLine `json:"lines,omitempty"`
+}
+
+type PayoutLine struct {
+	CourierID   uuid.UUID `json:"courier_id"`
+	CourierName string    `json:"courier_name"`
+	Amount      int64     `json:"amount"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocationFix — одна точка из истории перемещений курьера.
+type LocationFix struct {
+	CourierID  uuid.UUID   `json:"courier_id"`
+	Location   Coordinates `json:"location"`
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+type AnomalyKind string
+
+const (
+	// AnomalyNullIsland — координаты (0,0): типичный признак пустого или
+	// подделанного фикса.
+	AnomalyNullIsland AnomalyKind = "NULL_ISLAND"
+	// AnomalyOutOfOrder — время фикса раньше предыдущего принятого.
+	AnomalyOutOfOrder AnomalyKind = "OUT_OF_ORDER"
+	// AnomalyImpossibleSpeed — скорость от предыдущего фикса выше допустимой.
+	AnomalyImpossibleSpeed AnomalyKind = "IMPOSSIBLE_SPEED"
+	// AnomalyTeleport — скачок на большое расстояние с невозможной скоростью.
+	AnomalyTeleport AnomalyKind = "TELEPORT"
+	// AnomalyFutureTimestamp — время фикса опережает время сервера больше
+	// допустимого расхождения часов.
+	AnomalyFutureTimestamp AnomalyKind = "FUTURE_TIMESTAMP"
+)
+
+// LocationAnomaly — подозрительный фикс, сохранённый для разбора
+// администратором. Rejected — координаты не были приняты.
+type LocationAnomaly struct {
+	ID           uuid.UUID     `json:"id"`
+	CourierID    uuid.UUID     `json:"courier_id"`
+	Kinds        []AnomalyKind `json:"kinds"`
+	Location     Coordinates   `json:"location"`
+	PrevLocation *Coordinates  `json:"prev_location,omitempty"`
+	SpeedMps     *float64      `json:"speed_mps,omitempty"`
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Rejected     bool          `json:"rejected"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
@@ -19,6 +19,28 @@ const (
 	StatusCanceled  OrderStatus = "CANCELED"
 )
 
+type StopKind string
+
+const (
+	StopPickup  StopKind = "PICKUP"
+	StopDropoff StopKind = "DROPOFF"
+)
+
+// OrderStop — точка маршрута заказа; Sequence задаёт порядок внутри заказа.
+type OrderStop struct {
+	ID          uuid.UUID   `json:"id"`
+	OrderID     uuid.UUID   `json:"order_id"`
+	Sequence    int         `json:"sequence"`
+	Kind        StopKind    `json:"kind"`
+	Address     string      `json:"address"`
+	Location    Coordinates `json:"location"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	// RoutePosition — место точки в текущем маршруте курьера.
+	RoutePosition *int `json:"route_position,omitempty"`
+	// ArrivedAt — когда геозона зафиксировала прибытие курьера.
+	ArrivedAt *time.Time `json:"arrived_at,omitempty"`
+}
+
 type Order struct {
 	ID              uuid.UUID   `json:"id"`
 	ClientID        uuid.UUID   `json:"client_id"`
@@ -26,6 +48,39 @@ type Order struct {
 	Status          OrderStatus `json:"status"`
 	DeliveryAddress string      `json:"delivery_address"`
 	DeliveryCoords  string      `json:"delivery_coords"` 
+	Stops           []OrderStop `json:"stops,omitempty"`
+	// AddressID — сохранённый адрес клиента, из которого взята доставка.
+	AddressID     *uuid.UUID `json:"address_id,omitempty"`
+	DeliveryNotes string     `json:"delivery_notes,omitempty"`
+	// DeliveryCode — код вручения для получателя. Отдаётся только в ответе
+	// на создание заказа и клиенту через /me/orders/{id}/delivery-code.
+	DeliveryCode string `json:"delivery_code,omitempty"`
+	// WindowStart и WindowEnd — окно, в которое клиент просит доставить
+	// заказ; пустые — «как можно скорее».
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+	// ReleaseAt — с какого момента заказ попадает в автоназначение.
+	ReleaseAt *time.Time `json:"release_at,omitempty"`
+	// WindowAtRisk — заказ, по прогнозу, не успевает в окно доставки.
+	WindowAtRisk bool `json:"window_at_risk"`
+	EstimatedPickupAt   *time.Time `json:"estimated_pickup_at,omitempty"`
+	EstimatedDeliveryAt *time.Time `json:"estimated_delivery_at,omitempty"`
+	QuoteID             *uuid.UUID `json:"quote_id,omitempty"`
+	Price               *int64     `json:"price,omitempty"`
+	Currency            string     `json:"currency,omitempty"`
+	// SurgeMultiplier — множитель спроса из котировки, по которой
+	// посчитана цена.
+	SurgeMultiplier *float64 `json:"surge_multiplier,omitempty"`
+	ZoneID              *uuid.UUID `json:"zone_id,omitempty"`
+	// OutOfZone — адрес доставки не попал ни в одну активную зону.
+	OutOfZone bool `json:"out_of_zone"`
+	// Габариты посылки; 0 — не указано.
+	WeightKg float64 `json:"weight_kg"`
+	LengthCm float64 `json:"length_cm"`
+	WidthCm  float64 `json:"width_cm"`
+	HeightCm float64 `json:"height_cm"`
+	// Version растёт при каждом изменении заказа; отдаётся как ETag.
+	Version int `json:"version"`
 	CreatedAt       time.Time   `json:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at"`
 }
@@ -45,3 +100,34 @@ func (o *Order) ParseCoords() (lat, lon float64, err error) {
 	}
 	return lat, lon, nil
 }
+
+// VolumeLiters — объём посылки по габаритам.
+func (o *Order) VolumeLiters() float64 {
+	return o.LengthCm * o.WidthCm * o.HeightCm / 1000
+}
+
+// IsActive сообщает, занимает ли заказ место в загрузке курьера.
+func (o *Order) IsActive() bool {
+	return o.Status == StatusAssigned || o.Status == StatusInTransit
+}
+
+// HasWindow сообщает, задано ли окно доставки.
+func (o *Order) HasWindow() bool {
+	return o.WindowStart != nil || o.WindowEnd != nil
+}
+
+// Scheduled сообщает, что заказ ещё не отпущен в автоназначение.
+func (o *Order) Scheduled(now time.Time) bool {
+	return o.ReleaseAt != nil && now.Before(*o.ReleaseAt)
+}
+
+// PendingStops возвращает ещё не пройденные точки в порядке Sequence.
+func (o *Order) PendingStops() []OrderStop {
+	var ret []OrderStop
+	for _, s := range o.Stops {
+		if s.CompletedAt == nil {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
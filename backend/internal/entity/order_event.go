@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderEventType string
+
+const (
+	EventStatusChanged   OrderEventType = "STATUS_CHANGED"
+	EventArrivedAtPickup OrderEventType = "ARRIVED_AT_PICKUP"
+	EventArrivedAtDrop   OrderEventType = "ARRIVED_AT_DROPOFF"
+	// EventWindowAtRisk — прогноз доставки вышел за окно клиента.
+	EventWindowAtRisk OrderEventType = "WINDOW_AT_RISK"
+)
+
+// OrderEvent — запись в хронологии заказа. Для STATUS_CHANGED заполнен
+// Status, для событий геозоны — StopID и Location.
+type OrderEvent struct {
+	ID        uuid.UUID      `json:"id"`
+	OrderID   uuid.UUID      `json:"order_id"`
+	Type      OrderEventType `json:"type"`
+	Status    OrderStatus    `json:"status,omitempty"`
+	StopID    *uuid.UUID     `json:"stop_id,omitempty"`
+	Location  *Coordinates   `json:"location,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ArrivalEvent — тип события прибытия для точки данного вида.
+func ArrivalEvent(kind StopKind) OrderEventType {
+	if kind == StopPickup {
+		return EventArrivedAtPickup
+	}
+	return EventArrivedAtDrop
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Все денежные суммы — в минимальных единицах валюты (копейки, центы).
+
+type WeightTier struct {
+	UpToKg    float64 `json:"up_to_kg"`
+	Surcharge int64   `json:"surcharge"`
+}
+
+// TimeMultiplier действует с From до To по местному времени правил
+// ("HH:MM"); окно может переходить через полночь, например 22:00–06:00.
+type TimeMultiplier struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// ZoneSurcharge — доплата, если забор или выдача попадает в круг зоны.
+type ZoneSurcharge struct {
+	Name         string      `json:"name"`
+	Center       Coordinates `json:"center"`
+	RadiusMeters float64     `json:"radius_meters"`
+	Surcharge    int64       `json:"surcharge"`
+}
+
+// SurgeRules — повышающий множитель по загрузке зоны: отношение открытых
+// заказов к свободным курьерам выше Threshold повышает цену на Step за
+// каждую единицу, но не выше Max. Smoothing — доля, на которую множитель
+// сдвигается к новому значению за один пересчёт (1 — сразу).
+type SurgeRules struct {
+	Enabled   bool    `json:"enabled"`
+	Threshold float64 `json:"ratio_threshold"`
+	Step      float64 `json:"step"`
+	Max       float64 `json:"max_multiplier"`
+	Smoothing float64 `json:"smoothing"`
+}
+
+type PricingRules struct {
+	Version         int64            `json:"version"`
+	Currency        string           `json:"currency"`
+	BaseFare        int64            `json:"base_fare"`
+	PerKm           int64            `json:"per_km"`
+	MinimumFare     int64            `json:"minimum_fare"`
+	Timezone        string           `json:"timezone"`
+	WeightTiers     []WeightTier     `json:"weight_tiers"`
+	TimeMultipliers []TimeMultiplier `json:"time_multipliers"`
+	ZoneSurcharges  []ZoneSurcharge  `json:"zone_surcharges"`
+	Surge           *SurgeRules      `json:"surge,omitempty"`
+	CreatedBy       *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// PriceLine — строка расшифровки цены.
+type PriceLine struct {
+	Kind   string  `json:"kind"`
+	Label  string  `json:"label,omitempty"`
+	Amount int64   `json:"amount,omitempty"`
+	Factor float64 `json:"factor,omitempty"`
+}
+
+type Quote struct {
+	ID             uuid.UUID   `json:"id"`
+	Pickup         Coordinates `json:"pickup"`
+	Dropoff        Coordinates `json:"dropoff"`
+	DistanceMeters float64     `json:"distance_meters"`
+	WeightKg       float64     `json:"weight_kg"`
+	Price          int64       `json:"price"`
+	Currency       string      `json:"currency"`
+	Breakdown      []PriceLine `json:"breakdown"`
+	RulesVersion   int64       `json:"rules_version"`
+	// ZoneID и SurgeMultiplier — зона адреса выдачи и множитель спроса,
+	// действовавший при расчёте.
+	ZoneID          *uuid.UUID `json:"zone_id,omitempty"`
+	SurgeMultiplier float64    `json:"surge_multiplier"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	UsedAt          *time.Time `json:"used_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (q *Quote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProofKind string
+
+const (
+	// ProofOTP — получатель назвал код, выданный клиенту при создании заказа.
+	ProofOTP       ProofKind = "OTP"
+	ProofSignature ProofKind = "SIGNATURE"
+	ProofPhoto     ProofKind = "PHOTO"
+)
+
+func (k ProofKind) Valid() bool {
+	switch k {
+	case ProofOTP, ProofSignature, ProofPhoto:
+		return true
+	}
+	return false
+}
+
+// HasFile — подтверждение сопровождается изображением.
+func (k ProofKind) HasFile() bool {
+	return k == ProofSignature || k == ProofPhoto
+}
+
+// DeliveryProof — подтверждение вручения на точке выдачи.
+type DeliveryProof struct {
+	ID          uuid.UUID   `json:"id"`
+	OrderID     uuid.UUID   `json:"order_id"`
+	StopID      uuid.UUID   `json:"stop_id"`
+	CourierID   *uuid.UUID  `json:"courier_id,omitempty"`
+	Kind        ProofKind   `json:"kind"`
+	FileKey     string      `json:"-"`
+	ContentType string      `json:"content_type,omitempty"`
+	SizeBytes   int64       `json:"size_bytes,omitempty"`
+	Location    Coordinates `json:"location"`
+	RecordedAt  time.Time   `json:"recorded_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
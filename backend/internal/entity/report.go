@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+// ReportGroup — разрез, по которому агрегируется отчёт.
+type ReportGroup string
+
+const (
+	ReportByDay     ReportGroup = "day"
+	ReportByZone    ReportGroup = "zone"
+	ReportByCourier ReportGroup = "courier"
+)
+
+func (g ReportGroup) Valid() bool {
+	switch g {
+	case ReportByDay, ReportByZone, ReportByCourier:
+		return true
+	}
+	return false
+}
+
+// ReportQuery — период [From, To) по времени создания заказа и разрез.
+// Дни считаются в часовом поясе Location.
+type ReportQuery struct {
+	GroupBy  ReportGroup
+	From     time.Time
+	To       time.Time
+	Location string
+}
+
+// Key строки отчёта — дата YYYY-MM-DD, id зоны или курьера, Label — имя
+// зоны или курьера. Заказы без зоны или курьера попадают в строку с пустым
+// ключом.
+type VolumeRow struct {
+	Key       string `json:"key"`
+	Label     string `json:"label,omitempty"`
+	Orders    int    `json:"orders"`
+	Delivered int    `json:"delivered"`
+	Canceled  int    `json:"canceled"`
+	// InProgress — ни доставлен, ни отменён на момент обновления отчёта.
+	InProgress int `json:"in_progress"`
+}
+
+type TimingRow struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"`
+	// Assigned и Delivered — сколько заказов вошло в среднее; время
+	// считается от создания заказа или от release_at.
+	Assigned          int      `json:"assigned"`
+	AvgAssignSeconds  *float64 `json:"avg_assign_seconds"`
+	Delivered         int      `json:"delivered"`
+	AvgDeliverSeconds *float64 `json:"avg_deliver_seconds"`
+}
+
+type CancellationRow struct {
+	Key      string `json:"key"`
+	Label    string `json:"label,omitempty"`
+	Orders   int    `json:"orders"`
+	Canceled int    `json:"canceled"`
+	// CanceledUnassigned — отменены до назначения курьера.
+	CanceledUnassigned int     `json:"canceled_unassigned"`
+	CancellationRate   float64 `json:"cancellation_rate"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "github.com/google/uuid"
+
+// RouteStop — точка маршрута курьера с длиной участка от предыдущей точки.
+type RouteStop struct {
+	OrderStop
+	LegMeters        float64 `json:"leg_meters"`
+	CumulativeMeters float64 `json:"cumulative_meters"`
+}
+
+type Route struct {
+	CourierID   uuid.UUID   `json:"courier_id"`
+	Start       Coordinates `json:"start"`
+	Stops       []RouteStop `json:"stops"`
+	TotalMeters float64     `json:"total_meters"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shift — плановая смена курьера и фактические отметки начала и конца.
+type Shift struct {
+	ID           uuid.UUID  `json:"id"`
+	CourierID    uuid.UUID  `json:"courier_id"`
+	ZoneID       *uuid.UUID `json:"zone_id,omitempty"`
+	PlannedStart time.Time  `json:"planned_start"`
+	PlannedEnd   time.Time  `json:"planned_end"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsActive — курьер отметился на смене и ещё не ушёл.
+func (s *Shift) IsActive() bool {
+	return s.StartedAt != nil && s.EndedAt == nil
+}
+
+// Overlaps — пересекаются ли плановые интервалы смен.
+func (s *Shift) Overlaps(o *Shift) bool {
+	return s.PlannedStart.Before(o.PlannedEnd) && o.PlannedStart.Before(s.PlannedEnd)
+}
+
+// ShiftSummary — итоги смены для расчёта оплаты.
+type ShiftSummary struct {
+	ShiftID         uuid.UUID  `json:"shift_id"`
+	CourierID       uuid.UUID  `json:"courier_id"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	WorkedSeconds   int64      `json:"worked_seconds"`
+	OrdersDelivered int        `json:"orders_delivered"`
+	DistanceMeters  float64    `json:"distance_meters"`
+}
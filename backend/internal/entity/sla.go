@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLAMetric — этап заказа, на который у нас договорное время. Отсчёт идёт
+// от release_at заказа с окном доставки или от его создания.
+type SLAMetric string
+
+const (
+	SLATimeToAssign  SLAMetric = "TIME_TO_ASSIGN"
+	SLATimeToPickup  SLAMetric = "TIME_TO_PICKUP"
+	SLATimeToDeliver SLAMetric = "TIME_TO_DELIVER"
+)
+
+var SLAMetrics = []SLAMetric{SLATimeToAssign, SLATimeToPickup, SLATimeToDeliver}
+
+func (m SLAMetric) Valid() bool {
+	for _, known := range SLAMetrics {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Milestones — статусы, первый переход в любой из которых завершает этап.
+func (m SLAMetric) Milestones() []OrderStatus {
+	switch m {
+	case SLATimeToAssign:
+		return []OrderStatus{StatusAssigned, StatusInTransit, StatusDelivered}
+	case SLATimeToPickup:
+		return []OrderStatus{StatusInTransit, StatusDelivered}
+	case SLATimeToDeliver:
+		return []OrderStatus{StatusDelivered}
+	}
+	return nil
+}
+
+type SLAPolicy struct {
+	Metric           SLAMetric `json:"metric"`
+	ThresholdSeconds int64     `json:"threshold_seconds"`
+	// WarnBeforeSeconds — за сколько до нарушения заказ считается под
+	// угрозой; 0 — не предупреждать.
+	WarnBeforeSeconds int64      `json:"warn_before_seconds"`
+	Enabled           bool       `json:"enabled"`
+	UpdatedBy         *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (p *SLAPolicy) Threshold() time.Duration {
+	return time.Duration(p.ThresholdSeconds) * time.Second
+}
+
+func (p *SLAPolicy) WarnBefore() time.Duration {
+	return time.Duration(p.WarnBeforeSeconds) * time.Second
+}
+
+type SLABreach struct {
+	ID               uuid.UUID  `json:"id"`
+	OrderID          uuid.UUID  `json:"order_id"`
+	Metric           SLAMetric  `json:"metric"`
+	ThresholdSeconds int64      `json:"threshold_seconds"`
+	Deadline         time.Time  `json:"deadline"`
+	ReachedAt        *time.Time `json:"reached_at,omitempty"`
+	DetectedAt       time.Time  `json:"detected_at"`
+}
+
+// SLARisk — заказ, который ещё не нарушил SLA, но скоро нарушит.
+type SLARisk struct {
+	OrderID  uuid.UUID   `json:"order_id"`
+	Metric   SLAMetric   `json:"metric"`
+	Status   OrderStatus `json:"status"`
+	Deadline time.Time   `json:"deadline"`
+}
+
+// SLAReport — нарушения за период и их число по метрикам.
+type SLAReport struct {
+	Breaches []*SLABreach      `json:"breaches"`
+	Counts   map[SLAMetric]int `json:"counts"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ZoneSurge — загрузка зоны и множитель спроса для её котировок.
+type ZoneSurge struct {
+	ZoneID   uuid.UUID `json:"zone_id"`
+	ZoneName string    `json:"zone_name"`
+	// OpenOrders — заказы без курьера, уже доступные для назначения;
+	// AvailableCouriers — свободные курьеры на открытой смене в зоне.
+	OpenOrders        int `json:"open_orders"`
+	AvailableCouriers int `json:"available_couriers"`
+	// Multiplier — сглаженный расчётный множитель.
+	Multiplier float64 `json:"multiplier"`
+	// Override задаёт множитель вручную до OverrideExpiresAt (nil —
+	// бессрочно).
+	Override          *float64   `json:"override,omitempty"`
+	OverrideExpiresAt *time.Time `json:"override_expires_at,omitempty"`
+	OverrideBy        *uuid.UUID `json:"override_by,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Effective — множитель, который действует для котировок в момент now.
+func (s *ZoneSurge) Effective(now time.Time) float64 {
+	if s.Override != nil && (s.OverrideExpiresAt == nil || now.Before(*s.OverrideExpiresAt)) {
+		return *s.Override
+	}
+	if s.Multiplier <= 0 {
+		return 1
+	}
+	return s.Multiplier
+}
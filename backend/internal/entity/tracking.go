@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrackingLink — публичная ссылка отслеживания заказа для получателя.
+type TrackingLink struct {
+	ID        uuid.UUID  `json:"-"`
+	OrderID   uuid.UUID  `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PublicTracking — то, что видит получатель по ссылке: без данных клиента,
+// адресов и внутренних идентификаторов.
+type PublicTracking struct {
+	Status OrderStatus `json:"status"`
+	// Stops — точки маршрута в порядке следования, без адресов.
+	Stops []PublicStop `json:"stops"`
+	// CourierLocation — огрублённое положение курьера, пока заказ в работе.
+	CourierLocation     *Coordinates `json:"courier_location,omitempty"`
+	LocationUpdatedAt   *time.Time   `json:"location_updated_at,omitempty"`
+	EstimatedPickupAt   *time.Time   `json:"estimated_pickup_at,omitempty"`
+	EstimatedDeliveryAt *time.Time   `json:"estimated_delivery_at,omitempty"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+	ExpiresAt           time.Time    `json:"expires_at"`
+}
+
+type PublicStop struct {
+	Kind        StopKind   `json:"kind"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
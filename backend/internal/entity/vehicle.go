@@ -0,0 +1,38 @@
+package entity
+
+type VehicleType string
+
+const (
+	VehicleFoot    VehicleType = "FOOT"
+	VehicleBike    VehicleType = "BIKE"
+	VehicleScooter VehicleType = "SCOOTER"
+	VehicleCar     VehicleType = "CAR"
+	VehicleVan     VehicleType = "VAN"
+)
+
+// VehicleSpec — типовые характеристики транспорта. Вместимость курьера
+// можно переопределить, скорость используется для ETA без истории.
+type VehicleSpec struct {
+	SpeedKmh    float64
+	MaxWeightKg float64
+	MaxVolumeL  float64
+}
+
+var vehicleSpecs = map[VehicleType]VehicleSpec{
+	VehicleFoot:    {SpeedKmh: 5, MaxWeightKg: 5, MaxVolumeL: 20},
+	VehicleBike:    {SpeedKmh: 15, MaxWeightKg: 15, MaxVolumeL: 50},
+	VehicleScooter: {SpeedKmh: 25, MaxWeightKg: 20, MaxVolumeL: 60},
+	VehicleCar:     {SpeedKmh: 30, MaxWeightKg: 200, MaxVolumeL: 500},
+	VehicleVan:     {SpeedKmh: 25, MaxWeightKg: 1000, MaxVolumeL: 5000},
+}
+
+func (v VehicleType) Valid() bool {
+	_, ok := vehicleSpecs[v]
+	return ok
+}
+
+// Spec возвращает характеристики типа; для неизвестного типа ok=false.
+func (v VehicleType) Spec() (spec VehicleSpec, ok bool) {
+	spec, ok = vehicleSpecs[v]
+	return spec, ok
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Zone — зона доставки. Boundary хранится как GeoJSON MultiPolygon.
+type Zone struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Boundary  json.RawMessage `json:"boundary"`
+	Active    bool            `json:"active"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,78 @@
+// Package eta оценивает время прибытия курьера по точкам маршрута.
+package eta
+
+import (
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+
+	"github.com/google/uuid"
+)
+
+// Скорости вне этого диапазона считаем шумом GPS, а не движением.
+const (
+	minSpeed = 0.5  // м/с
+	maxSpeed = 40.0 // м/с
+)
+
+// Estimate — ожидаемое время забора и выдачи для одного заказа.
+// Поле пустое, если соответствующих точек в маршруте не осталось.
+type Estimate struct {
+	PickupAt   *time.Time
+	DeliveryAt *time.Time
+}
+
+// ForRoute проходит маршрут со скоростью speed (м/с), тратя dwell на каждой
+// точке, и возвращает оценки по заказам. PickupAt — прибытие к первой точке
+// забора заказа, DeliveryAt — к последней точке выдачи.
+func ForRoute(r *entity.Route, speed float64, dwell time.Duration, now time.Time) map[uuid.UUID]Estimate {
+	ret := make(map[uuid.UUID]Estimate)
+	if speed <= 0 {
+		return ret
+	}
+
+	at := now
+	for _, st := range r.Stops {
+		at = at.Add(time.Duration(st.LegMeters / speed * float64(time.Second)))
+		arrival := at
+
+		e := ret[st.OrderID]
+		switch st.Kind {
+		case entity.StopPickup:
+			if e.PickupAt == nil {
+				e.PickupAt = &arrival
+			}
+		case entity.StopDropoff:
+			e.DeliveryAt = &arrival
+		}
+		ret[st.OrderID] = e
+
+		at = at.Add(dwell)
+	}
+	return ret
+}
+
+// AverageSpeed — средняя скорость по истории координат (м/с). Отрезки
+// с разрывом больше maxGap пропускаются: курьер был офлайн или стоял.
+// ok=false, если данных недостаточно для оценки.
+func AverageSpeed(fixes []entity.LocationFix, maxGap time.Duration) (speed float64, ok bool) {
+	var dist float64
+	var elapsed time.Duration
+	for i := 1; i < len(fixes); i++ {
+		dt := fixes[i].RecordedAt.Sub(fixes[i-1].RecordedAt)
+		if dt <= 0 || dt > maxGap {
+			continue
+		}
+		dist += geo.Haversine(fixes[i-1].Location, fixes[i].Location)
+		elapsed += dt
+	}
+	if elapsed < time.Minute {
+		return 0, false
+	}
+	speed = dist / elapsed.Seconds()
+	if speed < minSpeed || speed > maxSpeed {
+		return 0, false
+	}
+	return speed, true
+}
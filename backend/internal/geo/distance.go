@@ -0,0 +1,50 @@
+package geo
+
+import (
+	"math"
+
+	"backend/internal/entity"
+)
+
+const EarthRadiusMeters = 6371000.0
+
+// Haversine возвращает расстояние по большому кругу между двумя точками в метрах.
+func Haversine(a, b entity.Coordinates) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PathLength — длина пути от start через points по порядку.
+func PathLength(start entity.Coordinates, points []entity.Coordinates) float64 {
+	total := 0.0
+	prev := start
+	for _, p := range points {
+		total += Haversine(prev, p)
+		prev = p
+	}
+	return total
+}
+
+// metersPerDegree — длина градуса широты.
+const metersPerDegree = EarthRadiusMeters * math.Pi / 180
+
+// Snap огрубляет координаты до центра ячейки сетки со стороной cell
+// метров, чтобы не раскрывать точное положение.
+func Snap(c entity.Coordinates, cell float64) entity.Coordinates {
+	if cell <= 0 {
+		return c
+	}
+	latStep := cell / metersPerDegree
+	lat := (math.Floor(c.Latitude/latStep) + 0.5) * latStep
+	// шаг долготы считаем по широте ячейки, чтобы он был одинаков для
+	// всех точек внутри неё
+	lonStep := latStep / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+	lon := (math.Floor(c.Longitude/lonStep) + 0.5) * lonStep
+	return entity.Coordinates{Latitude: lat, Longitude: lon}
+}
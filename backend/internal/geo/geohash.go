@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+
+	"backend/internal/entity"
+)
+
+// geohashAlphabet — base32 геохеша (без a, i, l, o).
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+var ErrInvalidGeohash = errors.New("invalid geohash")
+
+// Geohash кодирует точку в геохеш из precision символов. Совпадает с
+// ST_GeoHash в PostGIS, поэтому ячейки из базы и из кода сопоставимы.
+func Geohash(c entity.Coordinates, precision int) string {
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+	var sb strings.Builder
+	// биты чередуются начиная с долготы, по 5 на символ
+	even, bit, ch := true, 0, 0
+	for sb.Len() < precision {
+		if even {
+			mid := (lonMin + lonMax) / 2
+			if c.Longitude >= mid {
+				ch |= 1 << (4 - bit)
+				lonMin = mid
+			} else {
+				lonMax = mid
+			}
+		} else {
+			mid := (latMin + latMax) / 2
+			if c.Latitude >= mid {
+				ch |= 1 << (4 - bit)
+				latMin = mid
+			} else {
+				latMax = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// GeohashBounds возвращает юго-западный и северо-восточный углы ячейки.
+func GeohashBounds(hash string) (sw, ne entity.Coordinates, err error) {
+	if hash == "" {
+		return sw, ne, ErrInvalidGeohash
+	}
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+	even := true
+	for _, r := range strings.ToLower(hash) {
+		ch := strings.IndexRune(geohashAlphabet, r)
+		if ch < 0 {
+			return sw, ne, ErrInvalidGeohash
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := ch&(1<<bit) != 0
+			if even {
+				mid := (lonMin + lonMax) / 2
+				if set {
+					lonMin = mid
+				} else {
+					lonMax = mid
+				}
+			} else {
+				mid := (latMin + latMax) / 2
+				if set {
+					latMin = mid
+				} else {
+					latMax = mid
+				}
+			}
+			even = !even
+		}
+	}
+	sw = entity.Coordinates{Latitude: latMin, Longitude: lonMin}
+	ne = entity.Coordinates{Latitude: latMax, Longitude: lonMax}
+	return sw, ne, nil
+}
@@ -0,0 +1,44 @@
+// Package geofence решает, прибыл ли курьер на точку маршрута. Чтобы
+// событие не «дребезжало» на границе круга, используются гистерезис
+// по расстоянию (вход — Radius, выход — ExitRadius) и по времени
+// (курьер должен пробыть внутри не меньше Dwell).
+package geofence
+
+import "time"
+
+type Config struct {
+	Radius     float64
+	ExitRadius float64
+	Dwell      time.Duration
+}
+
+// State — состояние геозоны одной точки маршрута.
+type State struct {
+	EnteredAt *time.Time
+	ArrivedAt *time.Time
+}
+
+// Step применяет очередную координату на расстоянии distance метров от
+// точки. arrived=true ровно один раз — в момент фиксации прибытия.
+func Step(cfg Config, st State, distance float64, now time.Time) (next State, arrived bool) {
+	if st.ArrivedAt != nil {
+		return st, false
+	}
+
+	switch {
+	case distance <= cfg.Radius:
+		if st.EnteredAt == nil {
+			entered := now
+			st.EnteredAt = &entered
+		}
+		if now.Sub(*st.EnteredAt) >= cfg.Dwell {
+			at := now
+			st.ArrivedAt = &at
+			return st, true
+		}
+	case distance > cfg.ExitRadius:
+		st.EnteredAt = nil
+	}
+	// между Radius и ExitRadius состояние не меняется
+	return st, false
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"backend/internal/audit"
+	"backend/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	ctxRequestID    = "request_id"
+	requestIDHeader = "X-Request-ID"
+	// maxAuditBody — сколько ответа держим в памяти ради записи о создании.
+	maxAuditBody = 64 << 10
+)
+
+// RequestID берёт идентификатор запроса из X-Request-ID или выдаёт новый
+// и возвращает его в ответе.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = uuid.NewString()
+		}
+		c.Set(ctxRequestID, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+func CurrentRequestID(c *gin.Context) string {
+	return c.GetString(ctxRequestID)
+}
+
+// AuditRecorder сохраняет запись журнала.
+type AuditRecorder interface {
+	Record(ctx context.Context, e *entity.AuditEvent) error
+}
+
+// Snapshot загружает текущее состояние сущности, чтобы записать в журнал,
+// какие поля изменил запрос.
+type Snapshot func(ctx context.Context, id uuid.UUID) (any, error)
+
+// notAudited — изменяющие маршруты, которые в журнал не пишем: ответы
+// входа и регистрации содержат токены, а координаты и heartbeat курьеров —
+// телеметрия, которая приходит каждые несколько секунд.
+var notAudited = map[string]bool{
+	"/login":                  true,
+	"/register":               true,
+	"/couriers/:id/location":  true,
+	"/couriers/:id/heartbeat": true,
+}
+
+// Audit пишет в журнал каждый изменяющий запрос: кто, что, над какой
+// сущностью и с каким результатом. Тип сущности — первый сегмент пути
+// после /admin или /me, идентификатор — параметр :id. Для типов из
+// snapshots состояние снимается до и после запроса; для созданий без
+// :id изменениями считается тело ответа.
+func Audit(rec AuditRecorder, snapshots map[string]Snapshot, logger *zap.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !mutating(c.Request.Method) || route == "" || notAudited[route] {
+			c.Next()
+			return
+		}
+
+		e := &entity.AuditEvent{
+			Action:     c.Request.Method + " " + route,
+			Path:       c.Request.URL.Path,
+			EntityType: entityType(route),
+			RequestID:  CurrentRequestID(c),
+		}
+		if id, err := uuid.Parse(c.Param("id")); err == nil {
+			e.EntityID = &id
+		}
+		snap := snapshots[e.EntityType]
+
+		var before any
+		if snap != nil && e.EntityID != nil {
+			before, _ = snap(c.Request.Context(), *e.EntityID)
+		}
+		w := &bodyRecorder{ResponseWriter: c.Writer, limit: maxAuditBody}
+		if e.EntityID == nil {
+			c.Writer = w
+		}
+
+		c.Next()
+
+		e.Status = c.Writer.Status()
+		if id, ok := CurrentUserID(c); ok {
+			e.ActorID = &id
+		}
+		if role, ok := CurrentRole(c); ok {
+			e.ActorRole = role
+		}
+
+		// изменение уже сделано: запись в журнал не должна теряться, если
+		// клиент успел отключиться
+		ctx := context.WithoutCancel(c.Request.Context())
+		if e.Status < http.StatusBadRequest {
+			var after any
+			switch {
+			case e.EntityID != nil && snap != nil:
+				after, _ = snap(ctx, *e.EntityID)
+			case e.EntityID == nil && !w.overflow:
+				after = w.body.Bytes()
+				e.EntityID = createdID(w.body.Bytes())
+			}
+			if changes, err := audit.Diff(before, after); err == nil {
+				e.Changes = changes
+			}
+		}
+
+		if err := rec.Record(ctx, e); err != nil {
+			logger.Error("failed to record audit event",
+				zap.String("action", e.Action),
+				zap.String("request_id", e.RequestID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// entityType: "/admin/couriers/:id/suspend" -> "couriers",
+// "/orders/:id/assign" -> "orders", "/me/addresses/:id" -> "addresses".
+func entityType(route string) string {
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	if len(parts) > 1 && (parts[0] == "admin" || parts[0] == "me") {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// createdID достаёт идентификатор созданной сущности из ответа.
+func createdID(body []byte) *uuid.UUID {
+	var resp struct {
+		ID     string `json:"id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+	for _, s := range []string{resp.ID, resp.UserID} {
+		if id, err := uuid.Parse(s); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// bodyRecorder копирует первые limit байт ответа, не мешая его отправке.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	limit    int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	if w.overflow || w.body.Len()+len(b) > w.limit {
+		w.overflow = true
+	} else {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	ctxUserID = "user_id"
+	ctxRole   = "role"
+)
+
+// Auth проверяет заголовок Authorization: Bearer <JWT> и кладёт
+// пользователя и его роль в контекст запроса.
+func Auth(jwtSecret string) gin.HandlerFunc {
+	cfg := &config.Config{JWTSecret: jwtSecret}
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		if !identify(c, cfg, token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Identify запоминает пользователя, если запрос пришёл с валидным
+// токеном, но не отклоняет анонимные запросы. Нужен журналу аудита на
+// маршрутах без обязательной авторизации.
+func Identify(jwtSecret string) gin.HandlerFunc {
+	cfg := &config.Config{JWTSecret: jwtSecret}
+	return func(c *gin.Context) {
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && token != "" {
+			identify(c, cfg, token)
+		}
+		c.Next()
+	}
+}
+
+func identify(c *gin.Context, cfg *config.Config, token string) bool {
+	claims, err := auth.ValidateToken(cfg, token)
+	if err != nil {
+		return false
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return false
+	}
+	c.Set(ctxUserID, userID)
+	c.Set(ctxRole, claims.Role)
+	return true
+}
+
+// RequireRole пропускает только пользователей с одной из ролей.
+// Ставится после Auth.
+func RequireRole(roles ...entity.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := CurrentRole(c)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	}
+}
+
+// PermissionSource отдаёт текущие права администратора.
+type PermissionSource interface {
+	Permissions(ctx context.Context, adminID uuid.UUID) (entity.Permissions, error)
+}
+
+// RequirePermission пропускает администратора, у которого есть право p.
+// Права читаются на каждый запрос, поэтому отзыв действует сразу.
+// Ставится после Auth и RequireRole(entity.RoleAdmin).
+func RequirePermission(src PermissionSource, p entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminID, ok := CurrentUserID(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		perms, err := src.Permissions(c.Request.Context(), adminID)
+		if err != nil && !errors.Is(err, repository.ErrAdminNotFound) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to load permissions"})
+			return
+		}
+		if !perms.Has(p) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing permission " + string(p)})
+			return
+		}
+		c.Next()
+	}
+}
+
+func CurrentUserID(c *gin.Context) (uuid.UUID, bool) {
+	v, ok := c.Get(ctxUserID)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, ok := v.(uuid.UUID)
+	return id, ok
+}
+
+func CurrentRole(c *gin.Context) (entity.Role, bool) {
+	v, ok := c.Get(ctxRole)
+	if !ok {
+		return "", false
+	}
+	role, ok := v.(entity.Role)
+	return role, ok
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader помечает ответ, повторённый из сохранённого.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKey = 255
+	// maxIdempotentBody — ответы длиннее не сохраняются, и ключ
+	// освобождается.
+	maxIdempotentBody = 1 << 20
+	// idempotencyLockTimeout — через сколько незавершённый запрос
+	// считается брошенным (например, при падении сервера) и ключ можно
+	// занять заново. Больше WriteTimeout сервера.
+	idempotencyLockTimeout = time.Minute
+)
+
+// IdempotencyStore хранит ключи идемпотентности и ответы на них.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, rec *entity.IdempotencyRecord, staleBefore time.Time) (*entity.IdempotencyRecord, error)
+	Complete(ctx context.Context, rec *entity.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+// notIdempotent — маршруты, ответы которых не сохраняем: в них токены.
+var notIdempotent = map[string]bool{
+	"/login":    true,
+	"/register": true,
+}
+
+// Idempotency делает изменяющие запросы с заголовком Idempotency-Key
+// повторяемыми: первый запрос выполняется и его ответ хранится ttl,
+// повтор с тем же ключом и тем же запросом получает сохранённый ответ,
+// а с другим методом, адресом или телом — 422. Ключи у каждого
+// пользователя свои, у анонимных запросов — у каждого адреса клиента.
+// Ответы 5xx, 401 и 403 не сохраняются: такой запрос можно повторить с
+// тем же ключом. Тело читается целиком для хеша, поэтому запрос с телом
+// длиннее maxBody получает 413. Должен стоять после Identify.
+func Idempotency(store IdempotencyStore, ttl time.Duration, maxBody int64, logger *zap.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		route := c.FullPath()
+		if key == "" || !mutating(c.Request.Method) || route == "" || notIdempotent[route] {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body is too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now().UTC()
+		rec := &entity.IdempotencyRecord{
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.RequestURI(),
+			RequestHash: requestHash(c.Request.Method, c.Request.URL.RequestURI(), body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		if id, ok := CurrentUserID(c); ok {
+			rec.Scope = id.String()
+		} else {
+			// иначе все анонимные клиенты делили бы одно пространство ключей.
+			// Адрес берётся из соединения: X-Forwarded-For подделывается
+			// клиентом, и по нему можно было бы получить чужой ответ
+			rec.Scope = "anon:" + c.RemoteIP()
+		}
+		l := logger.With(zap.String("idempotency_key", key), zap.String("request_id", CurrentRequestID(c)))
+
+		existing, err := store.Reserve(c.Request.Context(), rec, now.Add(-idempotencyLockTimeout))
+		if err != nil {
+			l.Error("failed to reserve idempotency key", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check Idempotency-Key"})
+			return
+		}
+		switch {
+		case existing == nil:
+		case existing.RequestHash != rec.RequestHash:
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			return
+		case !existing.Completed():
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is still in progress"})
+			return
+		default:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(existing.Status, existing.ContentType, existing.Body)
+			c.Abort()
+			return
+		}
+
+		// ответ сохраняется и ключ освобождается, даже если клиент оборвал
+		// соединение
+		done := context.WithoutCancel(c.Request.Context())
+		// ключ освобождается и при панике в обработчике, иначе он был бы
+		// занят до idempotencyLockTimeout
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := store.Release(done, rec.Scope, rec.Key); err != nil {
+				l.Error("failed to release idempotency key", zap.Error(err))
+			}
+		}()
+
+		w := &bodyRecorder{ResponseWriter: c.Writer, limit: maxIdempotentBody}
+		c.Writer = w
+
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusUnauthorized ||
+			status == http.StatusForbidden || w.overflow {
+			return
+		}
+		rec.Status = status
+		rec.ContentType = w.Header().Get("Content-Type")
+		rec.Body = w.body.Bytes()
+		saved = true
+		if err := store.Complete(done, rec); err != nil {
+			l.Error("failed to save idempotent response", zap.Error(err))
+		}
+	}
+}
+
+func requestHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+uri+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -7,6 +7,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// secretRoutes — маршруты, в пути которых передаётся ключ доступа; в лог
+// вместо пути пишется шаблон маршрута.
+var secretRoutes = map[string]bool{
+	"/track/:token": true,
+}
+
 func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -20,7 +26,9 @@ func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 
-		if rawQuery != "" {
+		if secretRoutes[c.FullPath()] {
+			path = c.FullPath()
+		} else if rawQuery != "" {
 			path = path + "?" + rawQuery
 		}
 
@@ -30,6 +38,7 @@ func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("path", path),
 			zap.String("ip", clientIP),
 			zap.Duration("latency", latency),
+			zap.String("request_id", CurrentRequestID(c)),
 		)
 	}
 }
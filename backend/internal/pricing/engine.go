@@ -0,0 +1,176 @@
+// Package pricing считает стоимость доставки по набору правил
+// entity.PricingRules. Пакет не ходит в базу: правила и входные данные
+// передаются целиком, что позволяет проверять расчёт изолированно.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+)
+
+var (
+	ErrTooHeavy     = errors.New("parcel is heavier than the largest weight tier")
+	ErrInvalidRules = errors.New("invalid pricing rules")
+)
+
+type Input struct {
+	Pickup   entity.Coordinates
+	Dropoff  entity.Coordinates
+	WeightKg float64
+	At       time.Time
+	// Surge — множитель спроса зоны; 0 и 1 — без надбавки.
+	Surge     float64
+	SurgeZone string
+}
+
+type Result struct {
+	DistanceMeters float64
+	Total          int64
+	Breakdown      []entity.PriceLine
+}
+
+// Calculate: (база + км + вес + зоны) × множитель времени суток ×
+// множитель спроса, но не меньше минимальной стоимости.
+func Calculate(rules *entity.PricingRules, in Input) (*Result, error) {
+	dist := geo.Haversine(in.Pickup, in.Dropoff)
+	res := &Result{DistanceMeters: dist}
+
+	subtotal := rules.BaseFare
+	res.add(entity.PriceLine{Kind: "base_fare", Amount: rules.BaseFare})
+
+	distance := int64(math.Round(dist / 1000 * float64(rules.PerKm)))
+	subtotal += distance
+	res.add(entity.PriceLine{Kind: "distance", Label: fmt.Sprintf("%.2f km", dist/1000), Amount: distance})
+
+	if len(rules.WeightTiers) > 0 {
+		tier, ok := weightTier(rules.WeightTiers, in.WeightKg)
+		if !ok {
+			return nil, ErrTooHeavy
+		}
+		if tier.Surcharge != 0 {
+			subtotal += tier.Surcharge
+			res.add(entity.PriceLine{Kind: "weight", Label: fmt.Sprintf("up to %g kg", tier.UpToKg), Amount: tier.Surcharge})
+		}
+	}
+
+	for _, z := range rules.ZoneSurcharges {
+		if geo.Haversine(z.Center, in.Pickup) <= z.RadiusMeters || geo.Haversine(z.Center, in.Dropoff) <= z.RadiusMeters {
+			subtotal += z.Surcharge
+			res.add(entity.PriceLine{Kind: "zone", Label: z.Name, Amount: z.Surcharge})
+		}
+	}
+
+	loc, err := time.LoadLocation(rules.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: timezone %q", ErrInvalidRules, rules.Timezone)
+	}
+	total := float64(subtotal)
+	for _, m := range rules.TimeMultipliers {
+		ok, err := inWindow(m, in.At.In(loc))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			total *= m.Multiplier
+			res.add(entity.PriceLine{Kind: "time_of_day", Label: m.From + "-" + m.To, Factor: m.Multiplier})
+			break
+		}
+	}
+	if in.Surge > 0 && in.Surge != 1 {
+		total *= in.Surge
+		res.add(entity.PriceLine{Kind: "surge", Label: in.SurgeZone, Factor: in.Surge})
+	}
+
+	res.Total = int64(math.Round(total))
+	if res.Total < rules.MinimumFare {
+		res.add(entity.PriceLine{Kind: "minimum_fare", Amount: rules.MinimumFare - res.Total})
+		res.Total = rules.MinimumFare
+	}
+	return res, nil
+}
+
+// Validate проверяет правила перед сохранением.
+func Validate(rules *entity.PricingRules) error {
+	if len(rules.Currency) != 3 {
+		return fmt.Errorf("%w: currency must be a 3-letter code", ErrInvalidRules)
+	}
+	if rules.BaseFare < 0 || rules.PerKm < 0 || rules.MinimumFare < 0 {
+		return fmt.Errorf("%w: fares must not be negative", ErrInvalidRules)
+	}
+	if _, err := time.LoadLocation(rules.Timezone); err != nil {
+		return fmt.Errorf("%w: timezone %q", ErrInvalidRules, rules.Timezone)
+	}
+	for i, t := range rules.WeightTiers {
+		if i > 0 && t.UpToKg <= rules.WeightTiers[i-1].UpToKg {
+			return fmt.Errorf("%w: weight tiers must be sorted by up_to_kg", ErrInvalidRules)
+		}
+	}
+	for _, m := range rules.TimeMultipliers {
+		if m.Multiplier <= 0 {
+			return fmt.Errorf("%w: multiplier must be positive", ErrInvalidRules)
+		}
+		if _, err := inWindow(m, time.Time{}); err != nil {
+			return err
+		}
+	}
+	for _, z := range rules.ZoneSurcharges {
+		if z.RadiusMeters <= 0 {
+			return fmt.Errorf("%w: zone %q radius must be positive", ErrInvalidRules, z.Name)
+		}
+	}
+	if s := rules.Surge; s != nil {
+		if s.Threshold < 0 || s.Step < 0 {
+			return fmt.Errorf("%w: surge threshold and step must not be negative", ErrInvalidRules)
+		}
+		if s.Max < 1 {
+			return fmt.Errorf("%w: surge max_multiplier must be at least 1", ErrInvalidRules)
+		}
+		if s.Smoothing <= 0 || s.Smoothing > 1 {
+			return fmt.Errorf("%w: surge smoothing must be in (0, 1]", ErrInvalidRules)
+		}
+	}
+	return nil
+}
+
+func (r *Result) add(line entity.PriceLine) {
+	r.Breakdown = append(r.Breakdown, line)
+}
+
+func weightTier(tiers []entity.WeightTier, kg float64) (entity.WeightTier, bool) {
+	for _, t := range tiers {
+		if kg <= t.UpToKg {
+			return t, true
+		}
+	}
+	return entity.WeightTier{}, false
+}
+
+func inWindow(m entity.TimeMultiplier, at time.Time) (bool, error) {
+	from, err := minuteOfDay(m.From)
+	if err != nil {
+		return false, err
+	}
+	to, err := minuteOfDay(m.To)
+	if err != nil {
+		return false, err
+	}
+	now := at.Hour()*60 + at.Minute()
+	if from <= to {
+		return now >= from && now < to, nil
+	}
+	// окно через полночь
+	return now >= from || now < to, nil
+}
+
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time %q must be HH:MM", ErrInvalidRules, s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
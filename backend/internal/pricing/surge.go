@@ -0,0 +1,39 @@
+package pricing
+
+import (
+	"math"
+
+	"backend/internal/entity"
+)
+
+// SurgeTarget — множитель спроса для зоны без сглаживания. Без свободных
+// курьеров отношение считается к одному курьеру.
+func SurgeTarget(rules *entity.SurgeRules, openOrders, couriers int) float64 {
+	if rules == nil || !rules.Enabled {
+		return 1
+	}
+	ratio := float64(openOrders) / math.Max(float64(couriers), 1)
+	if ratio <= rules.Threshold {
+		return 1
+	}
+	return math.Min(1+(ratio-rules.Threshold)*rules.Step, rules.Max)
+}
+
+// SmoothSurge сдвигает предыдущий множитель к target на долю Smoothing,
+// чтобы цена не прыгала от каждого пересчёта. Результат округляется до
+// сотых.
+func SmoothSurge(rules *entity.SurgeRules, prev, target float64) float64 {
+	alpha := 1.0
+	if rules != nil && rules.Smoothing > 0 {
+		alpha = rules.Smoothing
+	}
+	if prev <= 0 {
+		prev = 1
+	}
+	m := prev + alpha*(target-prev)
+	// иначе округление не даст множителю дойти до цели
+	if math.Abs(target-m) < 0.01 {
+		m = target
+	}
+	return math.Round(m*100) / 100
+}
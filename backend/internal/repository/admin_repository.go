@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+var ErrAdminNotFound = errors.New("admin not found")
+
+type AdminRepository interface {
+	// Create заводит пользователя с ролью ADMIN и его набор прав.
+	Create(u *entity.User, permissions entity.Permissions) error
+	GetByID(id uuid.UUID) (*entity.Admin, error)
+	List() ([]*entity.Admin, error)
+	SetPermissions(id uuid.UUID, permissions entity.Permissions) error
+}
+
+type adminRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewAdminRepository(db *sql.DB, logger *zap.Logger) AdminRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &adminRepository{db: db, logger: logger}
+}
+
+const adminQuery = `
+	SELECT a.user_id, u.email, a.permissions
+	  FROM admins a
+	  JOIN users u ON u.id = a.user_id
+	`
+
+func scanAdmin(row rowScanner) (*entity.Admin, error) {
+	var (
+		a   entity.Admin
+		raw []byte
+	)
+	if err := row.Scan(&a.UserID, &a.Email, &raw); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &a.Permissions); err != nil {
+		return nil, fmt.Errorf("decode permissions: %w", err)
+	}
+	if a.Permissions == nil {
+		a.Permissions = entity.Permissions{}
+	}
+	return &a, nil
+}
+
+func (r *adminRepository) Create(u *entity.User, permissions entity.Permissions) error {
+	const op = "AdminRepository.Create"
+	l := r.logger.With(zap.String("op", op))
+
+	raw, err := json.Marshal(permissions)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	u.CreatedAt, u.UpdatedAt = now, now
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"INSERT INTO users (id, email, password_hash, role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		u.ID, u.Email, u.PasswordHash, u.Role, u.CreatedAt, u.UpdatedAt,
+	); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		l.Error("failed to insert user", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec("INSERT INTO admins (user_id, permissions) VALUES ($1, $2)", u.ID, raw); err != nil {
+		l.Error("failed to insert admin", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("admin created", zap.String("admin_id", u.ID.String()))
+	return nil
+}
+
+func (r *adminRepository) GetByID(id uuid.UUID) (*entity.Admin, error) {
+	const op = "AdminRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("admin_id", id.String()))
+
+	a, err := scanAdmin(r.db.QueryRow(adminQuery+" WHERE a.user_id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAdminNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return a, nil
+}
+
+func (r *adminRepository) List() ([]*entity.Admin, error) {
+	const op = "AdminRepository.List"
+	l := r.logger.With(zap.String("op", op))
+
+	rows, err := r.db.Query(adminQuery + " ORDER BY u.email")
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.Admin
+	for rows.Next() {
+		a, err := scanAdmin(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, a)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *adminRepository) SetPermissions(id uuid.UUID, permissions entity.Permissions) error {
+	const op = "AdminRepository.SetPermissions"
+	l := r.logger.With(zap.String("op", op), zap.String("admin_id", id.String()))
+
+	raw, err := json.Marshal(permissions)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	res, err := r.db.Exec("UPDATE admins SET permissions = $2 WHERE user_id = $1", id, raw)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAdminNotFound
+	}
+	l.Info("permissions updated", zap.Any("permissions", permissions))
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AuditFilter — условия выборки журнала; пустые поля не фильтруют.
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	EntityType string
+	EntityID   *uuid.UUID
+	Action     string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditRepository только дописывает журнал: изменения и удаления
+// запрещены и на уровне таблицы.
+type AuditRepository interface {
+	Create(e *entity.AuditEvent) error
+	// List возвращает записи от новых к старым.
+	List(f AuditFilter) ([]*entity.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewAuditRepository(db *sql.DB, logger *zap.Logger) AuditRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &auditRepository{db: db, logger: logger}
+}
+
+func (r *auditRepository) Create(e *entity.AuditEvent) error {
+	const op = "AuditRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("request_id", e.RequestID))
+
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	if e.Changes == nil {
+		e.Changes = map[string]entity.Change{}
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	const query = `
+	INSERT INTO audit_events
+	       (id, actor_id, actor_role, action, path, entity_type, entity_id, status, request_id, changes, created_at)
+	VALUES ($1, $2, nullif($3, ''), $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	if _, err := r.db.Exec(query,
+		e.ID, e.ActorID, e.ActorRole, e.Action, e.Path,
+		e.EntityType, e.EntityID, e.Status, e.RequestID,
+		changes, e.CreatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *auditRepository) List(f AuditFilter) ([]*entity.AuditEvent, error) {
+	const op = "AuditRepository.List"
+	l := r.logger.With(zap.String("op", op))
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.ActorID != nil {
+		where = append(where, "actor_id = "+arg(*f.ActorID))
+	}
+	if f.EntityType != "" {
+		where = append(where, "entity_type = "+arg(f.EntityType))
+	}
+	if f.EntityID != nil {
+		where = append(where, "entity_id = "+arg(*f.EntityID))
+	}
+	if f.Action != "" {
+		where = append(where, "action = "+arg(f.Action))
+	}
+	if f.RequestID != "" {
+		where = append(where, "request_id = "+arg(f.RequestID))
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "created_at < "+arg(*f.To))
+	}
+
+	query := `
+	SELECT id, actor_id, coalesce(actor_role, ''), action, path,
+	       entity_type, entity_id, status, request_id, changes, created_at
+	  FROM audit_events`
+	if len(where) > 0 {
+		query += "\n\t WHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += " OFFSET " + arg(f.Offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.AuditEvent
+	for rows.Next() {
+		var (
+			e       entity.AuditEvent
+			changes []byte
+		)
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.Path,
+			&e.EntityType, &e.EntityID, &e.Status, &e.RequestID, &changes, &e.CreatedAt,
+		); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			l.Error("decode changes failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, &e)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+type ClientRepository interface {
+	// GetProfile возвращает профиль клиента; если профиль ещё не
+	// заполнялся, имя, телефон и адрес пустые.
+	GetProfile(userID uuid.UUID) (*entity.Client, error)
+	// SaveProfile создаёт или обновляет запись в clients.
+	SaveProfile(c *entity.Client) error
+
+	CreateAddress(a *entity.ClientAddress) error
+	GetAddress(id uuid.UUID) (*entity.ClientAddress, error)
+	ListAddresses(clientID uuid.UUID) ([]*entity.ClientAddress, error)
+	UpdateAddress(a *entity.ClientAddress) error
+	DeleteAddress(id uuid.UUID) error
+}
+
+type clientRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewClientRepository(db *sql.DB, logger *zap.Logger) ClientRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &clientRepository{db: db, logger: logger}
+}
+
+func (r *clientRepository) GetProfile(userID uuid.UUID) (*entity.Client, error) {
+	const op = "ClientRepository.GetProfile"
+	l := r.logger.With(zap.String("op", op), zap.String("client_id", userID.String()))
+
+	const query = `
+	SELECT u.id, u.email,
+	       coalesce(c.name, ''), coalesce(c.phone, ''), coalesce(c.address, '')
+	  FROM users u
+	  LEFT JOIN clients c ON c.user_id = u.id
+	 WHERE u.id = $1
+	   AND u.role = 'CLIENT'
+	`
+	var c entity.Client
+	if err := r.db.QueryRow(query, userID).Scan(&c.UserID, &c.Email, &c.Name, &c.Phone, &c.Address); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &c, nil
+}
+
+func (r *clientRepository) SaveProfile(c *entity.Client) error {
+	const op = "ClientRepository.SaveProfile"
+	l := r.logger.With(zap.String("op", op), zap.String("client_id", c.UserID.String()))
+
+	const query = `
+	INSERT INTO clients (user_id, name, phone, address)
+	VALUES ($1, $2, nullif($3, ''), nullif($4, ''))
+	ON CONFLICT (user_id) DO UPDATE SET
+	       name    = EXCLUDED.name,
+	       phone   = EXCLUDED.phone,
+	       address = EXCLUDED.address
+	`
+	if _, err := r.db.Exec(query, c.UserID, c.Name, c.Phone, c.Address); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("client profile saved")
+	return nil
+}
+
+const addressColumns = `id, client_id, label, address, ST_Y(location), ST_X(location), notes, created_at, updated_at`
+
+func scanAddress(row rowScanner) (*entity.ClientAddress, error) {
+	var a entity.ClientAddress
+	if err := row.Scan(
+		&a.ID, &a.ClientID, &a.Label, &a.Address,
+		&a.Location.Latitude, &a.Location.Longitude,
+		&a.Notes, &a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *clientRepository) CreateAddress(a *entity.ClientAddress) error {
+	const op = "ClientRepository.CreateAddress"
+	l := r.logger.With(zap.String("op", op), zap.String("client_id", a.ClientID.String()))
+
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	a.CreatedAt, a.UpdatedAt = now, now
+
+	const query = `
+	INSERT INTO client_addresses (id, client_id, label, address, location, notes, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326), $7, $8, $9)
+	`
+	if _, err := r.db.Exec(query,
+		a.ID, a.ClientID, a.Label, a.Address,
+		a.Location.Longitude, a.Location.Latitude,
+		a.Notes, a.CreatedAt, a.UpdatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("address created", zap.String("address_id", a.ID.String()))
+	return nil
+}
+
+func (r *clientRepository) GetAddress(id uuid.UUID) (*entity.ClientAddress, error) {
+	const op = "ClientRepository.GetAddress"
+	l := r.logger.With(zap.String("op", op), zap.String("address_id", id.String()))
+
+	a, err := scanAddress(r.db.QueryRow("SELECT "+addressColumns+" FROM client_addresses WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAddressNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return a, nil
+}
+
+func (r *clientRepository) ListAddresses(clientID uuid.UUID) ([]*entity.ClientAddress, error) {
+	const op = "ClientRepository.ListAddresses"
+	l := r.logger.With(zap.String("op", op), zap.String("client_id", clientID.String()))
+
+	rows, err := r.db.Query("SELECT "+addressColumns+" FROM client_addresses WHERE client_id = $1 ORDER BY label, created_at", clientID)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.ClientAddress
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, a)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *clientRepository) UpdateAddress(a *entity.ClientAddress) error {
+	const op = "ClientRepository.UpdateAddress"
+	l := r.logger.With(zap.String("op", op), zap.String("address_id", a.ID.String()))
+
+	a.UpdatedAt = time.Now().UTC()
+	const query = `
+	UPDATE client_addresses SET
+	       label      = $2,
+	       address    = $3,
+	       location   = ST_SetSRID(ST_MakePoint($4, $5), 4326),
+	       notes      = $6,
+	       updated_at = $7
+	 WHERE id = $1
+	`
+	res, err := r.db.Exec(query,
+		a.ID, a.Label, a.Address,
+		a.Location.Longitude, a.Location.Latitude,
+		a.Notes, a.UpdatedAt,
+	)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+func (r *clientRepository) DeleteAddress(id uuid.UUID) error {
+	const op = "ClientRepository.DeleteAddress"
+	l := r.logger.With(zap.String("op", op), zap.String("address_id", id.String()))
+
+	res, err := r.db.Exec("DELETE FROM client_addresses WHERE id = $1", id)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
@@ -1,110 +1,481 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"backend/internal/entity"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrCourierNotFound = errors.New("courier not found")
+	ErrEmailTaken      = errors.New("email is already registered")
+)
+
 type CourierRepository interface {
-	GetByID(id uuid.UUID) (*entity.Courier, error)
-	Update(c *entity.Courier) error
-	FindNearestAvailable(lat, lon, radius float64) ([]*entity.Courier, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Courier, error)
+	// Update сохраняет профиль, только если его версия не изменилась с
+	// чтения (иначе ErrVersionConflict), и увеличивает c.Version.
+	Update(ctx context.Context, c *entity.Courier) error
+	// SetLocation сохраняет координаты курьера.
+	SetLocation(ctx context.Context, id uuid.UUID, location *entity.Coordinates) error
+	// Create заводит пользователя с ролью COURIER и его профиль одной
+	// транзакцией.
+	Create(ctx context.Context, u *entity.User, c *entity.Courier) error
+	List(ctx context.Context, f CourierListFilter) ([]*entity.Courier, error)
+	SetAccountStatus(ctx context.Context, id uuid.UUID, status entity.AccountStatus, reason string, at time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// FindNearestAvailable ищет свободных курьеров на открытой смене в
+	// радиусе, отфильтрованных по CourierFilter.
+	FindNearestAvailable(ctx context.Context, lat, lon, radius float64, f CourierFilter) ([]*entity.Courier, error)
+	RecordLocation(ctx context.Context, fix *entity.LocationFix) error
+	RecentLocations(ctx context.Context, id uuid.UUID, since time.Time) ([]entity.LocationFix, error)
+	// LastLocation возвращает последний принятый фикс или nil, если
+	// истории ещё нет.
+	LastLocation(ctx context.Context, id uuid.UUID) (*entity.LocationFix, error)
+	// Touch отмечает, что приложение курьера на связи.
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	// MarkStaleOffline переводит в OFFLINE курьеров, молчащих с before,
+	// и возвращает их идентификаторы.
+	MarkStaleOffline(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+}
+
+// CourierListFilter — условия поиска курьеров для админки; пустые поля
+// не фильтруют. Query ищет по имени и email.
+type CourierListFilter struct {
+	Status        entity.CourierStatus
+	AccountStatus entity.AccountStatus
+	ZoneID        *uuid.UUID
+	MinRating     *float64
+	MaxRating     *float64
+	SeenAfter     *time.Time
+	SeenBefore    *time.Time
+	Query         string
+	Limit         int
+	Offset        int
 }
 
+// CourierFilter — дополнительные условия подбора курьера.
+type CourierFilter struct {
+	// ZoneID — только курьеры, для которых это домашняя зона или зона
+	// текущей смены.
+	ZoneID *uuid.UUID
+	// WeightKg и VolumeL — посылка, которая должна поместиться в транспорт
+	// вместе с уже взятыми заказами.
+	WeightKg float64
+	VolumeL  float64
+}
+
+// activeLoadColumns — вес и объём заказов, которые курьер везёт сейчас.
+const activeLoadColumns = `(
+	         SELECT coalesce(sum(o.weight_kg), 0) FROM orders o
+	          WHERE o.courier_id = couriers.user_id
+	            AND o.status IN ('ASSIGNED', 'IN_TRANSIT')
+	       ) AS active_weight_kg,
+	       (
+	         SELECT coalesce(sum(o.length_cm * o.width_cm * o.height_cm / 1000), 0) FROM orders o
+	          WHERE o.courier_id = couriers.user_id
+	            AND o.status IN ('ASSIGNED', 'IN_TRANSIT')
+	       ) AS active_volume_l`
+
+// activeOrdersColumn считает заказы, которые курьер везёт прямо сейчас.
+const activeOrdersColumn = `(
+	         SELECT count(*) FROM orders o
+	          WHERE o.courier_id = couriers.user_id
+	            AND o.status IN ('ASSIGNED', 'IN_TRANSIT')
+	       ) AS active_orders`
+
 type courierRepo struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
-func NewCourierRepository(db *sql.DB, logger *zap.Logger) CourierRepository {
+// NewCourierRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewCourierRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) CourierRepository {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &courierRepo{db: db, logger: logger}
+	return &courierRepo{db: db, timeout: timeout, logger: logger}
 }
 
-func (r *courierRepo) GetByID(id uuid.UUID) (*entity.Courier, error) {
-	const op = "CourierRepository.GetByID"
-	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
-
-	const query = `
-	SELECT user_id, name, status,
+// courierColumns — колонки для scanCourier.
+const courierColumns = `user_id, name, status,
 	       ST_X(location) AS lon,
 	       ST_Y(location) AS lat,
-	       rating
-	  FROM couriers
-	 WHERE user_id = $1
-	`
-	row := r.db.QueryRow(query, id)
+	       rating, capacity,
+	       ` + activeOrdersColumn + `,
+	       array(SELECT zone_id::text FROM courier_zones WHERE courier_id = couriers.user_id) AS zone_ids,
+	       vehicle_type, max_weight_kg, max_volume_l,
+	       ` + activeLoadColumns + `,
+	       last_seen_at,
+	       account_status, coalesce(status_reason, '') AS status_reason, account_status_changed_at,
+	       (SELECT email FROM users WHERE id = couriers.user_id) AS email,
+	       version`
+
+func scanCourier(row rowScanner) (*entity.Courier, error) {
 	var c entity.Courier
-	var lon, lat float64
-	if err := row.Scan(&c.UserID, &c.Name, &c.Status, &lon, &lat, &c.Rating); err != nil {
+	var lon, lat sql.NullFloat64
+	var zoneIDs pq.StringArray
+	if err := row.Scan(
+		&c.UserID, &c.Name, &c.Status, &lon, &lat, &c.Rating, &c.Capacity, &c.ActiveOrders, &zoneIDs,
+		&c.VehicleType, &c.MaxWeightKg, &c.MaxVolumeL, &c.ActiveWeightKg, &c.ActiveVolumeL,
+		&c.LastSeenAt, &c.AccountStatus, &c.StatusReason, &c.AccountStatusChangedAt, &c.Email,
+		&c.Version,
+	); err != nil {
+		return nil, err
+	}
+	if lon.Valid && lat.Valid {
+		c.Location = &entity.Coordinates{Latitude: lat.Float64, Longitude: lon.Float64}
+	}
+	for _, z := range zoneIDs {
+		zoneID, err := uuid.Parse(z)
+		if err != nil {
+			return nil, err
+		}
+		c.ZoneIDs = append(c.ZoneIDs, zoneID)
+	}
+	return &c, nil
+}
+
+func (r *courierRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Courier, error) {
+	const op = "CourierRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	c, err := scanCourier(r.db.QueryRowContext(ctx, "SELECT "+courierColumns+" FROM couriers WHERE user_id = $1", id))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			l.Warn("not found")
-			return nil, fmt.Errorf("%s: courier not found", op)
+			return nil, fmt.Errorf("%s: %w", op, ErrCourierNotFound)
 		}
 		l.Error("scan failed", zap.Error(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	c.Location = &entity.Coordinates{Latitude: lat, Longitude: lon}
-	return &c, nil
+	return c, nil
 }
 
-func (r *courierRepo) Update(c *entity.Courier) error {
-	const op = "CourierRepository.Update"
-	l := r.logger.With(zap.String("op", op), zap.String("courier_id", c.UserID.String()))
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
+// upsertCourier дополняет пустые поля значениями по умолчанию и сохраняет
+// профиль курьера. Существующий профиль обновляется, только если его
+// версия совпадает с c.Version; sql.ErrNoRows — версия устарела.
+// Координаты и статус учётной записи меняются отдельно — SetLocation и
+// SetAccountStatus.
+func upsertCourier(ctx context.Context, db queryRower, c *entity.Courier) error {
 	const query = `
-	INSERT INTO couriers(user_id, name, status, location, rating)
-	VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6)
+	INSERT INTO couriers(user_id, name, status, location, rating, capacity,
+	                     vehicle_type, max_weight_kg, max_volume_l, version)
+	VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6, $7, $8, $9, $10, 1)
 	ON CONFLICT (user_id) DO UPDATE
 	  SET name = EXCLUDED.name,
 	      status = EXCLUDED.status,
-	      location = EXCLUDED.location,
-	      rating = EXCLUDED.rating
+	      rating = EXCLUDED.rating,
+	      capacity = EXCLUDED.capacity,
+	      vehicle_type = EXCLUDED.vehicle_type,
+	      max_weight_kg = EXCLUDED.max_weight_kg,
+	      max_volume_l = EXCLUDED.max_volume_l,
+	      version = couriers.version + 1
+	  WHERE couriers.version = $11
+	RETURNING version
 	`
-	res, err := r.db.Exec(
+	if c.Capacity <= 0 {
+		c.Capacity = 1
+	}
+	if c.VehicleType == "" {
+		c.VehicleType = entity.VehicleBike
+	}
+	if spec, ok := c.VehicleType.Spec(); ok {
+		if c.MaxWeightKg <= 0 {
+			c.MaxWeightKg = spec.MaxWeightKg
+		}
+		if c.MaxVolumeL <= 0 {
+			c.MaxVolumeL = spec.MaxVolumeL
+		}
+	}
+	// курьер без координат хранится с location = NULL
+	var lon, lat *float64
+	if c.Location != nil {
+		lon, lat = &c.Location.Longitude, &c.Location.Latitude
+	}
+	return db.QueryRowContext(ctx,
 		query,
 		c.UserID,
 		c.Name,
 		c.Status,
-		c.Location.Longitude, // X
-		c.Location.Latitude,  // Y
+		lon, // X
+		lat, // Y
 		c.Rating,
+		c.Capacity,
+		c.VehicleType,
+		c.MaxWeightKg,
+		c.MaxVolumeL,
+		c.Version,
+	).Scan(&c.Version)
+}
+
+func (r *courierRepo) Update(ctx context.Context, c *entity.Courier) error {
+	const op = "CourierRepository.Update"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", c.UserID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := upsertCourier(ctx, r.db, c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrVersionConflict)
+		}
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *courierRepo) SetLocation(ctx context.Context, id uuid.UUID, location *entity.Coordinates) error {
+	const op = "CourierRepository.SetLocation"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var lon, lat *float64
+	if location != nil {
+		lon, lat = &location.Longitude, &location.Latitude
+	}
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE couriers SET location = ST_SetSRID(ST_MakePoint($2, $3), 4326), version = version + 1 WHERE user_id = $1",
+		id, lon, lat,
 	)
 	if err != nil {
 		l.Error("exec failed", zap.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	if rows, _ := res.RowsAffected(); rows == 0 {
-		l.Warn("no rows affected")
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourierNotFound)
 	}
 	return nil
 }
 
-func (r *courierRepo) FindNearestAvailable(lat, lon, radius float64) ([]*entity.Courier, error) {
+func (r *courierRepo) Create(ctx context.Context, u *entity.User, c *entity.Courier) error {
+	const op = "CourierRepository.Create"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	u.CreatedAt, u.UpdatedAt = now, now
+	c.UserID = u.ID
+	c.Email = u.Email
+	c.LastSeenAt = now
+	c.AccountStatus = entity.AccountActive
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO users (id, email, password_hash, role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		u.ID, u.Email, u.PasswordHash, u.Role, u.CreatedAt, u.UpdatedAt,
+	); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		l.Error("failed to insert user", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := upsertCourier(ctx, tx, c); err != nil {
+		l.Error("failed to insert courier", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("courier created", zap.String("courier_id", c.UserID.String()))
+	return nil
+}
+
+func (r *courierRepo) List(ctx context.Context, f CourierListFilter) ([]*entity.Courier, error) {
+	const op = "CourierRepository.List"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.Status != "" {
+		where = append(where, "status = "+arg(f.Status))
+	}
+	if f.AccountStatus != "" {
+		where = append(where, "account_status = "+arg(f.AccountStatus))
+	}
+	if f.ZoneID != nil {
+		where = append(where, "EXISTS (SELECT 1 FROM courier_zones cz WHERE cz.courier_id = couriers.user_id AND cz.zone_id = "+arg(*f.ZoneID)+")")
+	}
+	if f.MinRating != nil {
+		where = append(where, "rating >= "+arg(*f.MinRating))
+	}
+	if f.MaxRating != nil {
+		where = append(where, "rating <= "+arg(*f.MaxRating))
+	}
+	if f.SeenAfter != nil {
+		where = append(where, "last_seen_at >= "+arg(*f.SeenAfter))
+	}
+	if f.SeenBefore != nil {
+		where = append(where, "last_seen_at < "+arg(*f.SeenBefore))
+	}
+	if f.Query != "" {
+		p := arg("%" + f.Query + "%")
+		where = append(where, "(name ILIKE "+p+" OR EXISTS (SELECT 1 FROM users u WHERE u.id = couriers.user_id AND u.email ILIKE "+p+"))")
+	}
+
+	query := "SELECT " + courierColumns + " FROM couriers"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY name, user_id"
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += " OFFSET " + arg(f.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.Courier
+	for rows.Next() {
+		c, err := scanCourier(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, c)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *courierRepo) SetAccountStatus(ctx context.Context, id uuid.UUID, status entity.AccountStatus, reason string, at time.Time) error {
+	const op = "CourierRepository.SetAccountStatus"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	UPDATE couriers
+	   SET account_status = $2,
+	       status_reason = nullif($3, ''),
+	       account_status_changed_at = $4,
+	       version = version + 1
+	 WHERE user_id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, id, status, reason, at)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourierNotFound)
+	}
+	l.Info("account status changed", zap.String("status", string(status)))
+	return nil
+}
+
+// Delete удаляет учётную запись курьера; профиль, история координат и смены
+// удаляются каскадно, в заказах курьер обнуляется.
+func (r *courierRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	const op = "CourierRepository.Delete"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1 AND role = $2", id, entity.RoleCourier)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourierNotFound)
+	}
+	l.Info("courier deleted")
+	return nil
+}
+
+func (r *courierRepo) FindNearestAvailable(ctx context.Context, lat, lon, radius float64, f CourierFilter) ([]*entity.Courier, error) {
 	const op = "CourierRepository.FindNearestAvailable"
 	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
 	const query = `
-	SELECT user_id, name, status,
-	       ST_X(location) AS lon,
-	       ST_Y(location) AS lat,
-	       rating
-	  FROM couriers
-	 WHERE status = $1
-	   AND ST_DWithin(
-	         location,
-	         ST_SetSRID(ST_MakePoint($2, $3), 4326),
-	         $4
-	       )
+	SELECT * FROM (
+	  SELECT user_id, name, status,
+	         ST_X(location) AS lon,
+	         ST_Y(location) AS lat,
+	         rating, capacity,
+	         ` + activeOrdersColumn + `,
+	         vehicle_type, max_weight_kg, max_volume_l,
+	         last_seen_at,
+	         ST_Distance(location, ST_SetSRID(ST_MakePoint($2, $3), 4326)) AS dist,
+	         ` + activeLoadColumns + `,
+	         version
+	    FROM couriers
+	   WHERE status = $1
+	     AND account_status = 'ACTIVE'
+	     AND ST_DWithin(
+	           location,
+	           ST_SetSRID(ST_MakePoint($2, $3), 4326),
+	           $4
+	         )
+	     AND EXISTS (
+	           SELECT 1 FROM shifts sh
+	            WHERE sh.courier_id = couriers.user_id
+	              AND sh.started_at IS NOT NULL
+	              AND sh.ended_at IS NULL
+	              AND ($5::uuid IS NULL OR sh.zone_id = $5 OR EXISTS (
+	                    SELECT 1 FROM courier_zones cz
+	                     WHERE cz.courier_id = couriers.user_id
+	                       AND cz.zone_id = $5
+	                  ))
+	         )
+	) c
+	 WHERE c.active_orders < c.capacity
+	   AND c.active_weight_kg + $6 <= c.max_weight_kg
+	   AND c.active_volume_l + $7 <= c.max_volume_l
+	 ORDER BY c.dist
 	`
-	rows, err := r.db.Query(query, entity.CourierStatusAvailable, lon, lat, radius)
+	rows, err := r.db.QueryContext(ctx, query, entity.CourierStatusAvailable, lon, lat, radius, f.ZoneID, f.WeightKg, f.VolumeL)
 	if err != nil {
 		l.Error("query failed", zap.Error(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -114,8 +485,12 @@ func (r *courierRepo) FindNearestAvailable(lat, lon, radius float64) ([]*entity.
 	var ret []*entity.Courier
 	for rows.Next() {
 		var c entity.Courier
-		var lon2, lat2 float64
-		if err := rows.Scan(&c.UserID, &c.Name, &c.Status, &lon2, &lat2, &c.Rating); err != nil {
+		var lon2, lat2, dist float64
+		if err := rows.Scan(
+			&c.UserID, &c.Name, &c.Status, &lon2, &lat2, &c.Rating, &c.Capacity, &c.ActiveOrders,
+			&c.VehicleType, &c.MaxWeightKg, &c.MaxVolumeL,
+			&c.LastSeenAt, &dist, &c.ActiveWeightKg, &c.ActiveVolumeL, &c.Version,
+		); err != nil {
 			l.Error("scan failed", zap.Error(err))
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
@@ -129,3 +504,148 @@ func (r *courierRepo) FindNearestAvailable(lat, lon, radius float64) ([]*entity.
 	l.Info("found", zap.Int("count", len(ret)))
 	return ret, nil
 }
+
+func (r *courierRepo) RecordLocation(ctx context.Context, fix *entity.LocationFix) error {
+	const op = "CourierRepository.RecordLocation"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", fix.CourierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	INSERT INTO courier_locations(courier_id, location, recorded_at)
+	VALUES ($1, ST_SetSRID(ST_MakePoint($2, $3), 4326), $4)
+	`
+	if _, err := r.db.ExecContext(ctx, query, fix.CourierID, fix.Location.Longitude, fix.Location.Latitude, fix.RecordedAt); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RecentLocations возвращает историю координат курьера начиная с since,
+// от старых к новым.
+func (r *courierRepo) RecentLocations(ctx context.Context, id uuid.UUID, since time.Time) ([]entity.LocationFix, error) {
+	const op = "CourierRepository.RecentLocations"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	SELECT courier_id,
+	       ST_X(location) AS lon,
+	       ST_Y(location) AS lat,
+	       recorded_at
+	  FROM courier_locations
+	 WHERE courier_id = $1
+	   AND recorded_at >= $2
+	 ORDER BY recorded_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, id, since)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []entity.LocationFix
+	for rows.Next() {
+		var f entity.LocationFix
+		if err := rows.Scan(&f.CourierID, &f.Location.Longitude, &f.Location.Latitude, &f.RecordedAt); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, f)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *courierRepo) LastLocation(ctx context.Context, id uuid.UUID) (*entity.LocationFix, error) {
+	const op = "CourierRepository.LastLocation"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	SELECT courier_id,
+	       ST_X(location) AS lon,
+	       ST_Y(location) AS lat,
+	       recorded_at
+	  FROM courier_locations
+	 WHERE courier_id = $1
+	 ORDER BY recorded_at DESC
+	 LIMIT 1
+	`
+	var f entity.LocationFix
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&f.CourierID, &f.Location.Longitude, &f.Location.Latitude, &f.RecordedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &f, nil
+}
+
+func (r *courierRepo) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	const op = "CourierRepository.Touch"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// время не откатываем назад, если пинги пришли не по порядку
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE couriers SET last_seen_at = GREATEST(last_seen_at, $2), version = version + 1 WHERE user_id = $1",
+		id, at,
+	)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCourierNotFound)
+	}
+	return nil
+}
+
+func (r *courierRepo) MarkStaleOffline(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	const op = "CourierRepository.MarkStaleOffline"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	UPDATE couriers SET status = $1, version = version + 1
+	 WHERE status <> $1
+	   AND last_seen_at < $2
+	RETURNING user_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, entity.CourierStatusOffline, before)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(ids) > 0 {
+		l.Info("stale couriers marked offline", zap.Int("count", len(ids)))
+	}
+	return ids, nil
+}
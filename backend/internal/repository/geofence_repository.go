@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StopProximity — незавершённая точка активного заказа курьера и
+// расстояние до неё от последней координаты.
+type StopProximity struct {
+	OrderID   uuid.UUID
+	StopID    uuid.UUID
+	Kind      entity.StopKind
+	Distance  float64
+	EnteredAt *time.Time
+}
+
+type GeofenceRepository interface {
+	// CandidateStops возвращает точки, для которых есть смысл проверять
+	// геозону: внутри exitRadius или с уже начатым входом.
+	CandidateStops(ctx context.Context, courierID uuid.UUID, loc entity.Coordinates, exitRadius float64) ([]StopProximity, error)
+	SaveStopState(ctx context.Context, stopID uuid.UUID, enteredAt, arrivedAt *time.Time) error
+}
+
+type geofenceRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewGeofenceRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewGeofenceRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) GeofenceRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &geofenceRepository{db: db, timeout: timeout, logger: logger}
+}
+
+func (r *geofenceRepository) CandidateStops(ctx context.Context, courierID uuid.UUID, loc entity.Coordinates, exitRadius float64) ([]StopProximity, error) {
+	const op = "GeofenceRepository.CandidateStops"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+		SELECT s.order_id, s.id, s.kind, s.entered_at,
+		       ST_Distance(s.location::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography)
+		  FROM order_stops s
+		  JOIN orders o ON o.id = s.order_id
+		 WHERE o.courier_id = $1
+		   AND o.status IN ('ASSIGNED', 'IN_TRANSIT')
+		   AND s.completed_at IS NULL
+		   AND s.arrived_at IS NULL
+		   AND (s.entered_at IS NOT NULL
+		        OR ST_DWithin(s.location::geography, ST_SetSRID(ST_MakePoint($2, $3), 4326)::geography, $4))
+	`
+	rows, err := r.db.QueryContext(ctx, query, courierID, loc.Longitude, loc.Latitude, exitRadius)
+	if err != nil {
+		l.Error("failed to query stops", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []StopProximity
+	for rows.Next() {
+		var p StopProximity
+		if err := rows.Scan(&p.OrderID, &p.StopID, &p.Kind, &p.EnteredAt, &p.Distance); err != nil {
+			l.Error("failed to scan stop", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, p)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *geofenceRepository) SaveStopState(ctx context.Context, stopID uuid.UUID, enteredAt, arrivedAt *time.Time) error {
+	const op = "GeofenceRepository.SaveStopState"
+	l := r.logger.With(zap.String("op", op), zap.String("stop_id", stopID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// прибытие видно в заказе, поэтому растёт и версия заказа
+	res, err := r.db.ExecContext(ctx, `
+	WITH s AS (
+	    UPDATE order_stops SET entered_at = $2, arrived_at = $3
+	     WHERE id = $1
+	    RETURNING order_id
+	)
+	UPDATE orders SET version = version + 1 WHERE id IN (SELECT order_id FROM s)
+	`, stopID, enteredAt, arrivedAt)
+	if err != nil {
+		l.Error("failed to update stop", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrStopNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+type HeatmapRepository interface {
+	// Cells возвращает ячейки, где есть спрос или предложение.
+	Cells(q entity.HeatmapQuery) ([]*entity.HeatmapCell, error)
+}
+
+type heatmapRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewHeatmapRepository(db *sql.DB, logger *zap.Logger) HeatmapRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &heatmapRepository{db: db, logger: logger}
+}
+
+func (r *heatmapRepository) Cells(q entity.HeatmapQuery) ([]*entity.HeatmapCell, error) {
+	const op = "HeatmapRepository.Cells"
+	l := r.logger.With(zap.String("op", op), zap.Int("precision", q.Precision))
+
+	args := []any{q.From, q.To, q.Precision}
+	var inZone, courierInZone string
+	if q.ZoneID != nil {
+		args = append(args, *q.ZoneID)
+		inZone = "\n\t\t   AND ST_Intersects(o.delivery_coords, (SELECT boundary FROM zones WHERE id = $4))"
+		courierInZone = "\n\t\t   AND ST_Intersects(c.location, (SELECT boundary FROM zones WHERE id = $4))"
+	}
+
+	// предложение — снимок свободных курьеров, период к нему не относится
+	query := `
+	WITH demand AS (
+		SELECT ST_GeoHash(o.delivery_coords, $3) AS cell, count(*) AS n
+		  FROM orders o
+		 WHERE o.created_at >= $1 AND o.created_at < $2` + inZone + `
+		 GROUP BY 1
+	), supply AS (
+		SELECT ST_GeoHash(c.location, $3) AS cell, count(*) AS n
+		  FROM couriers c
+		 WHERE c.status = 'AVAILABLE' AND c.account_status = 'ACTIVE'
+		   AND c.location IS NOT NULL` + courierInZone + `
+		 GROUP BY 1
+	)
+	SELECT coalesce(d.cell, s.cell), coalesce(d.n, 0), coalesce(s.n, 0)
+	  FROM demand d
+	  FULL JOIN supply s ON s.cell = d.cell
+	 ORDER BY 1`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.HeatmapCell
+	for rows.Next() {
+		var c entity.HeatmapCell
+		if err := rows.Scan(&c.Geohash, &c.Demand, &c.Supply); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, &c)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
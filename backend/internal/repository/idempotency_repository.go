@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+type IdempotencyRepository interface {
+	// Reserve занимает ключ под запрос. Если ключ уже занят живой записью,
+	// возвращает её; истёкшие записи и брошенные до staleBefore
+	// незавершённые запросы перезанимаются.
+	Reserve(ctx context.Context, rec *entity.IdempotencyRecord, staleBefore time.Time) (*entity.IdempotencyRecord, error)
+	// Complete сохраняет ответ на запрос.
+	Complete(ctx context.Context, rec *entity.IdempotencyRecord) error
+	// Release освобождает ключ, чтобы запрос можно было повторить.
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewIdempotencyRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewIdempotencyRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) IdempotencyRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &idempotencyRepository{db: db, timeout: timeout, logger: logger}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, rec *entity.IdempotencyRecord, staleBefore time.Time) (*entity.IdempotencyRecord, error) {
+	const op = "IdempotencyRepository.Reserve"
+	l := r.logger.With(zap.String("op", op), zap.String("key", rec.Key))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var reserved bool
+	err := r.db.QueryRowContext(ctx, `
+	INSERT INTO idempotency_keys (scope, key, method, path, request_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (scope, key) DO UPDATE
+	   SET method        = EXCLUDED.method,
+	       path          = EXCLUDED.path,
+	       request_hash  = EXCLUDED.request_hash,
+	       status        = NULL,
+	       content_type  = NULL,
+	       response_body = NULL,
+	       created_at    = EXCLUDED.created_at,
+	       expires_at    = EXCLUDED.expires_at
+	 WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	    OR (idempotency_keys.status IS NULL AND idempotency_keys.created_at < $8)
+	RETURNING true
+	`, rec.Scope, rec.Key, rec.Method, rec.Path, rec.RequestHash, rec.CreatedAt, rec.ExpiresAt, staleBefore).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		l.Error("insert failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// ключ занят: отдаём запись, по которой решается, повторить ответ
+	// или отказать
+	existing := entity.IdempotencyRecord{Scope: rec.Scope, Key: rec.Key}
+	var (
+		status      sql.NullInt64
+		contentType sql.NullString
+	)
+	err = r.db.QueryRowContext(ctx, `
+	SELECT method, path, request_hash, status, content_type, response_body, created_at, expires_at
+	  FROM idempotency_keys
+	 WHERE scope = $1 AND key = $2
+	`, rec.Scope, rec.Key).Scan(
+		&existing.Method, &existing.Path, &existing.RequestHash, &status, &contentType,
+		&existing.Body, &existing.CreatedAt, &existing.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		// запись успели освободить между запросами — считаем, что запрос
+		// ещё выполняется, клиент повторит
+		existing.RequestHash = rec.RequestHash
+		return &existing, nil
+	}
+	if err != nil {
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	existing.Status = int(status.Int64)
+	existing.ContentType = contentType.String
+	return &existing, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, rec *entity.IdempotencyRecord) error {
+	const op = "IdempotencyRepository.Complete"
+	l := r.logger.With(zap.String("op", op), zap.String("key", rec.Key))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `
+	UPDATE idempotency_keys
+	   SET status = $3, content_type = $4, response_body = $5
+	 WHERE scope = $1 AND key = $2 AND request_hash = $6
+	`, rec.Scope, rec.Key, rec.Status, rec.ContentType, rec.Body, rec.RequestHash); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	const op = "IdempotencyRepository.Release"
+	l := r.logger.With(zap.String("op", op), zap.String("key", key))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status IS NULL", scope, key,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	const op = "IdempotencyRepository.DeleteExpired"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", now)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
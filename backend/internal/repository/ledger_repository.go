@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLedgerDuplicate — заработок или чаевые по заказу уже начислены.
+	ErrLedgerDuplicate     = errors.New("ledger transaction already posted")
+	ErrPayoutBatchNotFound = errors.New("payout batch not found")
+	ErrPayoutCutoff        = errors.New("payout cutoff is before the previous batch")
+	ErrPayoutEmpty         = errors.New("no courier balances to pay out")
+)
+
+// LedgerRepository хранит проводки по счетам курьеров. Проводки только
+// добавляются: ошибку исправляют новой корректировкой.
+type LedgerRepository interface {
+	// Post записывает проводку со всеми строками одной транзакцией.
+	Post(ctx context.Context, t *entity.LedgerTransaction) error
+	// ListByCourier возвращает проводки курьера за [from, to) по времени.
+	ListByCourier(ctx context.Context, courierID uuid.UUID, from, to time.Time) ([]*entity.LedgerTransaction, error)
+	// Balance — сколько мы должны курьеру в валюте currency.
+	Balance(ctx context.Context, courierID uuid.UUID, currency string) (int64, error)
+	// CreatePayoutBatch закрывает положительные балансы курьеров на
+	// b.Cutoff проводками PAYOUT и сохраняет реестр вместе со строками.
+	CreatePayoutBatch(ctx context.Context, b *entity.PayoutBatch) error
+	ListPayoutBatches(ctx context.Context, limit, offset int) ([]*entity.PayoutBatch, error)
+	GetPayoutBatch(ctx context.Context, id uuid.UUID) (*entity.PayoutBatch, error)
+	// UnpaidDeliveries возвращает заказы, доставленные курьером не раньше
+	// since, по которым нет проводки DELIVERY.
+	UnpaidDeliveries(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error)
+}
+
+type ledgerRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewLedgerRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewLedgerRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) LedgerRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ledgerRepository{db: db, timeout: timeout, logger: logger}
+}
+
+func insertLedger(ctx context.Context, tx *sql.Tx, t *entity.LedgerTransaction) error {
+	if _, err := tx.ExecContext(ctx, `
+	INSERT INTO ledger_transactions (id, kind, courier_id, order_id, payout_id, currency, description, created_by, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, nullif($7, ''), $8, $9)
+	`, t.ID, t.Kind, t.CourierID, t.OrderID, t.PayoutID, t.Currency, t.Description, t.CreatedBy, t.CreatedAt); err != nil {
+		return err
+	}
+	for _, e := range t.Entries {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO ledger_entries (transaction_id, account, component, amount) VALUES ($1, $2, $3, $4)",
+			t.ID, e.Account, e.Component, e.Amount,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ledgerRepository) Post(ctx context.Context, t *entity.LedgerTransaction) error {
+	const op = "LedgerRepository.Post"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", t.CourierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if !t.Balanced() {
+		return fmt.Errorf("%s: transaction is not balanced", op)
+	}
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertLedger(ctx, tx, t); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrLedgerDuplicate
+		}
+		l.Error("failed to insert transaction", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("ledger transaction posted",
+		zap.String("transaction_id", t.ID.String()),
+		zap.String("kind", string(t.Kind)),
+	)
+	return nil
+}
+
+func (r *ledgerRepository) ListByCourier(ctx context.Context, courierID uuid.UUID, from, to time.Time) ([]*entity.LedgerTransaction, error) {
+	const op = "LedgerRepository.ListByCourier"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT t.id, t.kind, t.courier_id, t.order_id, t.payout_id, t.currency,
+	       coalesce(t.description, ''), t.created_by, t.created_at,
+	       e.account, e.component, e.amount
+	  FROM ledger_transactions t
+	  JOIN ledger_entries e ON e.transaction_id = t.id
+	 WHERE t.courier_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+	 ORDER BY t.created_at, t.id, e.id
+	`, courierID, from, to)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.LedgerTransaction
+	var cur *entity.LedgerTransaction
+	for rows.Next() {
+		var (
+			t entity.LedgerTransaction
+			e entity.LedgerEntry
+		)
+		if err := rows.Scan(
+			&t.ID, &t.Kind, &t.CourierID, &t.OrderID, &t.PayoutID, &t.Currency,
+			&t.Description, &t.CreatedBy, &t.CreatedAt,
+			&e.Account, &e.Component, &e.Amount,
+		); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if cur == nil || cur.ID != t.ID {
+			cur = &t
+			ret = append(ret, cur)
+		}
+		cur.Entries = append(cur.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *ledgerRepository) Balance(ctx context.Context, courierID uuid.UUID, currency string) (int64, error) {
+	const op = "LedgerRepository.Balance"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// COURIER_PAYABLE — пассивный счёт: долг перед курьером лежит в кредите
+	var balance int64
+	err := r.db.QueryRowContext(ctx, `
+	SELECT coalesce(-sum(e.amount), 0)
+	  FROM ledger_entries e
+	  JOIN ledger_transactions t ON t.id = e.transaction_id
+	 WHERE t.courier_id = $1 AND t.currency = $2 AND e.account = 'COURIER_PAYABLE'
+	`, courierID, currency).Scan(&balance)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return balance, nil
+}
+
+func (r *ledgerRepository) CreatePayoutBatch(ctx context.Context, b *entity.PayoutBatch) error {
+	const op = "LedgerRepository.CreatePayoutBatch"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// два реестра одновременно выплатили бы одни и те же деньги дважды
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE payout_batches IN EXCLUSIVE MODE"); err != nil {
+		l.Error("failed to lock batches", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var last sql.NullTime
+	if err := tx.QueryRowContext(ctx,
+		"SELECT max(cutoff) FROM payout_batches WHERE currency = $1", b.Currency,
+	).Scan(&last); err != nil {
+		l.Error("failed to load last cutoff", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if last.Valid && b.Cutoff.Before(last.Time) {
+		return ErrPayoutCutoff
+	}
+
+	// прошлые выплаты учитываются целиком, даже если проведены после
+	// cutoff: они закрывают начисления до предыдущих cutoff
+	rows, err := tx.QueryContext(ctx, `
+	SELECT t.courier_id, coalesce(c.name, ''), -sum(e.amount)
+	  FROM ledger_entries e
+	  JOIN ledger_transactions t ON t.id = e.transaction_id
+	  LEFT JOIN couriers c ON c.user_id = t.courier_id
+	 WHERE e.account = 'COURIER_PAYABLE' AND t.currency = $1
+	   AND (t.created_at < $2 OR t.kind = 'PAYOUT')
+	 GROUP BY t.courier_id, c.name
+	HAVING -sum(e.amount) > 0
+	 ORDER BY c.name, t.courier_id
+	`, b.Currency, b.Cutoff)
+	if err != nil {
+		l.Error("failed to query balances", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	b.Lines, b.Total = nil, 0
+	for rows.Next() {
+		var line entity.PayoutLine
+		if err := rows.Scan(&line.CourierID, &line.CourierName, &line.Amount); err != nil {
+			rows.Close()
+			l.Error("scan failed", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		b.Lines = append(b.Lines, line)
+		b.Total += line.Amount
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(b.Lines) == 0 {
+		return ErrPayoutEmpty
+	}
+	b.Couriers = len(b.Lines)
+
+	if _, err := tx.ExecContext(ctx, `
+	INSERT INTO payout_batches (id, cutoff, currency, total, couriers, created_by, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, b.ID, b.Cutoff, b.Currency, b.Total, b.Couriers, b.CreatedBy, b.CreatedAt); err != nil {
+		l.Error("failed to insert batch", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, line := range b.Lines {
+		t := &entity.LedgerTransaction{
+			ID:        uuid.New(),
+			Kind:      entity.LedgerPayout,
+			CourierID: line.CourierID,
+			PayoutID:  &b.ID,
+			Currency:  b.Currency,
+			CreatedBy: b.CreatedBy,
+			CreatedAt: b.CreatedAt,
+		}
+		t.Credit(entity.AccountPayoutsClearing, entity.EarningPayout, -line.Amount)
+		if err := insertLedger(ctx, tx, t); err != nil {
+			l.Error("failed to insert payout", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("payout batch created",
+		zap.String("batch_id", b.ID.String()),
+		zap.Int("couriers", b.Couriers),
+		zap.Int64("total", b.Total),
+	)
+	return nil
+}
+
+const payoutBatchQuery = `
+	SELECT id, cutoff, currency, total, couriers, created_by, created_at
+	  FROM payout_batches
+	`
+
+func scanPayoutBatch(row rowScanner) (*entity.PayoutBatch, error) {
+	var b entity.PayoutBatch
+	if err := row.Scan(&b.ID, &b.Cutoff, &b.Currency, &b.Total, &b.Couriers, &b.CreatedBy, &b.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *ledgerRepository) ListPayoutBatches(ctx context.Context, limit, offset int) ([]*entity.PayoutBatch, error) {
+	const op = "LedgerRepository.ListPayoutBatches"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, payoutBatchQuery+" ORDER BY created_at DESC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.PayoutBatch
+	for rows.Next() {
+		b, err := scanPayoutBatch(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, b)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *ledgerRepository) GetPayoutBatch(ctx context.Context, id uuid.UUID) (*entity.PayoutBatch, error) {
+	const op = "LedgerRepository.GetPayoutBatch"
+	l := r.logger.With(zap.String("op", op), zap.String("batch_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	b, err := scanPayoutBatch(r.db.QueryRowContext(ctx, payoutBatchQuery+" WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPayoutBatchNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT t.courier_id, coalesce(c.name, ''), sum(e.amount)
+	  FROM ledger_transactions t
+	  JOIN ledger_entries e ON e.transaction_id = t.id AND e.account = 'COURIER_PAYABLE'
+	  LEFT JOIN couriers c ON c.user_id = t.courier_id
+	 WHERE t.payout_id = $1
+	 GROUP BY t.courier_id, c.name
+	 ORDER BY c.name, t.courier_id
+	`, id)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line entity.PayoutLine
+		if err := rows.Scan(&line.CourierID, &line.CourierName, &line.Amount); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		b.Lines = append(b.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return b, nil
+}
+
+func (r *ledgerRepository) UnpaidDeliveries(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error) {
+	const op = "LedgerRepository.UnpaidDeliveries"
+	l := r.logger.With(zap.String("op", op), zap.Time("since", since))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT o.id
+	  FROM orders o
+	 WHERE o.status = 'DELIVERED'
+	   AND o.courier_id IS NOT NULL
+	   AND EXISTS (SELECT 1 FROM order_status_logs sl
+	                WHERE sl.order_id = o.id AND sl.status = 'DELIVERED' AND sl.created_at >= $1)
+	   AND NOT EXISTS (SELECT 1 FROM ledger_transactions t
+	                    WHERE t.order_id = o.id AND t.kind = 'DELIVERY')
+	 ORDER BY o.updated_at
+	 LIMIT $2
+	`, since, limit)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, id)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
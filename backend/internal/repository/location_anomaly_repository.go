@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// AnomalyFilter — условия выборки аномалий; пустые поля не фильтруют.
+type AnomalyFilter struct {
+	CourierID *uuid.UUID
+	Since     *time.Time
+	Limit     int
+}
+
+type LocationAnomalyRepository interface {
+	Create(a *entity.LocationAnomaly) error
+	// List возвращает аномалии от новых к старым.
+	List(f AnomalyFilter) ([]*entity.LocationAnomaly, error)
+}
+
+type locationAnomalyRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewLocationAnomalyRepository(db *sql.DB, logger *zap.Logger) LocationAnomalyRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &locationAnomalyRepository{db: db, logger: logger}
+}
+
+func (r *locationAnomalyRepository) Create(a *entity.LocationAnomaly) error {
+	const op = "LocationAnomalyRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", a.CourierID.String()))
+
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	a.CreatedAt = time.Now().UTC()
+
+	var prevLon, prevLat *float64
+	if a.PrevLocation != nil {
+		prevLon, prevLat = &a.PrevLocation.Longitude, &a.PrevLocation.Latitude
+	}
+	kinds := make([]string, len(a.Kinds))
+	for i, k := range a.Kinds {
+		kinds[i] = string(k)
+	}
+
+	const query = `
+	INSERT INTO location_anomalies
+	       (id, courier_id, kinds, location, prev_location, speed_mps, recorded_at, rejected, created_at)
+	VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326),
+	        ST_SetSRID(ST_MakePoint($6, $7), 4326), $8, $9, $10, $11)
+	`
+	if _, err := r.db.Exec(query,
+		a.ID, a.CourierID, pq.Array(kinds),
+		a.Location.Longitude, a.Location.Latitude,
+		prevLon, prevLat,
+		a.SpeedMps, a.RecordedAt, a.Rejected, a.CreatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Warn("location anomaly recorded", zap.Strings("kinds", kinds), zap.Bool("rejected", a.Rejected))
+	return nil
+}
+
+func (r *locationAnomalyRepository) List(f AnomalyFilter) ([]*entity.LocationAnomaly, error) {
+	const op = "LocationAnomalyRepository.List"
+	l := r.logger.With(zap.String("op", op))
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	if f.CourierID != nil {
+		args = append(args, *f.CourierID)
+		where = append(where, fmt.Sprintf("courier_id = $%d", len(args)))
+	}
+	if f.Since != nil {
+		args = append(args, *f.Since)
+		where = append(where, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	query := `
+	SELECT id, courier_id, kinds,
+	       ST_Y(location), ST_X(location),
+	       ST_Y(prev_location), ST_X(prev_location),
+	       speed_mps, recorded_at, rejected, created_at
+	  FROM location_anomalies`
+	if len(where) > 0 {
+		query += "\n\t WHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.LocationAnomaly
+	for rows.Next() {
+		var (
+			a                entity.LocationAnomaly
+			kinds            pq.StringArray
+			prevLat, prevLon sql.NullFloat64
+			speed            sql.NullFloat64
+		)
+		if err := rows.Scan(&a.ID, &a.CourierID, &kinds,
+			&a.Location.Latitude, &a.Location.Longitude,
+			&prevLat, &prevLon,
+			&speed, &a.RecordedAt, &a.Rejected, &a.CreatedAt,
+		); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, k := range kinds {
+			a.Kinds = append(a.Kinds, entity.AnomalyKind(k))
+		}
+		if prevLat.Valid && prevLon.Valid {
+			a.PrevLocation = &entity.Coordinates{Latitude: prevLat.Float64, Longitude: prevLon.Float64}
+		}
+		if speed.Valid {
+			a.SpeedMps = &speed.Float64
+		}
+		ret = append(ret, &a)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
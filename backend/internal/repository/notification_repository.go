@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository interface {
+	Create(n *entity.Notification) error
+	// ListByUser возвращает уведомления пользователя от новых к старым.
+	ListByUser(userID uuid.UUID, unreadOnly bool, limit int) ([]*entity.Notification, error)
+	MarkRead(userID, id uuid.UUID) error
+}
+
+type notificationRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewNotificationRepository(db *sql.DB, logger *zap.Logger) NotificationRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &notificationRepository{db: db, logger: logger}
+}
+
+func (r *notificationRepository) Create(n *entity.Notification) error {
+	const op = "NotificationRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("user_id", n.UserID.String()))
+
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	n.CreatedAt = time.Now().UTC()
+	if _, err := r.db.Exec(
+		"INSERT INTO notifications (id, user_id, message, is_read, created_at) VALUES ($1, $2, $3, FALSE, $4)",
+		n.ID, n.UserID, n.Message, n.CreatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) ListByUser(userID uuid.UUID, unreadOnly bool, limit int) ([]*entity.Notification, error) {
+	const op = "NotificationRepository.ListByUser"
+	l := r.logger.With(zap.String("op", op), zap.String("user_id", userID.String()))
+
+	query := `
+	SELECT id, user_id, message, coalesce(is_read, FALSE), created_at
+	  FROM notifications
+	 WHERE user_id = $1`
+	if unreadOnly {
+		query += " AND NOT is_read"
+	}
+	query += "\n\t ORDER BY created_at DESC LIMIT $2"
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.Notification
+	for rows.Next() {
+		var n entity.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Message, &n.IsRead, &n.CreatedAt); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, &n)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *notificationRepository) MarkRead(userID, id uuid.UUID) error {
+	const op = "NotificationRepository.MarkRead"
+	l := r.logger.With(zap.String("op", op), zap.String("notification_id", id.String()))
+
+	res, err := r.db.Exec("UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,39 +12,135 @@ import (
 	"backend/internal/entity"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 var ErrOrderNotFound = errors.New("order not found")
 var ErrClientNotFound = errors.New("client not found")
 
+// ErrVersionConflict — запись изменили после того, как её прочитали.
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
 type OrderRepository interface {
-	Create(order *entity.Order) error
-	GetByID(id uuid.UUID) (*entity.Order, error)
-	GetAll() ([]*entity.Order, error)
-	Update(order *entity.Order) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, order *entity.Order) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error)
+	GetAll(ctx context.Context) ([]*entity.Order, error)
+	// Update сохраняет заказ, только если его версия не изменилась с
+	// чтения (иначе ErrVersionConflict), и увеличивает order.Version.
+	Update(ctx context.Context, order *entity.Order) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetActiveByCourier(ctx context.Context, courierID uuid.UUID) ([]*entity.Order, error)
+	CompleteStop(ctx context.Context, orderID, stopID uuid.UUID, at time.Time) error
+	SetRoutePositions(ctx context.Context, stopIDs []uuid.UUID) error
+	SetETA(ctx context.Context, orderID uuid.UUID, pickupAt, deliveryAt *time.Time) error
+	AddEvent(ctx context.Context, e *entity.OrderEvent) error
+	// GetTimeline возвращает смены статуса и события заказа по времени.
+	GetTimeline(ctx context.Context, orderID uuid.UUID) ([]entity.OrderEvent, error)
+	// ListDueForDispatch — заказы с окном доставки, которые пора назначить:
+	// без курьера и с наступившим release_at.
+	ListDueForDispatch(ctx context.Context, now time.Time) ([]*entity.Order, error)
+	// ListWindowAtRisk — ещё не помеченные заказы, которые не успевают
+	// в окно: ETA позже конца окна или курьера нет, а до конца окна
+	// осталось меньше lead.
+	ListWindowAtRisk(ctx context.Context, now time.Time, lead time.Duration) ([]*entity.Order, error)
+	// MarkWindowAtRisk помечает заказ и пишет событие в хронологию;
+	// false — заказ уже был помечен.
+	MarkWindowAtRisk(ctx context.Context, orderID uuid.UUID, at time.Time) (bool, error)
+}
+
+var ErrStopNotFound = errors.New("stop not found")
+
+// ErrStopCompleted — точка уже отмечена выполненной.
+var ErrStopCompleted = errors.New("stop is already completed")
+
+// orderColumns — общий список колонок для scanOrder. Координаты отдаём
+// в том же формате "lat,lon", в котором их принимает API.
+const orderColumns = `
+			id, client_id, courier_id, status,
+			delivery_address,
+			concat(ST_Y(delivery_coords), ',', ST_X(delivery_coords)) AS delivery_coords,
+			estimated_pickup_at, estimated_delivery_at,
+			quote_id, price, coalesce(currency, '') AS currency, surge_multiplier,
+			zone_id, out_of_zone,
+			weight_kg, length_cm, width_cm, height_cm,
+			address_id, delivery_notes,
+			window_start, window_end, release_at, window_at_risk,
+			version, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// withTimeout ограничивает один вызов репозитория: запрос отменяется по
+// истечении timeout или вместе с ctx — при обрыве соединения клиента или
+// остановке сервера. Нулевой timeout не ограничивает.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func scanOrder(row rowScanner) (*entity.Order, error) {
+	var order entity.Order
+	if err := row.Scan(
+		&order.ID,
+		&order.ClientID,
+		&order.CourierID,
+		&order.Status,
+		&order.DeliveryAddress,
+		&order.DeliveryCoords,
+		&order.EstimatedPickupAt,
+		&order.EstimatedDeliveryAt,
+		&order.QuoteID,
+		&order.Price,
+		&order.Currency,
+		&order.SurgeMultiplier,
+		&order.ZoneID,
+		&order.OutOfZone,
+		&order.WeightKg,
+		&order.LengthCm,
+		&order.WidthCm,
+		&order.HeightCm,
+		&order.AddressID,
+		&order.DeliveryNotes,
+		&order.WindowStart,
+		&order.WindowEnd,
+		&order.ReleaseAt,
+		&order.WindowAtRisk,
+		&order.Version,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &order, nil
 }
 
 type orderRepository struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
-func NewOrderRepository(db *sql.DB, logger *zap.Logger) OrderRepository {
+// NewOrderRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewOrderRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) OrderRepository {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
-	return &orderRepository{db: db, logger: logger}
+	return &orderRepository{db: db, timeout: timeout, logger: logger}
 }
 
-func (r *orderRepository) Create(order *entity.Order) error {
+func (r *orderRepository) Create(ctx context.Context, order *entity.Order) error {
 	const op = "OrderRepository.Create"
 	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
 	// проверяем, что клиент есть
 	var exists int
-	if err := r.db.QueryRow("SELECT 1 FROM users WHERE id = $1", order.ClientID).Scan(&exists); err != nil {
+	if err := r.db.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = $1", order.ClientID).Scan(&exists); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			l.Warn("client not found", zap.String("client_id", order.ClientID.String()))
 			return fmt.Errorf("%s: %w", op, ErrClientNotFound)
@@ -60,6 +157,7 @@ func (r *orderRepository) Create(order *entity.Order) error {
 	now := time.Now().UTC()
 	order.CreatedAt = now
 	order.UpdatedAt = now
+	order.Version = 1
 
 	// парсим "lat,lon"
 	parts := strings.Split(order.DeliveryCoords, ",")
@@ -75,27 +173,78 @@ func (r *orderRepository) Create(order *entity.Order) error {
 		return fmt.Errorf("%s: parse lon: %w", op, err)
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// котировку гасим в той же транзакции, чтобы её нельзя было
+	// использовать для двух заказов
+	if order.QuoteID != nil {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE quotes SET used_at = $2 WHERE id = $1 AND used_at IS NULL AND expires_at > $2",
+			*order.QuoteID, now,
+		)
+		if err != nil {
+			l.Error("failed to use quote", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("%s: %w", op, ErrQuoteUnavailable)
+		}
+	}
+
 	// вставляем с помощью PostGIS-функции
 	query := `
 		INSERT INTO orders (
 			id, client_id, courier_id, status,
 			delivery_address,
 			delivery_coords,
+			quote_id, price, currency,
+			zone_id, out_of_zone,
+			weight_kg, length_cm, width_cm, height_cm,
+			address_id, delivery_notes, delivery_code,
+			window_start, window_end, release_at,
+			surge_multiplier,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4,
 			$5,
 			ST_SetSRID(ST_MakePoint($6, $7), 4326),
-			$8, $9
+			$8, $9, nullif($10, ''),
+			$11, $12,
+			$13, $14, $15, $16,
+			$17, $18, nullif($19, ''),
+			$20, $21, $22,
+			$23,
+			$24, $25
 		)
 	`
-	_, err = r.db.Exec(query,
+	_, err = tx.ExecContext(ctx, query,
 		order.ID,
 		order.ClientID,
 		order.CourierID,
 		order.Status,
 		order.DeliveryAddress,
 		lon, lat,
+		order.QuoteID,
+		order.Price,
+		order.Currency,
+		order.ZoneID,
+		order.OutOfZone,
+		order.WeightKg,
+		order.LengthCm,
+		order.WidthCm,
+		order.HeightCm,
+		order.AddressID,
+		order.DeliveryNotes,
+		order.DeliveryCode,
+		order.WindowStart,
+		order.WindowEnd,
+		order.ReleaseAt,
+		order.SurgeMultiplier,
 		order.CreatedAt,
 		order.UpdatedAt,
 	)
@@ -104,36 +253,37 @@ func (r *orderRepository) Create(order *entity.Order) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := insertStops(ctx, tx, order); err != nil {
+		l.Error("failed to insert stops", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := logStatus(ctx, tx, order.ID, order.Status, order.CreatedAt); err != nil {
+		l.Error("failed to log status", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	l.Info("order created", zap.String("order_id", order.ID.String()))
 	return nil
 }
 
-func (r *orderRepository) GetByID(id uuid.UUID) (*entity.Order, error) {
+func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
 	const op = "OrderRepository.GetByID"
 	l := r.logger.With(zap.String("op", op), zap.String("order_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	query := `
-		SELECT
-			id, client_id, courier_id, status,
-			delivery_address,
-			ST_AsText(delivery_coords) AS delivery_coords,
-			created_at, updated_at
+	query := `SELECT` + orderColumns + `
 		FROM orders
 		WHERE id = $1
 	`
-	row := r.db.QueryRow(query, id)
-
-	var order entity.Order
-	if err := row.Scan(
-		&order.ID,
-		&order.ClientID,
-		&order.CourierID,
-		&order.Status,
-		&order.DeliveryAddress,
-		&order.DeliveryCoords,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-	); err != nil {
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrOrderNotFound
 		}
@@ -141,50 +291,34 @@ func (r *orderRepository) GetByID(id uuid.UUID) (*entity.Order, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	stops, err := r.stopsFor(ctx, []uuid.UUID{order.ID})
+	if err != nil {
+		l.Error("failed to load stops", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	order.Stops = stops[order.ID]
+
 	l.Debug("order fetched", zap.String("order_id", order.ID.String()))
-	return &order, nil
+	return order, nil
 }
 
-func (r *orderRepository) GetAll() ([]*entity.Order, error) {
+func (r *orderRepository) GetAll(ctx context.Context) ([]*entity.Order, error) {
 	const op = "OrderRepository.GetAll"
 	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	query := `
-		SELECT
-			id, client_id, courier_id, status,
-			delivery_address,
-			ST_AsText(delivery_coords) AS delivery_coords,
-			created_at, updated_at
+	query := `SELECT` + orderColumns + `
 		FROM orders
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	list, err := r.queryOrders(ctx, query)
 	if err != nil {
 		l.Error("failed to query orders", zap.Error(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer rows.Close()
-
-	var list []*entity.Order
-	for rows.Next() {
-		var order entity.Order
-		if err := rows.Scan(
-			&order.ID,
-			&order.ClientID,
-			&order.CourierID,
-			&order.Status,
-			&order.DeliveryAddress,
-			&order.DeliveryCoords,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		); err != nil {
-			l.Error("failed to scan order row", zap.Error(err))
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		list = append(list, &order)
-	}
-	if err := rows.Err(); err != nil {
-		l.Error("rows iteration error", zap.Error(err))
+	if err := r.attachStops(ctx, list); err != nil {
+		l.Error("failed to load stops", zap.Error(err))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -192,9 +326,11 @@ func (r *orderRepository) GetAll() ([]*entity.Order, error) {
 	return list, nil
 }
 
-func (r *orderRepository) Update(order *entity.Order) error {
+func (r *orderRepository) Update(ctx context.Context, order *entity.Order) error {
 	const op = "OrderRepository.Update"
 	l := r.logger.With(zap.String("op", op), zap.String("order_id", order.ID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
 	order.UpdatedAt = time.Now().UTC()
 
@@ -211,6 +347,22 @@ func (r *orderRepository) Update(order *entity.Order) error {
 		return fmt.Errorf("%s: parse lon: %w", op, err)
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var prevStatus entity.OrderStatus
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", order.ID).Scan(&prevStatus); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+		l.Error("failed to lock order", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	query := `
 		UPDATE orders SET
 			client_id        = $2,
@@ -218,10 +370,12 @@ func (r *orderRepository) Update(order *entity.Order) error {
 			status           = $4,
 			delivery_address = $5,
 			delivery_coords  = ST_SetSRID(ST_MakePoint($6, $7), 4326),
-			updated_at       = $8
-		WHERE id = $1
+			updated_at       = $8,
+			version          = version + 1
+		WHERE id = $1 AND version = $9
+		RETURNING version
 	`
-	res, err := r.db.Exec(query,
+	err = tx.QueryRowContext(ctx, query,
 		order.ID,
 		order.ClientID,
 		order.CourierID,
@@ -229,27 +383,38 @@ func (r *orderRepository) Update(order *entity.Order) error {
 		order.DeliveryAddress,
 		lon, lat,
 		order.UpdatedAt,
-	)
+		order.Version,
+	).Scan(&order.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVersionConflict
+	}
 	if err != nil {
 		l.Error("failed to update order", zap.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	n, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+
+	if prevStatus != order.Status {
+		if err := logStatus(ctx, tx, order.ID, order.Status, order.UpdatedAt); err != nil {
+			l.Error("failed to log status", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
 	}
-	if n == 0 {
-		return ErrOrderNotFound
+
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	l.Info("order updated", zap.String("order_id", order.ID.String()))
 	return nil
 }
 
-func (r *orderRepository) Delete(id uuid.UUID) error {
+func (r *orderRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	const op = "OrderRepository.Delete"
 	l := r.logger.With(zap.String("op", op), zap.String("order_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	res, err := r.db.Exec("DELETE FROM orders WHERE id = $1", id)
+	res, err := r.db.ExecContext(ctx, "DELETE FROM orders WHERE id = $1", id)
 	if err != nil {
 		l.Error("failed to delete order", zap.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
@@ -264,3 +429,401 @@ func (r *orderRepository) Delete(id uuid.UUID) error {
 	l.Info("order deleted", zap.String("order_id", id.String()))
 	return nil
 }
+
+func (r *orderRepository) GetActiveByCourier(ctx context.Context, courierID uuid.UUID) ([]*entity.Order, error) {
+	const op = "OrderRepository.GetActiveByCourier"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT` + orderColumns + `
+		FROM orders
+		WHERE courier_id = $1
+		  AND status IN ($2, $3)
+		ORDER BY created_at
+	`
+	list, err := r.queryOrders(ctx, query, courierID, entity.StatusAssigned, entity.StatusInTransit)
+	if err != nil {
+		l.Error("failed to query orders", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := r.attachStops(ctx, list); err != nil {
+		l.Error("failed to load stops", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	l.Debug("active orders fetched", zap.Int("count", len(list)))
+	return list, nil
+}
+
+func (r *orderRepository) CompleteStop(ctx context.Context, orderID, stopID uuid.UUID, at time.Time) error {
+	const op = "OrderRepository.CompleteStop"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()), zap.String("stop_id", stopID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// точка — часть заказа, поэтому растёт и версия заказа
+	res, err := r.db.ExecContext(ctx, `
+	WITH s AS (
+	    UPDATE order_stops SET completed_at = $3
+	     WHERE id = $1 AND order_id = $2 AND completed_at IS NULL
+	    RETURNING order_id
+	)
+	UPDATE orders SET version = version + 1 WHERE id IN (SELECT order_id FROM s)
+	`, stopID, orderID, at)
+	if err != nil {
+		l.Error("failed to complete stop", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		// время выполнения не перезаписываем: точку либо не нашли, либо
+		// её уже закрыли
+		var exists bool
+		err := r.db.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM order_stops WHERE id = $1 AND order_id = $2)",
+			stopID, orderID,
+		).Scan(&exists)
+		if err != nil {
+			l.Error("failed to check stop", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if exists {
+			return ErrStopCompleted
+		}
+		return ErrStopNotFound
+	}
+	l.Info("stop completed")
+	return nil
+}
+
+// SetRoutePositions сохраняет порядок объезда: stopIDs[0] получает позицию 1.
+func (r *orderRepository) SetRoutePositions(ctx context.Context, stopIDs []uuid.UUID) error {
+	const op = "OrderRepository.SetRoutePositions"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range stopIDs {
+		if _, err := tx.ExecContext(ctx, "UPDATE order_stops SET route_position = $2 WHERE id = $1", id, i+1); err != nil {
+			l.Error("failed to update stop", zap.String("stop_id", id.String()), zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE orders SET version = version + 1 WHERE id IN (SELECT order_id FROM order_stops WHERE id = ANY($1))",
+		pq.Array(stopIDs),
+	); err != nil {
+		l.Error("failed to bump order versions", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Debug("route positions saved", zap.Int("count", len(stopIDs)))
+	return nil
+}
+
+func (r *orderRepository) SetETA(ctx context.Context, orderID uuid.UUID, pickupAt, deliveryAt *time.Time) error {
+	const op = "OrderRepository.SetETA"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE orders SET estimated_pickup_at = $2, estimated_delivery_at = $3, version = version + 1 WHERE id = $1",
+		orderID, pickupAt, deliveryAt,
+	)
+	if err != nil {
+		l.Error("failed to set eta", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return ErrOrderNotFound
+	}
+	return nil
+}
+
+func (r *orderRepository) queryOrders(ctx context.Context, query string, args ...any) ([]*entity.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*entity.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, order)
+	}
+	return list, rows.Err()
+}
+
+func (r *orderRepository) attachStops(ctx context.Context, list []*entity.Order) error {
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, o := range list {
+		ids = append(ids, o.ID)
+	}
+	stops, err := r.stopsFor(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, o := range list {
+		o.Stops = stops[o.ID]
+	}
+	return nil
+}
+
+// stopsFor загружает точки маршрута сразу для нескольких заказов.
+func (r *orderRepository) stopsFor(ctx context.Context, orderIDs []uuid.UUID) (map[uuid.UUID][]entity.OrderStop, error) {
+	ret := make(map[uuid.UUID][]entity.OrderStop, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return ret, nil
+	}
+
+	ids := make([]string, len(orderIDs))
+	for i, id := range orderIDs {
+		ids[i] = id.String()
+	}
+	query := `
+		SELECT id, order_id, seq, kind, address,
+		       ST_Y(location) AS lat,
+		       ST_X(location) AS lon,
+		       completed_at, route_position, arrived_at
+		  FROM order_stops
+		 WHERE order_id = ANY($1::uuid[])
+		 ORDER BY order_id, seq
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.OrderStop
+		if err := rows.Scan(
+			&s.ID,
+			&s.OrderID,
+			&s.Sequence,
+			&s.Kind,
+			&s.Address,
+			&s.Location.Latitude,
+			&s.Location.Longitude,
+			&s.CompletedAt,
+			&s.RoutePosition,
+			&s.ArrivedAt,
+		); err != nil {
+			return nil, err
+		}
+		ret[s.OrderID] = append(ret[s.OrderID], s)
+	}
+	return ret, rows.Err()
+}
+
+func (r *orderRepository) AddEvent(ctx context.Context, e *entity.OrderEvent) error {
+	const op = "OrderRepository.AddEvent"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", e.OrderID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	var lon, lat *float64
+	if e.Location != nil {
+		lon, lat = &e.Location.Longitude, &e.Location.Latitude
+	}
+	const query = `
+		INSERT INTO order_events (id, order_id, stop_id, type, location, created_at)
+		VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326), $7)
+	`
+	if _, err := r.db.ExecContext(ctx, query, e.ID, e.OrderID, e.StopID, e.Type, lon, lat, e.CreatedAt); err != nil {
+		l.Error("failed to insert event", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("order event recorded", zap.String("type", string(e.Type)))
+	return nil
+}
+
+func (r *orderRepository) GetTimeline(ctx context.Context, orderID uuid.UUID) ([]entity.OrderEvent, error) {
+	const op = "OrderRepository.GetTimeline"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+		SELECT id, order_id, $2 AS type, status, NULL::uuid AS stop_id,
+		       NULL::float8 AS lat, NULL::float8 AS lon, created_at
+		  FROM order_status_logs
+		 WHERE order_id = $1
+		UNION ALL
+		SELECT id, order_id, type, '' AS status, stop_id,
+		       ST_Y(location), ST_X(location), created_at
+		  FROM order_events
+		 WHERE order_id = $1
+		 ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, orderID, entity.EventStatusChanged)
+	if err != nil {
+		l.Error("failed to query timeline", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []entity.OrderEvent
+	for rows.Next() {
+		var e entity.OrderEvent
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Type, &e.Status, &e.StopID, &lat, &lon, &e.CreatedAt); err != nil {
+			l.Error("failed to scan event", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if lat.Valid && lon.Valid {
+			e.Location = &entity.Coordinates{Latitude: lat.Float64, Longitude: lon.Float64}
+		}
+		ret = append(ret, e)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func logStatus(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, status entity.OrderStatus, at time.Time) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO order_status_logs (id, order_id, status, created_at) VALUES ($1, $2, $3, $4)",
+		uuid.New(), orderID, status, at,
+	)
+	return err
+}
+
+func insertStops(ctx context.Context, tx *sql.Tx, order *entity.Order) error {
+	const query = `
+		INSERT INTO order_stops (id, order_id, seq, kind, address, location, completed_at)
+		VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($6, $7), 4326), $8)
+	`
+	for i := range order.Stops {
+		s := &order.Stops[i]
+		if s.ID == uuid.Nil {
+			s.ID = uuid.New()
+		}
+		s.OrderID = order.ID
+		s.Sequence = i + 1
+		if _, err := tx.ExecContext(ctx, query,
+			s.ID, s.OrderID, s.Sequence, s.Kind, s.Address,
+			s.Location.Longitude, s.Location.Latitude,
+			s.CompletedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *orderRepository) ListDueForDispatch(ctx context.Context, now time.Time) ([]*entity.Order, error) {
+	const op = "OrderRepository.ListDueForDispatch"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT` + orderColumns + `
+		FROM orders
+		WHERE status = $1
+		  AND courier_id IS NULL
+		  AND (window_start IS NOT NULL OR window_end IS NOT NULL)
+		  AND (release_at IS NULL OR release_at <= $2)
+		ORDER BY coalesce(window_end, window_start)
+	`
+	list, err := r.queryOrders(ctx, query, entity.StatusCreated, now)
+	if err != nil {
+		l.Error("failed to query orders", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := r.attachStops(ctx, list); err != nil {
+		l.Error("failed to load stops", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return list, nil
+}
+
+func (r *orderRepository) ListWindowAtRisk(ctx context.Context, now time.Time, lead time.Duration) ([]*entity.Order, error) {
+	const op = "OrderRepository.ListWindowAtRisk"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT` + orderColumns + `
+		FROM orders
+		WHERE status IN ($1, $2, $3)
+		  AND window_end IS NOT NULL
+		  AND NOT window_at_risk
+		  AND (estimated_delivery_at > window_end
+		       OR (courier_id IS NULL AND window_end <= $4))
+		ORDER BY window_end
+	`
+	list, err := r.queryOrders(ctx, query,
+		entity.StatusCreated, entity.StatusAssigned, entity.StatusInTransit,
+		now.Add(lead),
+	)
+	if err != nil {
+		l.Error("failed to query orders", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return list, nil
+}
+
+func (r *orderRepository) MarkWindowAtRisk(ctx context.Context, orderID uuid.UUID, at time.Time) (bool, error) {
+	const op = "OrderRepository.MarkWindowAtRisk"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE orders SET window_at_risk = TRUE, version = version + 1 WHERE id = $1 AND NOT window_at_risk", orderID)
+	if err != nil {
+		l.Error("failed to flag order", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO order_events (id, order_id, type, created_at) VALUES ($1, $2, $3, $4)",
+		uuid.New(), orderID, entity.EventWindowAtRisk, at,
+	); err != nil {
+		l.Error("failed to insert event", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	l.Warn("order flagged as missing its delivery window")
+	return true, nil
+}
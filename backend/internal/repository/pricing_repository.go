@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPricingRulesNotFound = errors.New("pricing rules not found")
+	ErrQuoteNotFound        = errors.New("quote not found")
+	// ErrQuoteUnavailable — котировка истекла или уже использована.
+	ErrQuoteUnavailable = errors.New("quote is expired or already used")
+)
+
+type PricingRepository interface {
+	GetActiveRules(ctx context.Context) (*entity.PricingRules, error)
+	SaveRules(ctx context.Context, rules *entity.PricingRules) error
+	CreateQuote(ctx context.Context, q *entity.Quote) error
+	GetQuote(ctx context.Context, id uuid.UUID) (*entity.Quote, error)
+}
+
+type pricingRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewPricingRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewPricingRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) PricingRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &pricingRepository{db: db, timeout: timeout, logger: logger}
+}
+
+// GetActiveRules возвращает последнюю сохранённую версию правил.
+func (r *pricingRepository) GetActiveRules(ctx context.Context) (*entity.PricingRules, error) {
+	const op = "PricingRepository.GetActiveRules"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+		SELECT id, rules, created_by, created_at
+		  FROM pricing_rules
+		 ORDER BY id DESC
+		 LIMIT 1
+	`
+	var (
+		id        int64
+		raw       []byte
+		rules     entity.PricingRules
+		createdBy uuid.NullUUID
+	)
+	if err := r.db.QueryRowContext(ctx, query).Scan(&id, &raw, &createdBy, &rules.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPricingRulesNotFound
+		}
+		l.Error("failed to scan rules", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	createdAt := rules.CreatedAt
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		l.Error("failed to decode rules", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rules.Version = id
+	rules.CreatedAt = createdAt
+	if createdBy.Valid {
+		rules.CreatedBy = &createdBy.UUID
+	}
+	return &rules, nil
+}
+
+// SaveRules добавляет новую версию правил; старые версии остаются для
+// котировок, которые на них ссылаются.
+func (r *pricingRepository) SaveRules(ctx context.Context, rules *entity.PricingRules) error {
+	const op = "PricingRepository.SaveRules"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	const query = `
+		INSERT INTO pricing_rules (rules, created_by)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, raw, rules.CreatedBy).Scan(&rules.Version, &rules.CreatedAt); err != nil {
+		l.Error("failed to insert rules", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("pricing rules saved", zap.Int64("version", rules.Version))
+	return nil
+}
+
+func (r *pricingRepository) CreateQuote(ctx context.Context, q *entity.Quote) error {
+	const op = "PricingRepository.CreateQuote"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	breakdown, err := json.Marshal(q.Breakdown)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	const query = `
+		INSERT INTO quotes (
+			id, pickup, dropoff, distance_meters, weight_kg,
+			price, currency, breakdown, rules_version, expires_at, created_at,
+			zone_id, surge_multiplier
+		) VALUES (
+			$1,
+			ST_SetSRID(ST_MakePoint($2, $3), 4326),
+			ST_SetSRID(ST_MakePoint($4, $5), 4326),
+			$6, $7, $8, $9, $10, $11, $12, $13,
+			$14, $15
+		)
+	`
+	_, err = r.db.ExecContext(ctx, query,
+		q.ID,
+		q.Pickup.Longitude, q.Pickup.Latitude,
+		q.Dropoff.Longitude, q.Dropoff.Latitude,
+		q.DistanceMeters, q.WeightKg,
+		q.Price, q.Currency, breakdown, q.RulesVersion,
+		q.ExpiresAt, q.CreatedAt,
+		q.ZoneID, q.SurgeMultiplier,
+	)
+	if err != nil {
+		l.Error("failed to insert quote", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("quote created", zap.String("quote_id", q.ID.String()), zap.Int64("price", q.Price))
+	return nil
+}
+
+func (r *pricingRepository) GetQuote(ctx context.Context, id uuid.UUID) (*entity.Quote, error) {
+	const op = "PricingRepository.GetQuote"
+	l := r.logger.With(zap.String("op", op), zap.String("quote_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+		SELECT id,
+		       ST_Y(pickup), ST_X(pickup),
+		       ST_Y(dropoff), ST_X(dropoff),
+		       distance_meters, weight_kg, price, currency, breakdown,
+		       coalesce(rules_version, 0), expires_at, used_at, created_at,
+		       zone_id, surge_multiplier
+		  FROM quotes
+		 WHERE id = $1
+	`
+	var q entity.Quote
+	var breakdown []byte
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&q.ID,
+		&q.Pickup.Latitude, &q.Pickup.Longitude,
+		&q.Dropoff.Latitude, &q.Dropoff.Longitude,
+		&q.DistanceMeters, &q.WeightKg, &q.Price, &q.Currency, &breakdown,
+		&q.RulesVersion, &q.ExpiresAt, &q.UsedAt, &q.CreatedAt,
+		&q.ZoneID, &q.SurgeMultiplier,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrQuoteNotFound
+		}
+		l.Error("failed to scan quote", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := json.Unmarshal(breakdown, &q.Breakdown); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &q, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrProofNotFound = errors.New("delivery proof not found")
+	// ErrCodeAttemptsExceeded — код вручения больше нельзя проверять.
+	ErrCodeAttemptsExceeded = errors.New("too many wrong delivery code attempts")
+)
+
+type ProofRepository interface {
+	Create(p *entity.DeliveryProof) error
+	// Delete убирает подтверждение, если точку так и не удалось закрыть.
+	Delete(id uuid.UUID) error
+	GetByID(id uuid.UUID) (*entity.DeliveryProof, error)
+	ListByOrder(orderID uuid.UUID) ([]*entity.DeliveryProof, error)
+	// DeliveryCode возвращает клиента заказа и код вручения.
+	DeliveryCode(orderID uuid.UUID) (clientID uuid.UUID, code string, err error)
+	// CheckCode сверяет код и расходует попытку; после maxAttempts
+	// неверных попыток возвращает ErrCodeAttemptsExceeded.
+	CheckCode(orderID uuid.UUID, code string, maxAttempts int) (bool, error)
+}
+
+type proofRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewProofRepository(db *sql.DB, logger *zap.Logger) ProofRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &proofRepository{db: db, logger: logger}
+}
+
+const proofColumns = `id, order_id, stop_id, courier_id, kind,
+	       coalesce(file_key, ''), coalesce(content_type, ''), coalesce(size_bytes, 0),
+	       ST_Y(location), ST_X(location), recorded_at, created_at`
+
+func scanProof(row rowScanner) (*entity.DeliveryProof, error) {
+	var p entity.DeliveryProof
+	if err := row.Scan(
+		&p.ID, &p.OrderID, &p.StopID, &p.CourierID, &p.Kind,
+		&p.FileKey, &p.ContentType, &p.SizeBytes,
+		&p.Location.Latitude, &p.Location.Longitude,
+		&p.RecordedAt, &p.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *proofRepository) Create(p *entity.DeliveryProof) error {
+	const op = "ProofRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", p.OrderID.String()))
+
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	p.CreatedAt = time.Now().UTC()
+
+	const query = `
+	INSERT INTO delivery_proofs
+	       (id, order_id, stop_id, courier_id, kind, file_key, content_type, size_bytes, location, recorded_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, nullif($6, ''), nullif($7, ''), nullif($8, 0),
+	        ST_SetSRID(ST_MakePoint($9, $10), 4326), $11, $12)
+	`
+	if _, err := r.db.Exec(query,
+		p.ID, p.OrderID, p.StopID, p.CourierID, p.Kind,
+		p.FileKey, p.ContentType, p.SizeBytes,
+		p.Location.Longitude, p.Location.Latitude,
+		p.RecordedAt, p.CreatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("delivery proof saved", zap.String("proof_id", p.ID.String()), zap.String("kind", string(p.Kind)))
+	return nil
+}
+
+func (r *proofRepository) Delete(id uuid.UUID) error {
+	const op = "ProofRepository.Delete"
+	l := r.logger.With(zap.String("op", op), zap.String("proof_id", id.String()))
+
+	if _, err := r.db.Exec("DELETE FROM delivery_proofs WHERE id = $1", id); err != nil {
+		l.Error("failed to delete proof", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *proofRepository) GetByID(id uuid.UUID) (*entity.DeliveryProof, error) {
+	const op = "ProofRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("proof_id", id.String()))
+
+	p, err := scanProof(r.db.QueryRow("SELECT "+proofColumns+" FROM delivery_proofs WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProofNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return p, nil
+}
+
+func (r *proofRepository) ListByOrder(orderID uuid.UUID) ([]*entity.DeliveryProof, error) {
+	const op = "ProofRepository.ListByOrder"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+
+	rows, err := r.db.Query("SELECT "+proofColumns+" FROM delivery_proofs WHERE order_id = $1 ORDER BY recorded_at", orderID)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.DeliveryProof
+	for rows.Next() {
+		p, err := scanProof(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, p)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *proofRepository) DeliveryCode(orderID uuid.UUID) (uuid.UUID, string, error) {
+	const op = "ProofRepository.DeliveryCode"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+
+	var (
+		clientID uuid.UUID
+		code     sql.NullString
+	)
+	if err := r.db.QueryRow("SELECT client_id, delivery_code FROM orders WHERE id = $1", orderID).Scan(&clientID, &code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, "", ErrOrderNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return uuid.Nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return clientID, code.String, nil
+}
+
+func (r *proofRepository) CheckCode(orderID uuid.UUID, code string, maxAttempts int) (bool, error) {
+	const op = "ProofRepository.CheckCode"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+
+	// верный код попытку не расходует; условие на счётчик не даёт
+	// перебирать коды параллельными запросами
+	const query = `
+	UPDATE orders
+	   SET delivery_code_attempts = delivery_code_attempts + CASE WHEN delivery_code = $2 THEN 0 ELSE 1 END,
+	       version = version + CASE WHEN delivery_code = $2 THEN 0 ELSE 1 END
+	 WHERE id = $1
+	   AND delivery_code_attempts < $3
+	RETURNING delivery_code = $2
+	`
+	var ok sql.NullBool
+	if err := r.db.QueryRow(query, orderID, code, maxAttempts).Scan(&ok); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)", orderID).Scan(&exists); err != nil {
+				return false, fmt.Errorf("%s: %w", op, err)
+			}
+			if !exists {
+				return false, ErrOrderNotFound
+			}
+			return false, ErrCodeAttemptsExceeded
+		}
+		l.Error("query failed", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok.Bool {
+		l.Warn("wrong delivery code")
+	}
+	return ok.Bool, nil
+}
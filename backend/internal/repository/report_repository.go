@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"backend/internal/entity"
+
+	"go.uber.org/zap"
+)
+
+// ReportRepository считает отчёты по витрине report_order_facts, поэтому
+// свежие заказы появляются в них только после Refresh.
+type ReportRepository interface {
+	Volume(q entity.ReportQuery) ([]*entity.VolumeRow, error)
+	Timing(q entity.ReportQuery) ([]*entity.TimingRow, error)
+	Cancellations(q entity.ReportQuery) ([]*entity.CancellationRow, error)
+	// Refresh пересчитывает витрину, не блокируя чтение отчётов.
+	Refresh() error
+}
+
+type reportRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewReportRepository(db *sql.DB, logger *zap.Logger) ReportRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &reportRepository{db: db, logger: logger}
+}
+
+func (r *reportRepository) Volume(q entity.ReportQuery) ([]*entity.VolumeRow, error) {
+	var ret []*entity.VolumeRow
+	err := r.aggregate("ReportRepository.Volume", q, `
+	       count(*),
+	       count(*) FILTER (WHERE f.status = 'DELIVERED'),
+	       count(*) FILTER (WHERE f.status = 'CANCELED'),
+	       count(*) FILTER (WHERE f.status NOT IN ('DELIVERED', 'CANCELED'))`,
+		func(row rowScanner) error {
+			var v entity.VolumeRow
+			if err := row.Scan(&v.Key, &v.Label, &v.Orders, &v.Delivered, &v.Canceled, &v.InProgress); err != nil {
+				return err
+			}
+			ret = append(ret, &v)
+			return nil
+		})
+	return ret, err
+}
+
+func (r *reportRepository) Timing(q entity.ReportQuery) ([]*entity.TimingRow, error) {
+	var ret []*entity.TimingRow
+	// назначение вручную до release_at не даёт отрицательного времени
+	err := r.aggregate("ReportRepository.Timing", q, `
+	       count(f.assigned_at),
+	       avg(extract(epoch FROM greatest(f.assigned_at, f.started_at) - f.started_at)),
+	       count(f.delivered_at),
+	       avg(extract(epoch FROM greatest(f.delivered_at, f.started_at) - f.started_at))`,
+		func(row rowScanner) error {
+			var t entity.TimingRow
+			if err := row.Scan(&t.Key, &t.Label, &t.Assigned, &t.AvgAssignSeconds, &t.Delivered, &t.AvgDeliverSeconds); err != nil {
+				return err
+			}
+			ret = append(ret, &t)
+			return nil
+		})
+	return ret, err
+}
+
+func (r *reportRepository) Cancellations(q entity.ReportQuery) ([]*entity.CancellationRow, error) {
+	var ret []*entity.CancellationRow
+	err := r.aggregate("ReportRepository.Cancellations", q, `
+	       count(*),
+	       count(*) FILTER (WHERE f.status = 'CANCELED'),
+	       count(*) FILTER (WHERE f.status = 'CANCELED' AND f.assigned_at IS NULL)`,
+		func(row rowScanner) error {
+			var c entity.CancellationRow
+			if err := row.Scan(&c.Key, &c.Label, &c.Orders, &c.Canceled, &c.CanceledUnassigned); err != nil {
+				return err
+			}
+			if c.Orders > 0 {
+				c.CancellationRate = float64(c.Canceled) / float64(c.Orders)
+			}
+			ret = append(ret, &c)
+			return nil
+		})
+	return ret, err
+}
+
+// aggregate выполняет агрегат columns по заказам периода в разрезе
+// q.GroupBy; первые две колонки строки — ключ и подпись группы.
+func (r *reportRepository) aggregate(op string, q entity.ReportQuery, columns string, scan func(rowScanner) error) error {
+	l := r.logger.With(zap.String("op", op), zap.String("group_by", string(q.GroupBy)))
+
+	args := []any{q.From, q.To}
+	var key, label, join string
+	switch q.GroupBy {
+	case entity.ReportByDay:
+		args = append(args, q.Location)
+		key, label = "to_char(f.created_at AT TIME ZONE $3, 'YYYY-MM-DD')", "''"
+	case entity.ReportByZone:
+		key, label = "coalesce(f.zone_id::text, '')", "coalesce(z.name, '')"
+		join = "\n\t  LEFT JOIN zones z ON z.id = f.zone_id"
+	case entity.ReportByCourier:
+		key, label = "coalesce(f.courier_id::text, '')", "coalesce(c.name, '')"
+		join = "\n\t  LEFT JOIN couriers c ON c.user_id = f.courier_id"
+	default:
+		return fmt.Errorf("%s: unknown report group %q", op, q.GroupBy)
+	}
+
+	query := `
+	SELECT ` + key + `, ` + label + `,` + columns + `
+	  FROM report_order_facts f` + join + `
+	 WHERE f.created_at >= $1 AND f.created_at < $2
+	 GROUP BY 1, 2
+	 ORDER BY 1`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *reportRepository) Refresh() error {
+	const op = "ReportRepository.Refresh"
+	l := r.logger.With(zap.String("op", op))
+
+	if _, err := r.db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY report_order_facts"); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrShiftNotFound = errors.New("shift not found")
+
+// ShiftFilter — условия выборки смен; пустые поля не фильтруют. From/To
+// ограничивают плановое начало смены.
+type ShiftFilter struct {
+	CourierID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+}
+
+type ShiftRepository interface {
+	Create(ctx context.Context, s *entity.Shift) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Shift, error)
+	List(ctx context.Context, f ShiftFilter) ([]*entity.Shift, error)
+	Update(ctx context.Context, s *entity.Shift) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// GetActive возвращает открытую смену курьера или ErrShiftNotFound.
+	GetActive(ctx context.Context, courierID uuid.UUID) (*entity.Shift, error)
+	// Summary считает доставленные заказы и пробег за фактическое время
+	// смены; для открытой смены — по состоянию на now.
+	Summary(ctx context.Context, s *entity.Shift, now time.Time) (*entity.ShiftSummary, error)
+}
+
+type shiftRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewShiftRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewShiftRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) ShiftRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &shiftRepository{db: db, timeout: timeout, logger: logger}
+}
+
+const shiftColumns = `id, courier_id, zone_id, planned_start, planned_end, started_at, ended_at, created_at, updated_at`
+
+func scanShift(row rowScanner) (*entity.Shift, error) {
+	var s entity.Shift
+	if err := row.Scan(
+		&s.ID, &s.CourierID, &s.ZoneID,
+		&s.PlannedStart, &s.PlannedEnd,
+		&s.StartedAt, &s.EndedAt,
+		&s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *shiftRepository) Create(ctx context.Context, s *entity.Shift) error {
+	const op = "ShiftRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", s.CourierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	s.CreatedAt, s.UpdatedAt = now, now
+
+	const query = `
+	INSERT INTO shifts (` + shiftColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		s.ID, s.CourierID, s.ZoneID,
+		s.PlannedStart, s.PlannedEnd,
+		s.StartedAt, s.EndedAt,
+		s.CreatedAt, s.UpdatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("shift created", zap.String("shift_id", s.ID.String()))
+	return nil
+}
+
+func (r *shiftRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Shift, error) {
+	const op = "ShiftRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("shift_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	s, err := scanShift(r.db.QueryRowContext(ctx, "SELECT "+shiftColumns+" FROM shifts WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShiftNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+func (r *shiftRepository) GetActive(ctx context.Context, courierID uuid.UUID) (*entity.Shift, error) {
+	const op = "ShiftRepository.GetActive"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const query = `
+	SELECT ` + shiftColumns + `
+	  FROM shifts
+	 WHERE courier_id = $1
+	   AND started_at IS NOT NULL
+	   AND ended_at IS NULL
+	`
+	s, err := scanShift(r.db.QueryRowContext(ctx, query, courierID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShiftNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+func (r *shiftRepository) List(ctx context.Context, f ShiftFilter) ([]*entity.Shift, error) {
+	const op = "ShiftRepository.List"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	if f.CourierID != nil {
+		args = append(args, *f.CourierID)
+		where = append(where, fmt.Sprintf("courier_id = $%d", len(args)))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where = append(where, fmt.Sprintf("planned_start >= $%d", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where = append(where, fmt.Sprintf("planned_start < $%d", len(args)))
+	}
+
+	query := "SELECT " + shiftColumns + " FROM shifts"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY planned_start"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.Shift
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, s)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *shiftRepository) Update(ctx context.Context, s *entity.Shift) error {
+	const op = "ShiftRepository.Update"
+	l := r.logger.With(zap.String("op", op), zap.String("shift_id", s.ID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	s.UpdatedAt = time.Now().UTC()
+	const query = `
+	UPDATE shifts SET
+	       zone_id       = $2,
+	       planned_start = $3,
+	       planned_end   = $4,
+	       started_at    = $5,
+	       ended_at      = $6,
+	       updated_at    = $7
+	 WHERE id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, s.ID, s.ZoneID, s.PlannedStart, s.PlannedEnd, s.StartedAt, s.EndedAt, s.UpdatedAt)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrShiftNotFound
+	}
+	return nil
+}
+
+func (r *shiftRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	const op = "ShiftRepository.Delete"
+	l := r.logger.With(zap.String("op", op), zap.String("shift_id", id.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM shifts WHERE id = $1", id)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrShiftNotFound
+	}
+	return nil
+}
+
+func (r *shiftRepository) Summary(ctx context.Context, s *entity.Shift, now time.Time) (*entity.ShiftSummary, error) {
+	const op = "ShiftRepository.Summary"
+	l := r.logger.With(zap.String("op", op), zap.String("shift_id", s.ID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	sum := &entity.ShiftSummary{
+		ShiftID:   s.ID,
+		CourierID: s.CourierID,
+		StartedAt: s.StartedAt,
+		EndedAt:   s.EndedAt,
+	}
+	if s.StartedAt == nil {
+		return sum, nil
+	}
+	end := now
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	sum.WorkedSeconds = int64(end.Sub(*s.StartedAt).Seconds())
+
+	// заказ засчитывается смене, в которую он перешёл в DELIVERED
+	const query = `
+	SELECT
+	  (SELECT count(DISTINCT l.order_id)
+	     FROM order_status_logs l
+	     JOIN orders o ON o.id = l.order_id
+	    WHERE o.courier_id = $1
+	      AND l.status = 'DELIVERED'
+	      AND l.created_at BETWEEN $2 AND $3),
+	  (SELECT coalesce(ST_Length(ST_MakeLine(location ORDER BY recorded_at)::geography), 0)
+	     FROM courier_locations
+	    WHERE courier_id = $1
+	      AND recorded_at BETWEEN $2 AND $3)
+	`
+	if err := r.db.QueryRowContext(ctx, query, s.CourierID, *s.StartedAt, end).Scan(&sum.OrdersDelivered, &sum.DistanceMeters); err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sum, nil
+}
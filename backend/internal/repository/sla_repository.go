@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+var ErrSLAPolicyNotFound = errors.New("sla policy not found")
+
+// SLABreachFilter — условия отчёта о нарушениях; период — по сроку
+// (deadline), пустые поля не фильтруют.
+type SLABreachFilter struct {
+	Metric  entity.SLAMetric
+	OrderID *uuid.UUID
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+type SLARepository interface {
+	ListPolicies() ([]*entity.SLAPolicy, error)
+	GetPolicy(metric entity.SLAMetric) (*entity.SLAPolicy, error)
+	SavePolicy(p *entity.SLAPolicy) error
+	// DetectBreaches сохраняет новые нарушения политики среди заказов,
+	// созданных после since, и возвращает только их. Заодно отмечает,
+	// когда ранее найденные нарушители всё-таки достигли этапа.
+	DetectBreaches(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLABreach, error)
+	// ListAtRisk — заказы, у которых до срока по политике осталось меньше
+	// WarnBefore.
+	ListAtRisk(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLARisk, error)
+	// MarkWarned запоминает предупреждение; false — о заказе уже
+	// предупреждали.
+	MarkWarned(orderID uuid.UUID, metric entity.SLAMetric, at time.Time) (bool, error)
+	ListBreaches(f SLABreachFilter) ([]*entity.SLABreach, error)
+	CountBreaches(f SLABreachFilter) (map[entity.SLAMetric]int, error)
+}
+
+type slaRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewSLARepository(db *sql.DB, logger *zap.Logger) SLARepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &slaRepository{db: db, logger: logger}
+}
+
+const slaPolicyColumns = `metric, threshold_seconds, warn_before_seconds, enabled, updated_by, updated_at`
+
+func scanSLAPolicy(row rowScanner) (*entity.SLAPolicy, error) {
+	var p entity.SLAPolicy
+	if err := row.Scan(&p.Metric, &p.ThresholdSeconds, &p.WarnBeforeSeconds, &p.Enabled, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *slaRepository) ListPolicies() ([]*entity.SLAPolicy, error) {
+	const op = "SLARepository.ListPolicies"
+	l := r.logger.With(zap.String("op", op))
+
+	rows, err := r.db.Query("SELECT " + slaPolicyColumns + " FROM sla_policies ORDER BY metric")
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.SLAPolicy
+	for rows.Next() {
+		p, err := scanSLAPolicy(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, p)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *slaRepository) GetPolicy(metric entity.SLAMetric) (*entity.SLAPolicy, error) {
+	const op = "SLARepository.GetPolicy"
+	l := r.logger.With(zap.String("op", op), zap.String("metric", string(metric)))
+
+	p, err := scanSLAPolicy(r.db.QueryRow("SELECT "+slaPolicyColumns+" FROM sla_policies WHERE metric = $1", metric))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSLAPolicyNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return p, nil
+}
+
+func (r *slaRepository) SavePolicy(p *entity.SLAPolicy) error {
+	const op = "SLARepository.SavePolicy"
+	l := r.logger.With(zap.String("op", op), zap.String("metric", string(p.Metric)))
+
+	p.UpdatedAt = time.Now().UTC()
+	const query = `
+	INSERT INTO sla_policies (metric, threshold_seconds, warn_before_seconds, enabled, updated_by, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (metric) DO UPDATE
+	   SET threshold_seconds   = EXCLUDED.threshold_seconds,
+	       warn_before_seconds = EXCLUDED.warn_before_seconds,
+	       enabled             = EXCLUDED.enabled,
+	       updated_by          = EXCLUDED.updated_by,
+	       updated_at          = EXCLUDED.updated_at
+	`
+	if _, err := r.db.Exec(query,
+		p.Metric, p.ThresholdSeconds, p.WarnBeforeSeconds, p.Enabled, p.UpdatedBy, p.UpdatedAt,
+	); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("sla policy saved", zap.Int64("threshold_seconds", p.ThresholdSeconds))
+	return nil
+}
+
+// slaProgress — срок по политике и момент достижения этапа для каждого
+// заказа. $1 — порог в секундах, $2 — статусы этапа, $3 — since.
+const slaProgress = `
+	WITH progress AS (
+		SELECT o.id, o.status,
+		       coalesce(o.release_at, o.created_at) + make_interval(secs => $1) AS deadline,
+		       (SELECT min(sl.created_at) FROM order_status_logs sl
+		         WHERE sl.order_id = o.id AND sl.status = ANY($2)) AS reached_at,
+		       (SELECT min(sl.created_at) FROM order_status_logs sl
+		         WHERE sl.order_id = o.id AND sl.status = 'CANCELED') AS canceled_at
+		  FROM orders o
+		 WHERE o.created_at >= $3
+	)`
+
+func milestones(m entity.SLAMetric) pq.StringArray {
+	var ret pq.StringArray
+	for _, s := range m.Milestones() {
+		ret = append(ret, string(s))
+	}
+	return ret
+}
+
+const slaBreachColumns = `id, order_id, metric, threshold_seconds, deadline, reached_at, detected_at`
+
+func scanSLABreach(row rowScanner) (*entity.SLABreach, error) {
+	var b entity.SLABreach
+	if err := row.Scan(&b.ID, &b.OrderID, &b.Metric, &b.ThresholdSeconds, &b.Deadline, &b.ReachedAt, &b.DetectedAt); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *slaRepository) DetectBreaches(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLABreach, error) {
+	const op = "SLARepository.DetectBreaches"
+	l := r.logger.With(zap.String("op", op), zap.String("metric", string(p.Metric)))
+
+	statuses := milestones(p.Metric)
+	if _, err := r.db.Exec(`
+	UPDATE sla_breaches b
+	   SET reached_at = (SELECT min(sl.created_at) FROM order_status_logs sl
+	                      WHERE sl.order_id = b.order_id AND sl.status = ANY($2))
+	 WHERE b.metric = $1 AND b.reached_at IS NULL
+	`, p.Metric, statuses); err != nil {
+		l.Error("failed to close breaches", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// заказ, отменённый до срока, SLA не нарушает
+	query := slaProgress + `
+	INSERT INTO sla_breaches (order_id, metric, threshold_seconds, deadline, reached_at, detected_at)
+	SELECT id, $4, $1, deadline, reached_at, $5
+	  FROM progress
+	 WHERE deadline < coalesce(reached_at, canceled_at, $5)
+	ON CONFLICT (order_id, metric) DO NOTHING
+	RETURNING ` + slaBreachColumns
+	rows, err := r.db.Query(query, p.ThresholdSeconds, statuses, since, p.Metric, now)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.SLABreach
+	for rows.Next() {
+		b, err := scanSLABreach(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, b)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(ret) > 0 {
+		l.Warn("sla breaches detected", zap.Int("count", len(ret)))
+	}
+	return ret, nil
+}
+
+func (r *slaRepository) ListAtRisk(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLARisk, error) {
+	const op = "SLARepository.ListAtRisk"
+	l := r.logger.With(zap.String("op", op), zap.String("metric", string(p.Metric)))
+
+	query := slaProgress + `
+	SELECT id, status, deadline
+	  FROM progress
+	 WHERE reached_at IS NULL AND canceled_at IS NULL
+	   AND deadline >= $4 AND deadline < $5
+	 ORDER BY deadline
+	`
+	rows, err := r.db.Query(query, p.ThresholdSeconds, milestones(p.Metric), since, now, now.Add(p.WarnBefore()))
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.SLARisk
+	for rows.Next() {
+		risk := &entity.SLARisk{Metric: p.Metric}
+		if err := rows.Scan(&risk.OrderID, &risk.Status, &risk.Deadline); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, risk)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *slaRepository) MarkWarned(orderID uuid.UUID, metric entity.SLAMetric, at time.Time) (bool, error) {
+	const op = "SLARepository.MarkWarned"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+
+	res, err := r.db.Exec(
+		"INSERT INTO sla_warnings (order_id, metric, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		orderID, metric, at,
+	)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (f SLABreachFilter) where(arg func(any) string) string {
+	var where []string
+	if f.Metric != "" {
+		where = append(where, "metric = "+arg(f.Metric))
+	}
+	if f.OrderID != nil {
+		where = append(where, "order_id = "+arg(*f.OrderID))
+	}
+	if f.From != nil {
+		where = append(where, "deadline >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		where = append(where, "deadline < "+arg(*f.To))
+	}
+	if len(where) == 0 {
+		return ""
+	}
+	return "\n\t WHERE " + strings.Join(where, " AND ")
+}
+
+func (r *slaRepository) ListBreaches(f SLABreachFilter) ([]*entity.SLABreach, error) {
+	const op = "SLARepository.ListBreaches"
+	l := r.logger.With(zap.String("op", op))
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := "SELECT " + slaBreachColumns + "\n\t  FROM sla_breaches" + f.where(arg) + "\n\t ORDER BY deadline DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += " OFFSET " + arg(f.Offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.SLABreach
+	for rows.Next() {
+		b, err := scanSLABreach(rows)
+		if err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, b)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *slaRepository) CountBreaches(f SLABreachFilter) (map[entity.SLAMetric]int, error) {
+	const op = "SLARepository.CountBreaches"
+	l := r.logger.With(zap.String("op", op))
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	rows, err := r.db.Query("SELECT metric, count(*) FROM sla_breaches"+f.where(arg)+" GROUP BY metric", args...)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	ret := make(map[entity.SLAMetric]int)
+	for rows.Next() {
+		var (
+			m entity.SLAMetric
+			n int
+		)
+		if err := rows.Scan(&m, &n); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret[m] = n
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SurgeRepository interface {
+	// Load считает текущую загрузку активных зон и добавляет к ней
+	// сохранённые множители.
+	Load(ctx context.Context, now time.Time) ([]*entity.ZoneSurge, error)
+	// Get возвращает сохранённый множитель зоны; для зоны, где его ещё не
+	// считали, — 1.
+	Get(ctx context.Context, zoneID uuid.UUID) (*entity.ZoneSurge, error)
+	// Save сохраняет загрузку и расчётный множитель, не трогая
+	// переопределение.
+	Save(ctx context.Context, s *entity.ZoneSurge) error
+	// SetOverride задаёт ручной множитель; nil снимает переопределение.
+	SetOverride(ctx context.Context, zoneID uuid.UUID, override *float64, expiresAt *time.Time, actorID uuid.UUID) error
+}
+
+type surgeRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewSurgeRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewSurgeRepository(db *sql.DB, timeout time.Duration, logger *zap.Logger) SurgeRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &surgeRepository{db: db, timeout: timeout, logger: logger}
+}
+
+func (r *surgeRepository) Load(ctx context.Context, now time.Time) ([]*entity.ZoneSurge, error) {
+	const op = "SurgeRepository.Load"
+	l := r.logger.With(zap.String("op", op))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// свободные курьеры считаются так же, как при автоназначении
+	const query = `
+	SELECT z.id, z.name,
+	       (SELECT count(*) FROM orders o
+	         WHERE o.zone_id = z.id AND o.status = 'CREATED' AND o.courier_id IS NULL
+	           AND (o.release_at IS NULL OR o.release_at <= $1)),
+	       (SELECT count(*) FROM couriers c
+	         WHERE c.status = 'AVAILABLE' AND c.account_status = 'ACTIVE'
+	           AND ST_Intersects(c.location, z.boundary)
+	           AND EXISTS (
+	                 SELECT 1 FROM shifts sh
+	                  WHERE sh.courier_id = c.user_id
+	                    AND sh.started_at IS NOT NULL
+	                    AND sh.ended_at IS NULL
+	               )),
+	       coalesce(s.multiplier, 1), s.override_multiplier, s.override_expires_at, s.override_by,
+	       coalesce(s.updated_at, $1)
+	  FROM zones z
+	  LEFT JOIN zone_surge s ON s.zone_id = z.id
+	 WHERE z.active
+	 ORDER BY z.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		l.Error("query failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ret []*entity.ZoneSurge
+	for rows.Next() {
+		var s entity.ZoneSurge
+		if err := rows.Scan(
+			&s.ZoneID, &s.ZoneName, &s.OpenOrders, &s.AvailableCouriers,
+			&s.Multiplier, &s.Override, &s.OverrideExpiresAt, &s.OverrideBy, &s.UpdatedAt,
+		); err != nil {
+			l.Error("scan failed", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ret = append(ret, &s)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return ret, nil
+}
+
+func (r *surgeRepository) Get(ctx context.Context, zoneID uuid.UUID) (*entity.ZoneSurge, error) {
+	const op = "SurgeRepository.Get"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", zoneID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	s := entity.ZoneSurge{ZoneID: zoneID}
+	err := r.db.QueryRowContext(ctx, `
+	SELECT open_orders, available_couriers, multiplier,
+	       override_multiplier, override_expires_at, override_by, updated_at
+	  FROM zone_surge
+	 WHERE zone_id = $1
+	`, zoneID).Scan(
+		&s.OpenOrders, &s.AvailableCouriers, &s.Multiplier,
+		&s.Override, &s.OverrideExpiresAt, &s.OverrideBy, &s.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.Multiplier = 1
+		return &s, nil
+	}
+	if err != nil {
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &s, nil
+}
+
+func (r *surgeRepository) Save(ctx context.Context, s *entity.ZoneSurge) error {
+	const op = "SurgeRepository.Save"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", s.ZoneID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `
+	INSERT INTO zone_surge (zone_id, open_orders, available_couriers, multiplier, updated_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (zone_id) DO UPDATE
+	   SET open_orders        = EXCLUDED.open_orders,
+	       available_couriers = EXCLUDED.available_couriers,
+	       multiplier         = EXCLUDED.multiplier,
+	       updated_at         = EXCLUDED.updated_at
+	`, s.ZoneID, s.OpenOrders, s.AvailableCouriers, s.Multiplier, s.UpdatedAt); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *surgeRepository) SetOverride(ctx context.Context, zoneID uuid.UUID, override *float64, expiresAt *time.Time, actorID uuid.UUID) error {
+	const op = "SurgeRepository.SetOverride"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", zoneID.String()))
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `
+	INSERT INTO zone_surge (zone_id, override_multiplier, override_expires_at, override_by)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (zone_id) DO UPDATE
+	   SET override_multiplier = EXCLUDED.override_multiplier,
+	       override_expires_at = EXCLUDED.override_expires_at,
+	       override_by         = EXCLUDED.override_by
+	`, zoneID, override, expiresAt, actorID); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("surge override set", zap.Any("multiplier", override), zap.String("actor_id", actorID.String()))
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrTrackingLinkNotFound = errors.New("tracking link not found")
+
+type TrackingRepository interface {
+	Create(link *entity.TrackingLink) error
+	GetByID(id uuid.UUID) (*entity.TrackingLink, error)
+	// RevokeByOrder отзывает все действующие ссылки заказа и возвращает
+	// их число.
+	RevokeByOrder(orderID uuid.UUID, at time.Time) (int64, error)
+}
+
+type trackingRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewTrackingRepository(db *sql.DB, logger *zap.Logger) TrackingRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &trackingRepository{db: db, logger: logger}
+}
+
+func (r *trackingRepository) Create(link *entity.TrackingLink) error {
+	const op = "TrackingRepository.Create"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", link.OrderID.String()))
+
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	link.CreatedAt = time.Now().UTC()
+
+	const query = `
+	INSERT INTO tracking_links (id, order_id, expires_at, created_at)
+	VALUES ($1, $2, $3, $4)
+	`
+	if _, err := r.db.Exec(query, link.ID, link.OrderID, link.ExpiresAt, link.CreatedAt); err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("tracking link created", zap.Time("expires_at", link.ExpiresAt))
+	return nil
+}
+
+func (r *trackingRepository) GetByID(id uuid.UUID) (*entity.TrackingLink, error) {
+	const op = "TrackingRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("link_id", id.String()))
+
+	var link entity.TrackingLink
+	err := r.db.QueryRow(
+		"SELECT id, order_id, expires_at, revoked_at, created_at FROM tracking_links WHERE id = $1", id,
+	).Scan(&link.ID, &link.OrderID, &link.ExpiresAt, &link.RevokedAt, &link.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTrackingLinkNotFound
+		}
+		l.Error("scan failed", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &link, nil
+}
+
+func (r *trackingRepository) RevokeByOrder(orderID uuid.UUID, at time.Time) (int64, error) {
+	const op = "TrackingRepository.RevokeByOrder"
+	l := r.logger.With(zap.String("op", op), zap.String("order_id", orderID.String()))
+
+	res, err := r.db.Exec(
+		"UPDATE tracking_links SET revoked_at = $2 WHERE order_id = $1 AND revoked_at IS NULL AND expires_at > $2",
+		orderID, at,
+	)
+	if err != nil {
+		l.Error("exec failed", zap.Error(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	n, _ := res.RowsAffected()
+	l.Info("tracking links revoked", zap.Int64("count", n))
+	return n, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"time"
@@ -11,19 +12,24 @@ import (
 )
 
 type UserRepository interface {
-	Create(user *entity.User) error
-	GetByEmail(email string) (*entity.User, error)
+	Create(ctx context.Context, user *entity.User) error
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 }
 
 type userRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
-	return &userRepository{db: db}
+// NewUserRepository: timeout — предел для каждого вызова, 0 — без предела.
+func NewUserRepository(db *sql.DB, timeout time.Duration) UserRepository {
+	return &userRepository{db: db, timeout: timeout}
 }
 
-func (r *userRepository) Create(user *entity.User) error {
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
@@ -35,17 +41,20 @@ func (r *userRepository) Create(user *entity.User) error {
 		INSERT INTO users (id, email, password_hash, role, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Role, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.PasswordHash, user.Role, user.CreatedAt, user.UpdatedAt)
 	return err
 }
 
-func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	query := `
 		SELECT id, email, password_hash, role, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
-	row := r.db.QueryRow(query, email)
+	row := r.db.QueryRowContext(ctx, query, email)
 	var user entity.User
 	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
@@ -58,13 +67,16 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 }
 
 
-func (r *userRepository) GetByID(id string) (*entity.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
 	query := `
 		SELECT id, email, password_hash, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 	var user entity.User
 	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
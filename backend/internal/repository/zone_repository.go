@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrZoneNotFound = errors.New("zone not found")
+
+type ZoneRepository interface {
+	Create(z *entity.Zone) error
+	GetByID(id uuid.UUID) (*entity.Zone, error)
+	GetAll() ([]*entity.Zone, error)
+	Update(z *entity.Zone) error
+	Delete(id uuid.UUID) error
+	// FindByPoint возвращает активную зону, содержащую точку, или
+	// ErrZoneNotFound.
+	FindByPoint(lat, lon float64) (*entity.Zone, error)
+	SetCourierZones(courierID uuid.UUID, zoneIDs []uuid.UUID) error
+}
+
+type zoneRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewZoneRepository(db *sql.DB, logger *zap.Logger) ZoneRepository {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &zoneRepository{db: db, logger: logger}
+}
+
+const zoneColumns = `id, name, ST_AsGeoJSON(boundary), active, created_at, updated_at`
+
+func scanZone(row rowScanner) (*entity.Zone, error) {
+	var z entity.Zone
+	var boundary string
+	if err := row.Scan(&z.ID, &z.Name, &boundary, &z.Active, &z.CreatedAt, &z.UpdatedAt); err != nil {
+		return nil, err
+	}
+	z.Boundary = []byte(boundary)
+	return &z, nil
+}
+
+func (r *zoneRepository) Create(z *entity.Zone) error {
+	const op = "ZoneRepository.Create"
+	l := r.logger.With(zap.String("op", op))
+
+	if z.ID == uuid.Nil {
+		z.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	z.CreatedAt = now
+	z.UpdatedAt = now
+
+	const query = `
+		INSERT INTO zones (id, name, boundary, active, created_at, updated_at)
+		VALUES ($1, $2, ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($3), 4326)), $4, $5, $6)
+	`
+	if _, err := r.db.Exec(query, z.ID, z.Name, string(z.Boundary), z.Active, z.CreatedAt, z.UpdatedAt); err != nil {
+		l.Error("failed to insert zone", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("zone created", zap.String("zone_id", z.ID.String()))
+	return nil
+}
+
+func (r *zoneRepository) GetByID(id uuid.UUID) (*entity.Zone, error) {
+	const op = "ZoneRepository.GetByID"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", id.String()))
+
+	z, err := scanZone(r.db.QueryRow(`SELECT `+zoneColumns+` FROM zones WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrZoneNotFound
+		}
+		l.Error("failed to scan zone", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return z, nil
+}
+
+func (r *zoneRepository) GetAll() ([]*entity.Zone, error) {
+	const op = "ZoneRepository.GetAll"
+	l := r.logger.With(zap.String("op", op))
+
+	rows, err := r.db.Query(`SELECT ` + zoneColumns + ` FROM zones ORDER BY name`)
+	if err != nil {
+		l.Error("failed to query zones", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var list []*entity.Zone
+	for rows.Next() {
+		z, err := scanZone(rows)
+		if err != nil {
+			l.Error("failed to scan zone row", zap.Error(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		list = append(list, z)
+	}
+	if err := rows.Err(); err != nil {
+		l.Error("rows iteration error", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return list, nil
+}
+
+func (r *zoneRepository) Update(z *entity.Zone) error {
+	const op = "ZoneRepository.Update"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", z.ID.String()))
+
+	z.UpdatedAt = time.Now().UTC()
+	const query = `
+		UPDATE zones SET
+			name       = $2,
+			boundary   = ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($3), 4326)),
+			active     = $4,
+			updated_at = $5
+		WHERE id = $1
+	`
+	res, err := r.db.Exec(query, z.ID, z.Name, string(z.Boundary), z.Active, z.UpdatedAt)
+	if err != nil {
+		l.Error("failed to update zone", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return ErrZoneNotFound
+	}
+	l.Info("zone updated")
+	return nil
+}
+
+func (r *zoneRepository) Delete(id uuid.UUID) error {
+	const op = "ZoneRepository.Delete"
+	l := r.logger.With(zap.String("op", op), zap.String("zone_id", id.String()))
+
+	res, err := r.db.Exec("DELETE FROM zones WHERE id = $1", id)
+	if err != nil {
+		l.Error("failed to delete zone", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return ErrZoneNotFound
+	}
+	l.Info("zone deleted")
+	return nil
+}
+
+func (r *zoneRepository) FindByPoint(lat, lon float64) (*entity.Zone, error) {
+	const op = "ZoneRepository.FindByPoint"
+	l := r.logger.With(zap.String("op", op))
+
+	// при пересечении зон берём самую маленькую — она точнее
+	query := `SELECT ` + zoneColumns + `
+		FROM zones
+		WHERE active
+		  AND ST_Covers(boundary, ST_SetSRID(ST_MakePoint($1, $2), 4326))
+		ORDER BY ST_Area(boundary)
+		LIMIT 1
+	`
+	z, err := scanZone(r.db.QueryRow(query, lon, lat))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrZoneNotFound
+		}
+		l.Error("failed to scan zone", zap.Error(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return z, nil
+}
+
+// SetCourierZones заменяет набор домашних зон курьера.
+func (r *zoneRepository) SetCourierZones(courierID uuid.UUID, zoneIDs []uuid.UUID) error {
+	const op = "ZoneRepository.SetCourierZones"
+	l := r.logger.With(zap.String("op", op), zap.String("courier_id", courierID.String()))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		l.Error("failed to begin tx", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM courier_zones WHERE courier_id = $1", courierID); err != nil {
+		l.Error("failed to clear zones", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, zoneID := range zoneIDs {
+		if _, err := tx.Exec("INSERT INTO courier_zones (courier_id, zone_id) VALUES ($1, $2)", courierID, zoneID); err != nil {
+			l.Error("failed to insert zone", zap.String("zone_id", zoneID.String()), zap.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		l.Error("failed to commit", zap.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.Info("courier zones set", zap.Int("count", len(zoneIDs)))
+	return nil
+}
@@ -0,0 +1,285 @@
+// Package route строит порядок объезда точек курьера: вариант задачи
+// коммивояжёра с ограничением «забрать раньше, чем доставить».
+// Начальное решение — ближайший сосед, затем улучшение 2-opt и or-opt.
+package route
+
+import (
+	"errors"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+
+	"github.com/google/uuid"
+)
+
+// maxPasses ограничивает число проходов локального поиска.
+const maxPasses = 50
+
+// DistanceMatrix считает попарные расстояния (или время в пути) между
+// точками. Позволяет подменить haversine на матрицу от внешнего сервиса.
+type DistanceMatrix interface {
+	Matrix(points []entity.Coordinates) ([][]float64, error)
+}
+
+// HaversineMatrix — матрица расстояний по большому кругу в метрах.
+type HaversineMatrix struct{}
+
+func (HaversineMatrix) Matrix(points []entity.Coordinates) ([][]float64, error) {
+	m := make([][]float64, len(points))
+	for i := range points {
+		m[i] = make([]float64, len(points))
+		for j := range points {
+			if i != j {
+				m[i][j] = geo.Haversine(points[i], points[j])
+			}
+		}
+	}
+	return m, nil
+}
+
+var ErrBadMatrix = errors.New("distance matrix has wrong dimensions")
+
+type Planner struct {
+	matrix DistanceMatrix
+}
+
+func NewPlanner(matrix DistanceMatrix) *Planner {
+	if matrix == nil {
+		matrix = HaversineMatrix{}
+	}
+	return &Planner{matrix: matrix}
+}
+
+// Plan — упорядоченные точки и длины участков: Legs[i] — путь до Stops[i]
+// от предыдущей точки (для первой — от старта).
+type Plan struct {
+	Stops []entity.OrderStop
+	Legs  []float64
+	Total float64
+}
+
+// Plan возвращает порядок объезда stops из start. Точка DROPOFF заказа
+// ставится только после всех его PICKUP из того же набора.
+func (p *Planner) Plan(start entity.Coordinates, stops []entity.OrderStop) (*Plan, error) {
+	if len(stops) == 0 {
+		return &Plan{}, nil
+	}
+	d, err := p.distances(start, stops)
+	if err != nil {
+		return nil, err
+	}
+	pr := newProblem(d, stops)
+
+	seq := pr.nearestNeighbor()
+	for pass := 0; pass < maxPasses; pass++ {
+		improved := pr.twoOpt(seq)
+		if pr.orOpt(seq) {
+			improved = true
+		}
+		if !improved {
+			break
+		}
+	}
+	return pr.plan(seq, stops), nil
+}
+
+// Evaluate считает участки для уже заданного порядка stops.
+func (p *Planner) Evaluate(start entity.Coordinates, stops []entity.OrderStop) (*Plan, error) {
+	if len(stops) == 0 {
+		return &Plan{}, nil
+	}
+	d, err := p.distances(start, stops)
+	if err != nil {
+		return nil, err
+	}
+	seq := make([]int, len(stops))
+	for i := range seq {
+		seq[i] = i
+	}
+	return newProblem(d, stops).plan(seq, stops), nil
+}
+
+func (p *Planner) distances(start entity.Coordinates, stops []entity.OrderStop) ([][]float64, error) {
+	points := make([]entity.Coordinates, 0, len(stops)+1)
+	points = append(points, start)
+	for _, s := range stops {
+		points = append(points, s.Location)
+	}
+	d, err := p.matrix.Matrix(points)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != len(points) {
+		return nil, ErrBadMatrix
+	}
+	for _, row := range d {
+		if len(row) != len(points) {
+			return nil, ErrBadMatrix
+		}
+	}
+	return d, nil
+}
+
+// problem работает с индексами точек; в матрице индекс 0 — старт,
+// точка i лежит в строке i+1.
+type problem struct {
+	d       [][]float64
+	order   []uuid.UUID
+	pickup  []bool
+	pickups map[uuid.UUID]int
+}
+
+func newProblem(d [][]float64, stops []entity.OrderStop) *problem {
+	pr := &problem{
+		d:       d,
+		order:   make([]uuid.UUID, len(stops)),
+		pickup:  make([]bool, len(stops)),
+		pickups: make(map[uuid.UUID]int),
+	}
+	for i, s := range stops {
+		pr.order[i] = s.OrderID
+		pr.pickup[i] = s.Kind == entity.StopPickup
+		if pr.pickup[i] {
+			pr.pickups[s.OrderID]++
+		}
+	}
+	return pr
+}
+
+func (pr *problem) dist(from, to int) float64 {
+	return pr.d[from+1][to+1]
+}
+
+func (pr *problem) cost(seq []int) float64 {
+	total := pr.d[0][seq[0]+1]
+	for k := 1; k < len(seq); k++ {
+		total += pr.dist(seq[k-1], seq[k])
+	}
+	return total
+}
+
+func (pr *problem) feasible(seq []int) bool {
+	left := make(map[uuid.UUID]int, len(pr.pickups))
+	for id, n := range pr.pickups {
+		left[id] = n
+	}
+	for _, i := range seq {
+		if pr.pickup[i] {
+			left[pr.order[i]]--
+		} else if left[pr.order[i]] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (pr *problem) nearestNeighbor() []int {
+	n := len(pr.order)
+	left := make(map[uuid.UUID]int, len(pr.pickups))
+	for id, c := range pr.pickups {
+		left[id] = c
+	}
+	visited := make([]bool, n)
+	seq := make([]int, 0, n)
+	cur := -1
+	for len(seq) < n {
+		next, best := -1, 0.0
+		for i := 0; i < n; i++ {
+			if visited[i] || (!pr.pickup[i] && left[pr.order[i]] > 0) {
+				continue
+			}
+			var dist float64
+			if cur < 0 {
+				dist = pr.d[0][i+1]
+			} else {
+				dist = pr.dist(cur, i)
+			}
+			if next < 0 || dist < best {
+				next, best = i, dist
+			}
+		}
+		visited[next] = true
+		if pr.pickup[next] {
+			left[pr.order[next]]--
+		}
+		seq = append(seq, next)
+		cur = next
+	}
+	return seq
+}
+
+// twoOpt разворачивает отрезки маршрута, пока это сокращает путь.
+func (pr *problem) twoOpt(seq []int) bool {
+	improved := false
+	best := pr.cost(seq)
+	for i := 0; i < len(seq)-1; i++ {
+		for j := i + 1; j < len(seq); j++ {
+			reverse(seq, i, j)
+			if c := pr.cost(seq); c < best-1e-9 && pr.feasible(seq) {
+				best, improved = c, true
+				continue
+			}
+			reverse(seq, i, j)
+		}
+	}
+	return improved
+}
+
+// orOpt переносит отрезки длиной 1–3 точки на другое место маршрута.
+func (pr *problem) orOpt(seq []int) bool {
+	improved := false
+	best := pr.cost(seq)
+	for size := 1; size <= 3 && size < len(seq); size++ {
+		for i := 0; i+size <= len(seq); i++ {
+			for j := 0; j <= len(seq)-size; j++ {
+				if j == i {
+					continue
+				}
+				cand := moveSegment(seq, i, size, j)
+				if c := pr.cost(cand); c < best-1e-9 && pr.feasible(cand) {
+					copy(seq, cand)
+					best, improved = c, true
+				}
+			}
+		}
+	}
+	return improved
+}
+
+func (pr *problem) plan(seq []int, stops []entity.OrderStop) *Plan {
+	ret := &Plan{
+		Stops: make([]entity.OrderStop, len(seq)),
+		Legs:  make([]float64, len(seq)),
+	}
+	for k, i := range seq {
+		ret.Stops[k] = stops[i]
+		if k == 0 {
+			ret.Legs[k] = pr.d[0][i+1]
+		} else {
+			ret.Legs[k] = pr.dist(seq[k-1], i)
+		}
+		ret.Total += ret.Legs[k]
+	}
+	return ret
+}
+
+func reverse(seq []int, i, j int) {
+	for ; i < j; i, j = i+1, j-1 {
+		seq[i], seq[j] = seq[j], seq[i]
+	}
+}
+
+// moveSegment возвращает копию seq, где отрезок [i, i+size) вставлен так,
+// чтобы начинаться с позиции j в итоговой последовательности.
+func moveSegment(seq []int, i, size, j int) []int {
+	seg := append([]int{}, seq[i:i+size]...)
+	rest := make([]int, 0, len(seq)-size)
+	rest = append(rest, seq[:i]...)
+	rest = append(rest, seq[i+size:]...)
+
+	ret := make([]int, 0, len(seq))
+	ret = append(ret, rest[:j]...)
+	ret = append(ret, seg...)
+	ret = append(ret, rest[j:]...)
+	return ret
+}
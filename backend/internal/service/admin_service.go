@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrSelfLockout       = errors.New("admin cannot revoke their own users:manage permission")
+)
+
+type AdminService interface {
+	CreateAdmin(ctx context.Context, email, password string, permissions entity.Permissions) (*entity.Admin, error)
+	GetAdmin(ctx context.Context, id uuid.UUID) (*entity.Admin, error)
+	ListAdmins(ctx context.Context) ([]*entity.Admin, error)
+	Grant(ctx context.Context, id uuid.UUID, p entity.Permission) (*entity.Admin, error)
+	// Revoke отзывает право; actorID — администратор, выполняющий запрос.
+	// Отозвать у себя управление пользователями нельзя, чтобы не остаться
+	// без администратора, способного выдавать права.
+	Revoke(ctx context.Context, actorID, id uuid.UUID, p entity.Permission) (*entity.Admin, error)
+	// Permissions читается middleware на каждый запрос, поэтому отзыв
+	// права действует сразу, без перевыпуска токена.
+	Permissions(ctx context.Context, id uuid.UUID) (entity.Permissions, error)
+}
+
+type adminService struct {
+	repo   repository.AdminRepository
+	logger *zap.Logger
+}
+
+func NewAdminService(repo repository.AdminRepository, logger *zap.Logger) AdminService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &adminService{repo: repo, logger: logger}
+}
+
+func (s *adminService) CreateAdmin(ctx context.Context, email, password string, permissions entity.Permissions) (*entity.Admin, error) {
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	u := &entity.User{
+		Email:        email,
+		PasswordHash: string(hashed),
+		Role:         entity.RoleAdmin,
+	}
+	if err := s.repo.Create(u, permissions); err != nil {
+		return nil, err
+	}
+	return &entity.Admin{UserID: u.ID, Email: u.Email, Permissions: permissions}, nil
+}
+
+func (s *adminService) GetAdmin(ctx context.Context, id uuid.UUID) (*entity.Admin, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *adminService) ListAdmins(ctx context.Context) ([]*entity.Admin, error) {
+	return s.repo.List()
+}
+
+func (s *adminService) Grant(ctx context.Context, id uuid.UUID, p entity.Permission) (*entity.Admin, error) {
+	if !p.Valid() {
+		return nil, ErrUnknownPermission
+	}
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, have := range a.Permissions {
+		if have == p {
+			return a, nil
+		}
+	}
+	a.Permissions = append(a.Permissions, p)
+	if err := s.repo.SetPermissions(id, a.Permissions); err != nil {
+		return nil, err
+	}
+	s.logger.Info("permission granted", zap.String("admin_id", id.String()), zap.String("permission", string(p)))
+	return a, nil
+}
+
+func (s *adminService) Revoke(ctx context.Context, actorID, id uuid.UUID, p entity.Permission) (*entity.Admin, error) {
+	if !p.Valid() {
+		return nil, ErrUnknownPermission
+	}
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	left := entity.Permissions{}
+	for _, have := range a.Permissions {
+		if have != p {
+			left = append(left, have)
+		}
+	}
+	if len(left) == len(a.Permissions) {
+		return a, nil
+	}
+	if actorID == id && !left.Has(entity.PermUsersManage) {
+		return nil, ErrSelfLockout
+	}
+	if err := s.repo.SetPermissions(id, left); err != nil {
+		return nil, err
+	}
+	a.Permissions = left
+	s.logger.Info("permission revoked", zap.String("admin_id", id.String()), zap.String("permission", string(p)))
+	return a, nil
+}
+
+func (s *adminService) Permissions(ctx context.Context, id uuid.UUID) (entity.Permissions, error) {
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return a.Permissions, nil
+}
+
+// normalizePermissions проверяет права и убирает повторы.
+func normalizePermissions(ps entity.Permissions) (entity.Permissions, error) {
+	ret := entity.Permissions{}
+	seen := make(map[entity.Permission]bool, len(ps))
+	for _, p := range ps {
+		if !p.Valid() {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, e *entity.AuditEvent) error
+	List(ctx context.Context, f repository.AuditFilter) ([]*entity.AuditEvent, error)
+}
+
+type auditService struct {
+	repo   repository.AuditRepository
+	logger *zap.Logger
+}
+
+func NewAuditService(repo repository.AuditRepository, logger *zap.Logger) AuditService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &auditService{repo: repo, logger: logger}
+}
+
+func (s *auditService) Record(ctx context.Context, e *entity.AuditEvent) error {
+	return s.repo.Create(e)
+}
+
+func (s *auditService) List(ctx context.Context, f repository.AuditFilter) ([]*entity.AuditEvent, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	return s.repo.List(f)
+}
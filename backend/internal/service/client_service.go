@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidProfile = errors.New("client name is required")
+	ErrInvalidAddress = errors.New("address must have a label, text and valid coordinates")
+)
+
+type ClientService interface {
+	GetProfile(ctx context.Context, clientID uuid.UUID) (*entity.Client, error)
+	UpdateProfile(ctx context.Context, c *entity.Client) (*entity.Client, error)
+
+	ListAddresses(ctx context.Context, clientID uuid.UUID) ([]*entity.ClientAddress, error)
+	// GetAddress возвращает адрес, только если он принадлежит клиенту;
+	// чужой адрес неотличим от несуществующего.
+	GetAddress(ctx context.Context, clientID, id uuid.UUID) (*entity.ClientAddress, error)
+	CreateAddress(ctx context.Context, a *entity.ClientAddress) error
+	UpdateAddress(ctx context.Context, a *entity.ClientAddress) error
+	DeleteAddress(ctx context.Context, clientID, id uuid.UUID) error
+}
+
+type clientService struct {
+	repo   repository.ClientRepository
+	logger *zap.Logger
+}
+
+func NewClientService(repo repository.ClientRepository, logger *zap.Logger) ClientService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &clientService{repo: repo, logger: logger}
+}
+
+func (s *clientService) GetProfile(ctx context.Context, clientID uuid.UUID) (*entity.Client, error) {
+	return s.repo.GetProfile(clientID)
+}
+
+func (s *clientService) UpdateProfile(ctx context.Context, c *entity.Client) (*entity.Client, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return nil, ErrInvalidProfile
+	}
+	// профиль есть только у пользователей с ролью CLIENT
+	profile, err := s.repo.GetProfile(c.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get profile: %w", err)
+	}
+	if err := s.repo.SaveProfile(c); err != nil {
+		return nil, fmt.Errorf("save profile: %w", err)
+	}
+	c.Email = profile.Email
+	return c, nil
+}
+
+func (s *clientService) ListAddresses(ctx context.Context, clientID uuid.UUID) ([]*entity.ClientAddress, error) {
+	return s.repo.ListAddresses(clientID)
+}
+
+func (s *clientService) GetAddress(ctx context.Context, clientID, id uuid.UUID) (*entity.ClientAddress, error) {
+	a, err := s.repo.GetAddress(id)
+	if err != nil {
+		return nil, err
+	}
+	if a.ClientID != clientID {
+		return nil, repository.ErrAddressNotFound
+	}
+	return a, nil
+}
+
+func (s *clientService) CreateAddress(ctx context.Context, a *entity.ClientAddress) error {
+	if err := checkAddress(a); err != nil {
+		return err
+	}
+	return s.repo.CreateAddress(a)
+}
+
+func (s *clientService) UpdateAddress(ctx context.Context, a *entity.ClientAddress) error {
+	if err := checkAddress(a); err != nil {
+		return err
+	}
+	prev, err := s.GetAddress(ctx, a.ClientID, a.ID)
+	if err != nil {
+		return err
+	}
+	a.CreatedAt = prev.CreatedAt
+	return s.repo.UpdateAddress(a)
+}
+
+func (s *clientService) DeleteAddress(ctx context.Context, clientID, id uuid.UUID) error {
+	if _, err := s.GetAddress(ctx, clientID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteAddress(id)
+}
+
+func checkAddress(a *entity.ClientAddress) error {
+	a.Label = strings.TrimSpace(a.Label)
+	a.Address = strings.TrimSpace(a.Address)
+	if a.Label == "" || a.Address == "" {
+		return ErrInvalidAddress
+	}
+	if a.Location.Latitude < -90 || a.Location.Latitude > 90 ||
+		a.Location.Longitude < -180 || a.Location.Longitude > 180 {
+		return ErrInvalidAddress
+	}
+	return nil
+}
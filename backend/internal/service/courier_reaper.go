@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// CourierReaper переводит в OFFLINE курьеров, от которых давно не было
+// heartbeat, и возвращает в очередь их ещё не начатые заказы.
+type CourierReaper struct {
+	couriers     repository.CourierRepository
+	orders       OrderService
+	offlineAfter time.Duration
+	interval     time.Duration
+	logger       *zap.Logger
+}
+
+func NewCourierReaper(
+	couriers repository.CourierRepository,
+	orders OrderService,
+	offlineAfter, interval time.Duration,
+	logger *zap.Logger,
+) *CourierReaper {
+	return &CourierReaper{
+		couriers:     couriers,
+		orders:       orders,
+		offlineAfter: offlineAfter,
+		interval:     interval,
+		logger:       logger,
+	}
+}
+
+// Run работает до отмены ctx.
+func (r *CourierReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.ReapOnce(ctx, now); err != nil {
+				r.logger.Error("Courier reaper failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *CourierReaper) ReapOnce(ctx context.Context, now time.Time) error {
+	ids, err := r.couriers.MarkStaleOffline(ctx, now.Add(-r.offlineAfter))
+	if err != nil {
+		return fmt.Errorf("mark stale couriers: %w", err)
+	}
+	for _, id := range ids {
+		requeued, err := r.orders.RequeueCourierOrders(ctx, id)
+		if err != nil {
+			// остальных курьеров всё равно обрабатываем
+			r.logger.Error("Failed to requeue orders", zap.String("courier_id", id.String()), zap.Error(err))
+			continue
+		}
+		r.logger.Info("Courier went offline",
+			zap.String("courier_id", id.String()),
+			zap.Int("requeued_orders", len(requeued)),
+		)
+	}
+	return nil
+}
@@ -1,36 +1,105 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"backend/internal/entity"
 	"backend/internal/repository"
+	"backend/internal/spoofcheck"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrLocationRejected — координаты не прошли проверку правдоподобия.
+	ErrLocationRejected = errors.New("location rejected as implausible")
+	ErrInvalidVehicle   = errors.New("vehicle type must be one of FOOT, BIKE, SCOOTER, CAR, VAN")
+	// ErrCourierSuspended — курьер заблокирован или деактивирован и не
+	// может выходить на линию.
+	ErrCourierSuspended = errors.New("courier account is not active")
+	// ErrRetentionNotElapsed — данные курьера ещё нужно хранить.
+	ErrRetentionNotElapsed = errors.New("courier can be deleted only after deactivation and retention period")
+	ErrCourierHasOrders    = errors.New("courier still has active orders")
 )
 
 type CourierService interface {
-	GetCourierByID(id uuid.UUID) (*entity.Courier, error)
-	UpdateCourierStatus(id uuid.UUID, status entity.CourierStatus) error
-	UpdateCourierLocation(id uuid.UUID, location *entity.Coordinates) error
-	FindNearestAvailable(latitude, longitude float64, radius float64) ([]*entity.Courier, error)
+	GetCourierByID(ctx context.Context, id uuid.UUID) (*entity.Courier, error)
+	// UpdateCourierStatus и SetVehicle: version — версия курьера, которую
+	// видел клиент (If-Match); если курьер с тех пор изменился, возвращается
+	// repository.ErrVersionConflict. Нулевая версия не проверяется.
+	UpdateCourierStatus(ctx context.Context, id uuid.UUID, status entity.CourierStatus, version int) error
+	// UpdateCourierLocation: recordedAt — время фикса на устройстве,
+	// нулевое значение означает «сейчас».
+	UpdateCourierLocation(ctx context.Context, id uuid.UUID, location *entity.Coordinates, recordedAt time.Time) error
+	FindNearestAvailable(ctx context.Context, latitude, longitude float64, radius float64, f repository.CourierFilter) ([]*entity.Courier, error)
+	ListLocationAnomalies(ctx context.Context, f repository.AnomalyFilter) ([]*entity.LocationAnomaly, error)
+	// Heartbeat отмечает, что приложение курьера на связи.
+	Heartbeat(ctx context.Context, id uuid.UUID) error
+	// SetVehicle меняет транспорт курьера; нулевая вместимость заменяется
+	// типовой для этого транспорта.
+	SetVehicle(ctx context.Context, id uuid.UUID, vehicle entity.VehicleType, maxWeightKg, maxVolumeL float64, version int) (*entity.Courier, error)
+
+	// CreateCourier заводит учётную запись курьера; новый курьер OFFLINE,
+	// пока не выйдет на смену.
+	CreateCourier(ctx context.Context, email, password, name string, vehicle entity.VehicleType, capacity int) (*entity.Courier, error)
+	ListCouriers(ctx context.Context, f repository.CourierListFilter) ([]*entity.Courier, error)
+	// SuspendCourier и DeactivateCourier снимают курьера с линии и
+	// возвращают в очередь его не начатые заказы.
+	SuspendCourier(ctx context.Context, id uuid.UUID, reason string) (*entity.Courier, error)
+	DeactivateCourier(ctx context.Context, id uuid.UUID, reason string) (*entity.Courier, error)
+	ReactivateCourier(ctx context.Context, id uuid.UUID) (*entity.Courier, error)
+	// DeleteCourier удаляет курьера безвозвратно — только деактивированного
+	// дольше срока хранения и без активных заказов.
+	DeleteCourier(ctx context.Context, id uuid.UUID) error
+}
+
+type CourierOptions struct {
+	Limits spoofcheck.Limits
+	// RejectAnomalies — не принимать подозрительные координаты; иначе они
+	// сохраняются и только помечаются для разбора.
+	RejectAnomalies bool
+	// Retention — сколько хранить данные деактивированного курьера.
+	Retention time.Duration
 }
 
 type courierService struct {
-	repo   repository.CourierRepository
-	logger *zap.Logger
+	repo      repository.CourierRepository
+	anomalies repository.LocationAnomalyRepository
+	etas      EtaService
+	geofences GeofenceService
+	orders    OrderService
+	opts      CourierOptions
+	logger    *zap.Logger
 }
 
-func NewCourierService(repo repository.CourierRepository, logger *zap.Logger) CourierService {
+func NewCourierService(
+	repo repository.CourierRepository,
+	anomalies repository.LocationAnomalyRepository,
+	etas EtaService,
+	geofences GeofenceService,
+	orders OrderService,
+	opts CourierOptions,
+	logger *zap.Logger,
+) CourierService {
 	return &courierService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		anomalies: anomalies,
+		etas:      etas,
+		geofences: geofences,
+		orders:    orders,
+		opts:      opts,
+		logger:    logger,
 	}
 }
 
-func (s *courierService) GetCourierByID(id uuid.UUID) (*entity.Courier, error) {
+func (s *courierService) GetCourierByID(ctx context.Context, id uuid.UUID) (*entity.Courier, error) {
 	s.logger.Info("Fetching courier", zap.String("courier_id", id.String()))
-	courier, err := s.repo.GetByID(id)
+	courier, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Failed to get courier", zap.String("courier_id", id.String()), zap.Error(err))
 		return nil, fmt.Errorf("failed to get courier: %w", err)
@@ -38,22 +107,28 @@ func (s *courierService) GetCourierByID(id uuid.UUID) (*entity.Courier, error) {
 	return courier, nil
 }
 
-func (s *courierService) UpdateCourierStatus(id uuid.UUID, status entity.CourierStatus) error {
+func (s *courierService) UpdateCourierStatus(ctx context.Context, id uuid.UUID, status entity.CourierStatus, version int) error {
 	s.logger.Info("Updating courier status", zap.String("courier_id", id.String()), zap.String("status", string(status)))
-	courier, err := s.repo.GetByID(id)
+	courier, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Courier not found for status update", zap.String("courier_id", id.String()), zap.Error(err))
 		return fmt.Errorf("courier not found: %w", err)
 	}
+	if version != 0 && courier.Version != version {
+		return repository.ErrVersionConflict
+	}
+	if status != entity.CourierStatusOffline && courier.AccountStatus != entity.AccountActive {
+		return ErrCourierSuspended
+	}
 	courier.Status = status
-	if err := s.repo.Update(courier); err != nil {
+	if err := s.repo.Update(ctx, courier); err != nil {
 		s.logger.Error("Failed to update courier status", zap.String("courier_id", id.String()), zap.Error(err))
 		return fmt.Errorf("failed to update courier status: %w", err)
 	}
 	return nil
 }
 
-func (s *courierService) UpdateCourierLocation(id uuid.UUID, location *entity.Coordinates) error {
+func (s *courierService) UpdateCourierLocation(ctx context.Context, id uuid.UUID, location *entity.Coordinates, recordedAt time.Time) error {
 	logLat, logLon := float64(0), float64(0)
     if location != nil {
         logLat = location.Latitude
@@ -61,23 +136,213 @@ func (s *courierService) UpdateCourierLocation(id uuid.UUID, location *entity.Co
     }
 	s.logger.Info("Updating courier location", zap.String("courier_id", id.String()), zap.Float64("lat", logLat), zap.Float64("lon", logLon))
 
-	courier, err := s.repo.GetByID(id)
-	if err != nil {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
 		s.logger.Error("Courier not found for location update", zap.String("courier_id", id.String()), zap.Error(err))
 		return fmt.Errorf("courier not found: %w", err)
 	}
-	courier.Location = location
-	if err := s.repo.Update(courier); err != nil {
+
+	var fix *entity.LocationFix
+	if location != nil {
+		if recordedAt.IsZero() {
+			recordedAt = time.Now()
+		}
+		fix = &entity.LocationFix{CourierID: id, Location: *location, RecordedAt: recordedAt.UTC()}
+		if err := s.checkLocation(ctx, fix); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.SetLocation(ctx, id, location); err != nil {
 		s.logger.Error("Failed to update courier location", zap.String("courier_id", id.String()), zap.Error(err))
 		return fmt.Errorf("failed to update courier location: %w", err)
 	}
+
+	// история и ETA вторичны: ошибки логируем, но координаты уже сохранены
+	if err := s.repo.Touch(ctx, id, time.Now().UTC()); err != nil {
+		s.logger.Warn("Failed to update last seen", zap.String("courier_id", id.String()), zap.Error(err))
+	}
+	if fix != nil {
+		if err := s.repo.RecordLocation(ctx, fix); err != nil {
+			s.logger.Warn("Failed to record location history", zap.String("courier_id", id.String()), zap.Error(err))
+		}
+		if err := s.geofences.Track(ctx, id, fix.Location, fix.RecordedAt); err != nil {
+			s.logger.Warn("Failed to check geofences", zap.String("courier_id", id.String()), zap.Error(err))
+		}
+	}
+	if err := s.etas.Refresh(ctx, id); err != nil {
+		s.logger.Warn("Failed to refresh ETA", zap.String("courier_id", id.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// checkLocation сравнивает фикс с последним принятым. Подозрительный фикс
+// всегда сохраняется как аномалия; ErrLocationRejected возвращается только
+// в режиме RejectAnomalies.
+func (s *courierService) checkLocation(ctx context.Context, fix *entity.LocationFix) error {
+	prev, err := s.repo.LastLocation(ctx, fix.CourierID)
+	if err != nil {
+		return fmt.Errorf("failed to get last location: %w", err)
+	}
+	now := time.Now().UTC()
+	res := spoofcheck.Check(prev, *fix, now, s.opts.Limits)
+	if !res.Suspicious() {
+		return nil
+	}
+
+	anomaly := &entity.LocationAnomaly{
+		CourierID:  fix.CourierID,
+		Kinds:      res.Kinds,
+		Location:   fix.Location,
+		SpeedMps:   res.SpeedMps,
+		RecordedAt: fix.RecordedAt,
+		Rejected:   s.opts.RejectAnomalies,
+	}
+	if prev != nil {
+		anomaly.PrevLocation = &prev.Location
+	}
+	if err := s.anomalies.Create(anomaly); err != nil {
+		s.logger.Error("Failed to record location anomaly", zap.String("courier_id", fix.CourierID.String()), zap.Error(err))
+		return fmt.Errorf("failed to record location anomaly: %w", err)
+	}
+
+	if s.opts.RejectAnomalies {
+		return fmt.Errorf("%w: %v", ErrLocationRejected, res.Kinds)
+	}
+	// принятый фикс «из будущего» в историю идёт со временем сервера:
+	// иначе все следующие фиксы окажутся раньше него
+	if slices.Contains(res.Kinds, entity.AnomalyFutureTimestamp) {
+		fix.RecordedAt = now
+	}
+	return nil
+}
+
+func (s *courierService) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Touch(ctx, id, time.Now().UTC()); err != nil {
+		s.logger.Error("Failed to record heartbeat", zap.String("courier_id", id.String()), zap.Error(err))
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	return nil
+}
+
+func (s *courierService) SetVehicle(ctx context.Context, id uuid.UUID, vehicle entity.VehicleType, maxWeightKg, maxVolumeL float64, version int) (*entity.Courier, error) {
+	spec, ok := vehicle.Spec()
+	if !ok {
+		return nil, ErrInvalidVehicle
+	}
+	courier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("courier not found: %w", err)
+	}
+	if version != 0 && courier.Version != version {
+		return nil, repository.ErrVersionConflict
+	}
+	if maxWeightKg <= 0 {
+		maxWeightKg = spec.MaxWeightKg
+	}
+	if maxVolumeL <= 0 {
+		maxVolumeL = spec.MaxVolumeL
+	}
+	courier.VehicleType = vehicle
+	courier.MaxWeightKg = maxWeightKg
+	courier.MaxVolumeL = maxVolumeL
+	if err := s.repo.Update(ctx, courier); err != nil {
+		s.logger.Error("Failed to update courier vehicle", zap.String("courier_id", id.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to update courier vehicle: %w", err)
+	}
+	return courier, nil
+}
+
+func (s *courierService) CreateCourier(ctx context.Context, email, password, name string, vehicle entity.VehicleType, capacity int) (*entity.Courier, error) {
+	if vehicle == "" {
+		vehicle = entity.VehicleBike
+	}
+	if !vehicle.Valid() {
+		return nil, ErrInvalidVehicle
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &entity.User{Email: email, PasswordHash: string(hashed), Role: entity.RoleCourier}
+	courier := &entity.Courier{
+		Name:        name,
+		Status:      entity.CourierStatusOffline,
+		Capacity:    capacity,
+		VehicleType: vehicle,
+	}
+	if err := s.repo.Create(ctx, user, courier); err != nil {
+		s.logger.Error("Failed to create courier", zap.String("email", email), zap.Error(err))
+		return nil, fmt.Errorf("failed to create courier: %w", err)
+	}
+	return courier, nil
+}
+
+func (s *courierService) ListCouriers(ctx context.Context, f repository.CourierListFilter) ([]*entity.Courier, error) {
+	return s.repo.List(ctx, f)
+}
+
+func (s *courierService) SuspendCourier(ctx context.Context, id uuid.UUID, reason string) (*entity.Courier, error) {
+	return s.takeOffline(ctx, id, entity.AccountSuspended, reason)
+}
+
+func (s *courierService) DeactivateCourier(ctx context.Context, id uuid.UUID, reason string) (*entity.Courier, error) {
+	return s.takeOffline(ctx, id, entity.AccountDeactivated, reason)
+}
+
+func (s *courierService) takeOffline(ctx context.Context, id uuid.UUID, status entity.AccountStatus, reason string) (*entity.Courier, error) {
+	if err := s.repo.SetAccountStatus(ctx, id, status, reason, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to change account status: %w", err)
+	}
+	if err := s.UpdateCourierStatus(ctx, id, entity.CourierStatusOffline, 0); err != nil {
+		return nil, err
+	}
+	if _, err := s.orders.RequeueCourierOrders(ctx, id); err != nil {
+		s.logger.Error("Failed to requeue orders", zap.String("courier_id", id.String()), zap.Error(err))
+	}
+	s.logger.Info("Courier taken off the line",
+		zap.String("courier_id", id.String()),
+		zap.String("account_status", string(status)),
+		zap.String("reason", reason),
+	)
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *courierService) ReactivateCourier(ctx context.Context, id uuid.UUID) (*entity.Courier, error) {
+	if err := s.repo.SetAccountStatus(ctx, id, entity.AccountActive, "", time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to change account status: %w", err)
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *courierService) DeleteCourier(ctx context.Context, id uuid.UUID) error {
+	courier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if courier.AccountStatus != entity.AccountDeactivated ||
+		courier.AccountStatusChangedAt == nil ||
+		time.Since(*courier.AccountStatusChangedAt) < s.opts.Retention {
+		return ErrRetentionNotElapsed
+	}
+	if courier.ActiveOrders > 0 {
+		return ErrCourierHasOrders
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete courier", zap.String("courier_id", id.String()), zap.Error(err))
+		return fmt.Errorf("failed to delete courier: %w", err)
+	}
 	return nil
 }
 
-func (s *courierService) FindNearestAvailable(latitude, longitude float64, radius float64) ([]*entity.Courier, error) {
+func (s *courierService) ListLocationAnomalies(ctx context.Context, f repository.AnomalyFilter) ([]*entity.LocationAnomaly, error) {
+	return s.anomalies.List(f)
+}
+
+func (s *courierService) FindNearestAvailable(ctx context.Context, latitude, longitude float64, radius float64, f repository.CourierFilter) ([]*entity.Courier, error) {
     s.logger.Info("Finding nearest available couriers", zap.Float64("lat", latitude), zap.Float64("lon", longitude), zap.Float64("radius", radius))
 
-    couriers, err := s.repo.FindNearestAvailable(latitude, longitude, radius)
+    couriers, err := s.repo.FindNearestAvailable(ctx, latitude, longitude, radius, f)
     if err != nil {
         s.logger.Error("Failed to find nearest available couriers from repository", zap.Error(err))
         return nil, fmt.Errorf("failed to find nearest available couriers: %w", err)
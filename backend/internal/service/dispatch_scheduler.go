@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// DispatchScheduler отпускает в автоназначение заказы с окном доставки,
+// когда до окна остаётся DispatchLeadTime, и заранее помечает заказы,
+// которые в окно не успевают.
+type DispatchScheduler struct {
+	orderRepo repository.OrderRepository
+	orders    OrderService
+	lead      time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+}
+
+func NewDispatchScheduler(
+	orderRepo repository.OrderRepository,
+	orders OrderService,
+	lead, interval time.Duration,
+	logger *zap.Logger,
+) *DispatchScheduler {
+	return &DispatchScheduler{
+		orderRepo: orderRepo,
+		orders:    orders,
+		lead:      lead,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run работает до отмены ctx.
+func (d *DispatchScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := d.RunOnce(ctx, now); err != nil {
+				d.logger.Error("Dispatch scheduler failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (d *DispatchScheduler) RunOnce(ctx context.Context, now time.Time) error {
+	due, err := d.orderRepo.ListDueForDispatch(ctx, now)
+	if err != nil {
+		return fmt.Errorf("list due orders: %w", err)
+	}
+	for _, order := range due {
+		// без свободного курьера заказ дождётся следующего прохода
+		if err := d.orders.AssignCourierToOrder(ctx, order.ID); err != nil && !errors.Is(err, ErrNoCourierAvailable) {
+			d.logger.Error("Failed to dispatch scheduled order", zap.String("order_id", order.ID.String()), zap.Error(err))
+		}
+	}
+
+	late, err := d.orderRepo.ListWindowAtRisk(ctx, now, d.lead)
+	if err != nil {
+		return fmt.Errorf("list orders at risk: %w", err)
+	}
+	for _, order := range late {
+		if _, err := d.orderRepo.MarkWindowAtRisk(ctx, order.ID, now); err != nil {
+			d.logger.Error("Failed to flag order", zap.String("order_id", order.ID.String()), zap.Error(err))
+			continue
+		}
+		d.logger.Warn("Order will miss its delivery window",
+			zap.String("order_id", order.ID.String()),
+			zap.Timep("window_end", order.WindowEnd),
+			zap.Timep("estimated_delivery_at", order.EstimatedDeliveryAt),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// reconcileLookback — за какой срок назад ищутся доставки без
+	// начисления; старые заказы, доставленные до появления журнала
+	// проводок, не трогаем.
+	reconcileLookback = 7 * 24 * time.Hour
+	reconcileBatch    = 100
+)
+
+// EarningsReconciler досчитывает заработок по доставленным заказам, для
+// которых начисление не записалось: статус DELIVERED сохраняется раньше
+// проводки, и сбой между ними иначе терял бы заработок курьера.
+type EarningsReconciler struct {
+	ledger   repository.LedgerRepository
+	orders   repository.OrderRepository
+	earnings EarningsService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewEarningsReconciler(ledger repository.LedgerRepository, orders repository.OrderRepository, earnings EarningsService, interval time.Duration, logger *zap.Logger) *EarningsReconciler {
+	return &EarningsReconciler{ledger: ledger, orders: orders, earnings: earnings, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx.
+func (r *EarningsReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.RunOnce(ctx, now); err != nil {
+				r.logger.Error("Earnings reconcile failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *EarningsReconciler) RunOnce(ctx context.Context, now time.Time) error {
+	ids, err := r.ledger.UnpaidDeliveries(ctx, now.Add(-reconcileLookback), reconcileBatch)
+	if err != nil {
+		return fmt.Errorf("find unpaid deliveries: %w", err)
+	}
+	// один сбойный заказ не должен задерживать остальные
+	var recorded int
+	for _, id := range ids {
+		order, err := r.orders.GetByID(ctx, id)
+		if err != nil {
+			r.logger.Error("Failed to load delivered order", zap.String("order_id", id.String()), zap.Error(err))
+			continue
+		}
+		if err := r.earnings.RecordDelivery(ctx, order); err != nil {
+			r.logger.Error("Failed to record delivery earnings", zap.String("order_id", id.String()), zap.Error(err))
+			continue
+		}
+		recorded++
+	}
+	if recorded > 0 {
+		r.logger.Info("Missing delivery earnings recorded", zap.Int("orders", recorded))
+	}
+	return nil
+}
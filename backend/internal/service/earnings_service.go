@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxEarningsPeriod — самый длинный период одной сводки заработка.
+const maxEarningsPeriod = 366 * 24 * time.Hour
+
+var (
+	ErrInvalidEarningsPeriod = errors.New("earnings period must end after it starts and span at most 366 days")
+	ErrInvalidAdjustment     = errors.New("adjustment must have a non-zero amount and component BONUS or ADJUSTMENT")
+	ErrInvalidTip            = errors.New("tip must be positive")
+	ErrOrderNotDelivered     = errors.New("order is not delivered")
+	ErrAlreadyTipped         = errors.New("order has already been tipped")
+	ErrInvalidPayoutCutoff   = errors.New("payout cutoff must not be in the future")
+)
+
+// EarningsService ведёт заработок курьеров в двойной записи: начисления
+// за доставку, чаевые, ручные корректировки и выплаты реестрами.
+type EarningsService interface {
+	// RecordDelivery начисляет курьеру заработок за доставленный заказ.
+	// Повторный вызов для того же заказа ничего не меняет.
+	RecordDelivery(ctx context.Context, order *entity.Order) error
+	// Tip переводит курьеру чаевые клиента за доставленный заказ.
+	Tip(ctx context.Context, clientID, orderID uuid.UUID, amount int64) (*entity.LedgerTransaction, error)
+	Adjust(ctx context.Context, courierID uuid.UUID, in AdjustmentInput, actorID uuid.UUID) (*entity.LedgerTransaction, error)
+	// Summary — заработок курьера за период с разбивкой по дням или
+	// неделям. Незаполненный период — текущий день или неделя.
+	Summary(ctx context.Context, courierID uuid.UUID, q entity.EarningsQuery) (*entity.EarningsSummary, error)
+	// CreatePayout выплачивает всё начисленное до cutoff; nil — до
+	// текущего момента.
+	CreatePayout(ctx context.Context, cutoff *time.Time, actorID uuid.UUID) (*entity.PayoutBatch, error)
+	ListPayouts(ctx context.Context, limit, offset int) ([]*entity.PayoutBatch, error)
+	GetPayout(ctx context.Context, id uuid.UUID) (*entity.PayoutBatch, error)
+}
+
+type EarningsOptions struct {
+	// BasePay и PerKm — в минимальных единицах Currency.
+	BasePay  int64
+	PerKm    int64
+	Currency string
+	// Location задаёт границы дней и недель в сводке.
+	Location *time.Location
+}
+
+type AdjustmentInput struct {
+	Amount      int64
+	Component   entity.EarningComponent
+	Description string
+}
+
+type earningsService struct {
+	ledger      repository.LedgerRepository
+	orderRepo   repository.OrderRepository
+	courierRepo repository.CourierRepository
+	opts        EarningsOptions
+	logger      *zap.Logger
+}
+
+func NewEarningsService(
+	ledger repository.LedgerRepository,
+	orderRepo repository.OrderRepository,
+	courierRepo repository.CourierRepository,
+	opts EarningsOptions,
+	logger *zap.Logger,
+) EarningsService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	return &earningsService{
+		ledger:      ledger,
+		orderRepo:   orderRepo,
+		courierRepo: courierRepo,
+		opts:        opts,
+		logger:      logger,
+	}
+}
+
+// DeliveryEarnings раскладывает заработок за заказ по компонентам:
+// ставка за заказ, километраж по точкам маршрута и надбавка за
+// повышенный спрос, пропорциональная множителю котировки.
+func DeliveryEarnings(order *entity.Order, basePay, perKm int64) map[entity.EarningComponent]int64 {
+	stops := append([]entity.OrderStop(nil), order.Stops...)
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Sequence < stops[j].Sequence })
+
+	var meters float64
+	if len(stops) > 1 {
+		points := make([]entity.Coordinates, 0, len(stops)-1)
+		for _, s := range stops[1:] {
+			points = append(points, s.Location)
+		}
+		meters = geo.PathLength(stops[0].Location, points)
+	}
+
+	ret := map[entity.EarningComponent]int64{
+		entity.EarningBase:     basePay,
+		entity.EarningDistance: int64(math.Round(meters / 1000 * float64(perKm))),
+	}
+	if order.SurgeMultiplier != nil && *order.SurgeMultiplier > 1 {
+		base := ret[entity.EarningBase] + ret[entity.EarningDistance]
+		ret[entity.EarningBonus] = int64(math.Round(float64(base) * (*order.SurgeMultiplier - 1)))
+	}
+	return ret
+}
+
+func (s *earningsService) RecordDelivery(ctx context.Context, order *entity.Order) error {
+	if order.Status != entity.StatusDelivered || order.CourierID == nil {
+		return nil
+	}
+	t := &entity.LedgerTransaction{
+		Kind:      entity.LedgerDelivery,
+		CourierID: *order.CourierID,
+		OrderID:   &order.ID,
+		Currency:  s.opts.Currency,
+	}
+	parts := DeliveryEarnings(order, s.opts.BasePay, s.opts.PerKm)
+	for _, c := range []entity.EarningComponent{entity.EarningBase, entity.EarningDistance, entity.EarningBonus} {
+		t.Credit(entity.AccountEarningsExpense, c, parts[c])
+	}
+	if len(t.Entries) == 0 {
+		return nil
+	}
+	err := s.ledger.Post(ctx, t)
+	if errors.Is(err, repository.ErrLedgerDuplicate) {
+		return nil
+	}
+	return err
+}
+
+func (s *earningsService) Tip(ctx context.Context, clientID, orderID uuid.UUID, amount int64) (*entity.LedgerTransaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidTip
+	}
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	// чужой заказ неотличим от несуществующего
+	if order.ClientID != clientID {
+		return nil, repository.ErrOrderNotFound
+	}
+	if order.Status != entity.StatusDelivered || order.CourierID == nil {
+		return nil, ErrOrderNotDelivered
+	}
+	t := &entity.LedgerTransaction{
+		Kind:      entity.LedgerTip,
+		CourierID: *order.CourierID,
+		OrderID:   &order.ID,
+		Currency:  s.opts.Currency,
+		CreatedBy: &clientID,
+	}
+	t.Credit(entity.AccountTipsClearing, entity.EarningTip, amount)
+	if err := s.ledger.Post(ctx, t); err != nil {
+		if errors.Is(err, repository.ErrLedgerDuplicate) {
+			return nil, ErrAlreadyTipped
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *earningsService) Adjust(ctx context.Context, courierID uuid.UUID, in AdjustmentInput, actorID uuid.UUID) (*entity.LedgerTransaction, error) {
+	if in.Component == "" {
+		in.Component = entity.EarningAdjustment
+	}
+	if in.Amount == 0 || (in.Component != entity.EarningAdjustment && in.Component != entity.EarningBonus) {
+		return nil, ErrInvalidAdjustment
+	}
+	if _, err := s.courierRepo.GetByID(ctx, courierID); err != nil {
+		return nil, err
+	}
+	t := &entity.LedgerTransaction{
+		Kind:        entity.LedgerAdjustment,
+		CourierID:   courierID,
+		Currency:    s.opts.Currency,
+		Description: in.Description,
+		CreatedBy:   &actorID,
+	}
+	t.Credit(entity.AccountEarningsExpense, in.Component, in.Amount)
+	if err := s.ledger.Post(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// periodStart — начало дня или недели (с понедельника), в которые
+// попадает t.
+func (s *earningsService) periodStart(t time.Time, p entity.EarningsPeriodKind) time.Time {
+	y, m, d := t.In(s.opts.Location).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, s.opts.Location)
+	if p == entity.EarningsByWeek {
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+func (s *earningsService) Summary(ctx context.Context, courierID uuid.UUID, q entity.EarningsQuery) (*entity.EarningsSummary, error) {
+	if q.Period == "" {
+		q.Period = entity.EarningsByDay
+	}
+	if q.Period != entity.EarningsByDay && q.Period != entity.EarningsByWeek {
+		return nil, ErrInvalidEarningsPeriod
+	}
+	now := time.Now()
+	if q.From.IsZero() {
+		q.From = s.periodStart(now, q.Period)
+	}
+	if q.To.IsZero() {
+		q.To = s.periodStart(now, q.Period)
+		if q.Period == entity.EarningsByWeek {
+			q.To = q.To.AddDate(0, 0, 7)
+		} else {
+			q.To = q.To.AddDate(0, 0, 1)
+		}
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > maxEarningsPeriod {
+		return nil, ErrInvalidEarningsPeriod
+	}
+
+	txs, err := s.ledger.ListByCourier(ctx, courierID, q.From, q.To)
+	if err != nil {
+		return nil, fmt.Errorf("list ledger: %w", err)
+	}
+	balance, err := s.ledger.Balance(ctx, courierID, s.opts.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("ledger balance: %w", err)
+	}
+
+	sum := &entity.EarningsSummary{
+		CourierID:  courierID,
+		From:       q.From,
+		To:         q.To,
+		Period:     q.Period,
+		Currency:   s.opts.Currency,
+		Components: make(map[entity.EarningComponent]int64),
+		Periods:    []entity.EarningsPeriod{},
+		Balance:    balance,
+	}
+	index := make(map[time.Time]int)
+	for start := s.periodStart(q.From, q.Period); start.Before(q.To); {
+		index[start] = len(sum.Periods)
+		sum.Periods = append(sum.Periods, entity.EarningsPeriod{Start: start})
+		if q.Period == entity.EarningsByWeek {
+			start = start.AddDate(0, 0, 7)
+		} else {
+			start = start.AddDate(0, 0, 1)
+		}
+	}
+	for _, t := range txs {
+		// выплата — не заработок, она только уменьшает баланс
+		if t.Kind == entity.LedgerPayout || t.Currency != s.opts.Currency {
+			continue
+		}
+		i, ok := index[s.periodStart(t.CreatedAt, q.Period)]
+		if !ok {
+			continue
+		}
+		for c, amount := range t.Earned() {
+			sum.Components[c] += amount
+			sum.Total += amount
+			sum.Periods[i].Total += amount
+		}
+		if t.Kind == entity.LedgerDelivery {
+			sum.Orders++
+			sum.Periods[i].Orders++
+		}
+	}
+	return sum, nil
+}
+
+func (s *earningsService) CreatePayout(ctx context.Context, cutoff *time.Time, actorID uuid.UUID) (*entity.PayoutBatch, error) {
+	now := time.Now().UTC()
+	b := &entity.PayoutBatch{
+		Cutoff:    now,
+		Currency:  s.opts.Currency,
+		CreatedBy: &actorID,
+		CreatedAt: now,
+	}
+	if cutoff != nil {
+		if cutoff.After(now) {
+			return nil, ErrInvalidPayoutCutoff
+		}
+		b.Cutoff = cutoff.UTC()
+	}
+	if err := s.ledger.CreatePayoutBatch(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *earningsService) ListPayouts(ctx context.Context, limit, offset int) ([]*entity.PayoutBatch, error) {
+	batches, err := s.ledger.ListPayoutBatches(ctx, limit, offset)
+	if batches == nil {
+		batches = []*entity.PayoutBatch{}
+	}
+	return batches, err
+}
+
+func (s *earningsService) GetPayout(ctx context.Context, id uuid.UUID) (*entity.PayoutBatch, error) {
+	return s.ledger.GetPayoutBatch(ctx, id)
+}
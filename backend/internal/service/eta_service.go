@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/eta"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// speedHistoryWindow — за какой период берём историю для оценки скорости.
+	speedHistoryWindow = 30 * time.Minute
+	// speedHistoryMaxGap — разрыв между точками, после которого отрезок
+	// не учитывается в средней скорости.
+	speedHistoryMaxGap = 5 * time.Minute
+)
+
+type EtaService interface {
+	// Refresh пересчитывает ETA всех активных заказов курьера.
+	Refresh(ctx context.Context, courierID uuid.UUID) error
+	// Project оценивает, когда курьер доставит order, если назначить его
+	// сейчас. nil — оценить нельзя.
+	Project(ctx context.Context, courier *entity.Courier, order *entity.Order, now time.Time) (*time.Time, error)
+}
+
+type etaService struct {
+	orderRepo    repository.OrderRepository
+	courierRepo  repository.CourierRepository
+	routes       RouteService
+	defaultSpeed float64
+	dwell        time.Duration
+	logger       *zap.Logger
+}
+
+// NewEtaService: пока по истории координат нельзя оценить реальную
+// скорость курьера, берётся типовая скорость его транспорта, а
+// defaultSpeedKmh — если тип транспорта неизвестен.
+func NewEtaService(
+	orderRepo repository.OrderRepository,
+	courierRepo repository.CourierRepository,
+	routes RouteService,
+	defaultSpeedKmh float64,
+	dwell time.Duration,
+	logger *zap.Logger,
+) EtaService {
+	return &etaService{
+		orderRepo:    orderRepo,
+		courierRepo:  courierRepo,
+		routes:       routes,
+		defaultSpeed: defaultSpeedKmh / 3.6,
+		dwell:        dwell,
+		logger:       logger,
+	}
+}
+
+func (s *etaService) Refresh(ctx context.Context, courierID uuid.UUID) error {
+	route, err := s.routes.GetCourierRoute(ctx, courierID)
+	if err != nil {
+		if errors.Is(err, ErrCourierLocationUnknown) {
+			return nil
+		}
+		return fmt.Errorf("get route: %w", err)
+	}
+
+	now := time.Now().UTC()
+	speed := s.speed(ctx, courierID, now)
+	estimates := eta.ForRoute(route, speed, s.dwell, now)
+	for orderID, e := range estimates {
+		if err := s.orderRepo.SetETA(ctx, orderID, e.PickupAt, e.DeliveryAt); err != nil {
+			s.logger.Error("Failed to save ETA", zap.String("order_id", orderID.String()), zap.Error(err))
+			return fmt.Errorf("save eta: %w", err)
+		}
+	}
+
+	s.logger.Debug("ETA refreshed",
+		zap.String("courier_id", courierID.String()),
+		zap.Int("orders", len(estimates)),
+		zap.Float64("speed_mps", speed),
+	)
+	return nil
+}
+
+func (s *etaService) Project(ctx context.Context, courier *entity.Courier, order *entity.Order, now time.Time) (*time.Time, error) {
+	route, err := s.routes.Preview(ctx, courier, order)
+	if err != nil {
+		if errors.Is(err, ErrCourierLocationUnknown) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("preview route: %w", err)
+	}
+	return eta.ForRoute(route, s.speed(ctx, courier.UserID, now), s.dwell, now)[order.ID].DeliveryAt, nil
+}
+
+// speed — средняя скорость курьера по недавней истории или типовая
+// скорость его транспорта, если истории мало.
+func (s *etaService) speed(ctx context.Context, courierID uuid.UUID, now time.Time) float64 {
+	fixes, err := s.courierRepo.RecentLocations(ctx, courierID, now.Add(-speedHistoryWindow))
+	if err != nil {
+		s.logger.Warn("Failed to load location history", zap.String("courier_id", courierID.String()), zap.Error(err))
+		return s.vehicleSpeed(ctx, courierID)
+	}
+	if v, ok := eta.AverageSpeed(fixes, speedHistoryMaxGap); ok {
+		return v
+	}
+	return s.vehicleSpeed(ctx, courierID)
+}
+
+// vehicleSpeed — типовая скорость транспорта курьера в м/с; для
+// неизвестного типа — defaultSpeed.
+func (s *etaService) vehicleSpeed(ctx context.Context, courierID uuid.UUID) float64 {
+	courier, err := s.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		s.logger.Warn("Failed to load courier vehicle", zap.String("courier_id", courierID.String()), zap.Error(err))
+		return s.defaultSpeed
+	}
+	if spec, ok := courier.VehicleType.Spec(); ok {
+		return spec.SpeedKmh / 3.6
+	}
+	return s.defaultSpeed
+}
+
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geofence"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type GeofenceService interface {
+	// Track проверяет очередную координату курьера относительно точек
+	// его активных заказов и фиксирует прибытия.
+	Track(ctx context.Context, courierID uuid.UUID, loc entity.Coordinates, at time.Time) error
+}
+
+type GeofenceOptions struct {
+	Radius float64
+	Dwell  time.Duration
+	// AutoAdvance — при прибытии отмечать точку выполненной, как если
+	// бы курьер нажал кнопку сам.
+	AutoAdvance bool
+}
+
+type geofenceService struct {
+	repo      repository.GeofenceRepository
+	orderRepo repository.OrderRepository
+	orders    OrderService
+	cfg       geofence.Config
+	advance   bool
+	logger    *zap.Logger
+}
+
+func NewGeofenceService(
+	repo repository.GeofenceRepository,
+	orderRepo repository.OrderRepository,
+	orders OrderService,
+	opts GeofenceOptions,
+	logger *zap.Logger,
+) GeofenceService {
+	return &geofenceService{
+		repo:      repo,
+		orderRepo: orderRepo,
+		orders:    orders,
+		cfg: geofence.Config{
+			Radius:     opts.Radius,
+			ExitRadius: opts.Radius * 1.5,
+			Dwell:      opts.Dwell,
+		},
+		advance: opts.AutoAdvance,
+		logger:  logger,
+	}
+}
+
+func (s *geofenceService) Track(ctx context.Context, courierID uuid.UUID, loc entity.Coordinates, at time.Time) error {
+	stops, err := s.repo.CandidateStops(ctx, courierID, loc, s.cfg.ExitRadius)
+	if err != nil {
+		return fmt.Errorf("candidate stops: %w", err)
+	}
+
+	for _, p := range stops {
+		prev := geofence.State{EnteredAt: p.EnteredAt}
+		next, arrived := geofence.Step(s.cfg, prev, p.Distance, at)
+		if next.EnteredAt == prev.EnteredAt && !arrived {
+			continue
+		}
+		if err := s.repo.SaveStopState(ctx, p.StopID, next.EnteredAt, next.ArrivedAt); err != nil {
+			return fmt.Errorf("save stop state: %w", err)
+		}
+		if !arrived {
+			continue
+		}
+
+		stopID := p.StopID
+		location := loc
+		event := &entity.OrderEvent{
+			OrderID:   p.OrderID,
+			Type:      entity.ArrivalEvent(p.Kind),
+			StopID:    &stopID,
+			Location:  &location,
+			CreatedAt: at,
+		}
+		if err := s.orderRepo.AddEvent(ctx, event); err != nil {
+			return fmt.Errorf("add event: %w", err)
+		}
+		s.logger.Info("Courier arrived at stop",
+			zap.String("courier_id", courierID.String()),
+			zap.String("order_id", p.OrderID.String()),
+			zap.String("event", string(event.Type)),
+		)
+
+		// точку выдачи закрывает курьер с подтверждением вручения
+		if s.advance && p.Kind == entity.StopPickup {
+			if _, err := s.orders.CompleteStop(ctx, courierID, p.OrderID, p.StopID, nil); err != nil {
+				return fmt.Errorf("auto-advance: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+	"backend/internal/repository"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultHeatmapPrecision — ячейка около 1.2 × 0.6 км.
+	defaultHeatmapPrecision = 6
+	defaultHeatmapPeriod    = time.Hour
+	maxHeatmapPeriod        = 7 * 24 * time.Hour
+)
+
+var ErrInvalidHeatmapPeriod = errors.New("heatmap period must end after it starts and span at most 7 days")
+
+// HeatmapService сравнивает, где возникают заказы и где сейчас свободные
+// курьеры.
+type HeatmapService interface {
+	// Heatmap возвращает ячейки как GeoJSON-полигоны со свойствами
+	// geohash, demand, supply и ratio. Незаполненный период — последний
+	// час.
+	Heatmap(ctx context.Context, q entity.HeatmapQuery) (*geojson.FeatureCollection, error)
+}
+
+type heatmapService struct {
+	repo   repository.HeatmapRepository
+	zones  ZoneService
+	logger *zap.Logger
+}
+
+func NewHeatmapService(repo repository.HeatmapRepository, zones ZoneService, logger *zap.Logger) HeatmapService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &heatmapService{repo: repo, zones: zones, logger: logger}
+}
+
+func (s *heatmapService) Heatmap(ctx context.Context, q entity.HeatmapQuery) (*geojson.FeatureCollection, error) {
+	if q.Precision == 0 {
+		q.Precision = defaultHeatmapPrecision
+	}
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultHeatmapPeriod)
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > maxHeatmapPeriod {
+		return nil, ErrInvalidHeatmapPeriod
+	}
+	if q.ZoneID != nil {
+		if _, err := s.zones.GetZone(ctx, *q.ZoneID); err != nil {
+			return nil, err
+		}
+	}
+
+	cells, err := s.repo.Cells(q)
+	if err != nil {
+		return nil, err
+	}
+	fc := &geojson.FeatureCollection{Features: make([]*geojson.Feature, 0, len(cells))}
+	for _, c := range cells {
+		f, err := heatmapFeature(c)
+		if err != nil {
+			return nil, err
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return fc, nil
+}
+
+func heatmapFeature(c *entity.HeatmapCell) (*geojson.Feature, error) {
+	sw, ne, err := geo.GeohashBounds(c.Geohash)
+	if err != nil {
+		return nil, fmt.Errorf("cell %q: %w", c.Geohash, err)
+	}
+	cell, err := geom.NewPolygon(geom.XY).SetCoords([][]geom.Coord{{
+		{sw.Longitude, sw.Latitude},
+		{ne.Longitude, sw.Latitude},
+		{ne.Longitude, ne.Latitude},
+		{sw.Longitude, ne.Latitude},
+		{sw.Longitude, sw.Latitude},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("cell %q: %w", c.Geohash, err)
+	}
+	return &geojson.Feature{
+		ID:       c.Geohash,
+		Geometry: cell,
+		Properties: map[string]any{
+			"geohash": c.Geohash,
+			"demand":  c.Demand,
+			"supply":  c.Supply,
+			"ratio":   c.Ratio(),
+		},
+	}, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyPurger периодически удаляет истёкшие ключи идемпотентности.
+type IdempotencyPurger struct {
+	repo     repository.IdempotencyRepository
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewIdempotencyPurger(repo repository.IdempotencyRepository, interval time.Duration, logger *zap.Logger) *IdempotencyPurger {
+	return &IdempotencyPurger{repo: repo, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx.
+func (p *IdempotencyPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := p.RunOnce(ctx, now); err != nil {
+				p.logger.Error("Idempotency purge failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (p *IdempotencyPurger) RunOnce(ctx context.Context, now time.Time) error {
+	n, err := p.repo.DeleteExpired(ctx, now)
+	if err != nil {
+		return fmt.Errorf("delete expired keys: %w", err)
+	}
+	if n > 0 {
+		p.logger.Info("Expired idempotency keys purged", zap.Int64("deleted", n))
+	}
+	return nil
+}
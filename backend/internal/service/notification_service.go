@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type NotificationService interface {
+	// NotifyAdmins отправляет сообщение всем администраторам с правом p.
+	NotifyAdmins(ctx context.Context, p entity.Permission, message string) error
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*entity.Notification, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type notificationService struct {
+	repo   repository.NotificationRepository
+	admins repository.AdminRepository
+	logger *zap.Logger
+}
+
+func NewNotificationService(repo repository.NotificationRepository, admins repository.AdminRepository, logger *zap.Logger) NotificationService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &notificationService{repo: repo, admins: admins, logger: logger}
+}
+
+func (s *notificationService) NotifyAdmins(ctx context.Context, p entity.Permission, message string) error {
+	admins, err := s.admins.List()
+	if err != nil {
+		return fmt.Errorf("list admins: %w", err)
+	}
+	for _, a := range admins {
+		if !a.Permissions.Has(p) {
+			continue
+		}
+		if err := s.repo.Create(&entity.Notification{UserID: a.UserID, Message: message}); err != nil {
+			return fmt.Errorf("notify admin %s: %w", a.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *notificationService) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*entity.Notification, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.repo.ListByUser(userID, unreadOnly, limit)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.MarkRead(userID, id)
+}
@@ -2,90 +2,688 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"backend/internal/entity"
+	"backend/internal/geo"
 	"backend/internal/repository"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// dispatchRadius — радиус поиска курьеров при автоназначении, в метрах.
+const dispatchRadius = 5_000
+
+// quoteTolerance — насколько точки забора и выдачи заказа могут отличаться
+// от точек в котировке, в метрах.
+const quoteTolerance = 100
+
+// maxVersionRetries — сколько раз перечитывать курьера, изменённого
+// параллельно, при пересчёте его загрузки.
+const maxVersionRetries = 3
+
+var (
+	ErrNoCourierAvailable = errors.New("no available couriers found")
+	ErrCourierAtCapacity  = errors.New("courier is at capacity")
+	ErrCourierOffline     = errors.New("courier is offline")
+	ErrNotOnTheWay        = errors.New("order is not on the courier's way")
+	ErrInvalidStops       = errors.New("order must have at least one DROPOFF stop")
+	ErrOrderNotAssignable = errors.New("order is not in CREATED status")
+	ErrQuoteMismatch      = errors.New("quote does not match order pickup or delivery point")
+	ErrQuoteTooLight      = errors.New("order is heavier than the quoted weight")
+	ErrOutsideServiceArea = errors.New("delivery address is outside of service area")
+	ErrVehicleTooSmall    = errors.New("order does not fit the courier's vehicle")
+	ErrOrderNotCancelable = errors.New("order is already delivered or canceled")
+	ErrInvalidWindow      = errors.New("delivery window must end after it starts and in the future")
+	ErrOrderScheduled     = errors.New("order is scheduled and not yet released for dispatch")
+	ErrOrderNotInProgress = errors.New("order is not assigned or in transit")
+	ErrOrderNotDeletable  = errors.New("only orders in CREATED status can be deleted")
+	ErrCourierOutOfZone   = errors.New("courier's shift is not in the order's zone")
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, order *entity.Order) (*entity.Order, error)
 	AssignCourierToOrder(ctx context.Context, orderID uuid.UUID) error
-	GetOrderByID(id uuid.UUID) (*entity.Order, error)
-	GetAllOrders() ([]*entity.Order, error)
-	UpdateOrder(order *entity.Order) error
-	DeleteOrder(id uuid.UUID) error
+	AssignCourier(ctx context.Context, orderID, courierID uuid.UUID) error
+	// CompleteStop отмечает точку пройденной от имени курьера, который
+	// везёт заказ. Точка выдачи закрывается только с подтверждением
+	// вручения.
+	CompleteStop(ctx context.Context, courierID, orderID, stopID uuid.UUID, proof *ProofInput) (*entity.Order, error)
+	// RequeueCourierOrders снимает с курьера заказы, по которым он ещё
+	// ничего не сделал, и пытается назначить их другим курьерам.
+	RequeueCourierOrders(ctx context.Context, courierID uuid.UUID) ([]uuid.UUID, error)
+	GetOrderByID(ctx context.Context, id uuid.UUID) (*entity.Order, error)
+	GetAllOrders(ctx context.Context) ([]*entity.Order, error)
+	// GetTimeline — смены статуса и события геозон заказа по времени.
+	GetTimeline(ctx context.Context, id uuid.UUID) ([]entity.OrderEvent, error)
+	// UpdateOrder не переводит заказ в DELIVERED: доставка подтверждается
+	// только через CompleteStop.
+	UpdateOrder(ctx context.Context, order *entity.Order) error
+	// CancelOrder отменяет ещё не доставленный заказ и освобождает место
+	// у курьера.
+	CancelOrder(ctx context.Context, id uuid.UUID) (*entity.Order, error)
+	// DeleteOrder удаляет только заказ в статусе CREATED.
+	DeleteOrder(ctx context.Context, id uuid.UUID) error
+}
+
+// ZonePolicy — что делать с заказом, адрес которого вне активных зон.
+type ZonePolicy string
+
+const (
+	ZonePolicyOff    ZonePolicy = "off"
+	ZonePolicyFlag   ZonePolicy = "flag"
+	ZonePolicyReject ZonePolicy = "reject"
+)
+
+type OrderOptions struct {
+	// MaxDetourMeters — допустимый крюк при добавлении заказа курьеру в пути.
+	MaxDetourMeters float64
+	ZonePolicy      ZonePolicy
+	// DispatchInZone — автоназначать только курьеров с домашней зоной заказа.
+	DispatchInZone bool
+	// DispatchLeadTime — за сколько до начала окна доставки заказ
+	// попадает в автоназначение.
+	DispatchLeadTime time.Duration
 }
 
 type orderService struct {
 	orderRepo   repository.OrderRepository
 	courierRepo repository.CourierRepository
+	shifts      repository.ShiftRepository
+	routes      RouteService
+	etas        EtaService
+	pricing     PricingService
+	zones       ZoneService
+	clients     ClientService
+	proofs      ProofService
+	earnings    EarningsService
+	opts        OrderOptions
+	logger      *zap.Logger
 }
 
 func NewOrderService(
 	orderRepo repository.OrderRepository,
 	courierRepo repository.CourierRepository,
+	shifts repository.ShiftRepository,
+	routes RouteService,
+	etas EtaService,
+	pricing PricingService,
+	zones ZoneService,
+	clients ClientService,
+	proofs ProofService,
+	earnings EarningsService,
+	opts OrderOptions,
+	logger *zap.Logger,
 ) OrderService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
 	return &orderService{
 		orderRepo:   orderRepo,
 		courierRepo: courierRepo,
+		shifts:      shifts,
+		routes:      routes,
+		etas:        etas,
+		pricing:     pricing,
+		zones:       zones,
+		clients:     clients,
+		proofs:      proofs,
+		earnings:    earnings,
+		opts:        opts,
+		logger:      logger,
 	}
 }
 
 
 func (s *orderService) CreateOrder(ctx context.Context, order *entity.Order) (*entity.Order, error) {
-	if err := s.orderRepo.Create(order); err != nil {
+	if order.AddressID != nil {
+		if err := s.applyAddress(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	if len(order.Stops) == 0 {
+		// заказ без явного маршрута — одна точка выдачи по адресу доставки
+		lat, lon, err := order.ParseCoords()
+		if err != nil {
+			return nil, fmt.Errorf("parse delivery coords: %w", err)
+		}
+		order.Stops = []entity.OrderStop{{
+			Kind:     entity.StopDropoff,
+			Address:  order.DeliveryAddress,
+			Location: entity.Coordinates{Latitude: lat, Longitude: lon},
+		}}
+	}
+	if !hasDropoff(order.Stops) {
+		return nil, ErrInvalidStops
+	}
+	if err := s.applyWindow(order, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.applyZone(ctx, order); err != nil {
+		return nil, err
+	}
+	if order.QuoteID != nil {
+		if err := s.applyQuote(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	code, err := s.proofs.NewCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order.DeliveryCode = code
+	if err := s.orderRepo.Create(ctx, order); err != nil {
 		return nil, fmt.Errorf("create order in repository: %w", err)
 	}
 	return order, nil
 }
 
-func (s *orderService) GetOrderByID(id uuid.UUID) (*entity.Order, error) {
-	return s.orderRepo.GetByID(id)
+// applyAddress подставляет в заказ сохранённый адрес клиента. Явно
+// переданные адрес и координаты имеют приоритет.
+func (s *orderService) applyAddress(ctx context.Context, order *entity.Order) error {
+	a, err := s.clients.GetAddress(ctx, order.ClientID, *order.AddressID)
+	if err != nil {
+		return fmt.Errorf("get address: %w", err)
+	}
+	if order.DeliveryCoords == "" {
+		order.DeliveryAddress = a.Address
+		order.DeliveryCoords = fmt.Sprintf("%f,%f", a.Location.Latitude, a.Location.Longitude)
+	}
+	if order.DeliveryNotes == "" {
+		order.DeliveryNotes = a.Notes
+	}
+	return nil
 }
 
-func (s *orderService) GetAllOrders() ([]*entity.Order, error) {
-	return s.orderRepo.GetAll()
+// applyWindow проверяет окно доставки и откладывает автоназначение до
+// DispatchLeadTime перед его началом.
+func (s *orderService) applyWindow(order *entity.Order, now time.Time) error {
+	start, end := order.WindowStart, order.WindowEnd
+	if end != nil && (!end.After(now) || (start != nil && !end.After(*start))) {
+		return ErrInvalidWindow
+	}
+	order.ReleaseAt = nil
+	if start != nil {
+		if release := start.Add(-s.opts.DispatchLeadTime); release.After(now) {
+			order.ReleaseAt = &release
+		}
+	}
+	return nil
 }
 
-func (s *orderService) UpdateOrder(order *entity.Order) error {
-	return s.orderRepo.Update(order)
+// applyZone привязывает заказ к зоне доставки по адресу выдачи.
+func (s *orderService) applyZone(ctx context.Context, order *entity.Order) error {
+	if s.opts.ZonePolicy == ZonePolicyOff || s.opts.ZonePolicy == "" {
+		return nil
+	}
+	lat, lon, err := order.ParseCoords()
+	if err != nil {
+		return fmt.Errorf("parse delivery coords: %w", err)
+	}
+	zone, err := s.zones.Locate(ctx, lat, lon)
+	if err != nil {
+		if !errors.Is(err, repository.ErrZoneNotFound) {
+			return fmt.Errorf("locate zone: %w", err)
+		}
+		if s.opts.ZonePolicy == ZonePolicyReject {
+			return ErrOutsideServiceArea
+		}
+		order.OutOfZone = true
+		return nil
+	}
+	order.ZoneID = &zone.ID
+	return nil
 }
 
-func (s *orderService) DeleteOrder(id uuid.UUID) error {
-	return s.orderRepo.Delete(id)
+// applyQuote переносит цену из котировки в заказ. Окончательно котировка
+// гасится в репозитории вместе с созданием заказа.
+func (s *orderService) applyQuote(ctx context.Context, order *entity.Order) error {
+	q, err := s.pricing.GetQuote(ctx, *order.QuoteID)
+	if err != nil {
+		return fmt.Errorf("get quote: %w", err)
+	}
+	if q.UsedAt != nil || q.Expired(time.Now()) {
+		return repository.ErrQuoteUnavailable
+	}
+	lat, lon, err := order.ParseCoords()
+	if err != nil {
+		return fmt.Errorf("parse delivery coords: %w", err)
+	}
+	if geo.Haversine(q.Dropoff, entity.Coordinates{Latitude: lat, Longitude: lon}) > quoteTolerance {
+		return ErrQuoteMismatch
+	}
+	// котировка посчитана от своей точки забора: дальний забор по дешёвой
+	// котировке не принимаем
+	for _, stop := range order.Stops {
+		if stop.Kind == entity.StopPickup && geo.Haversine(q.Pickup, stop.Location) > quoteTolerance {
+			return ErrQuoteMismatch
+		}
+	}
+	// цена посчитана для веса из котировки: тяжелее посылку по ней не
+	// везём, без явного веса берём вес котировки
+	if order.WeightKg > q.WeightKg {
+		return ErrQuoteTooLight
+	}
+	if order.WeightKg == 0 {
+		order.WeightKg = q.WeightKg
+	}
+	order.Price = &q.Price
+	order.Currency = q.Currency
+	order.SurgeMultiplier = &q.SurgeMultiplier
+	return nil
+}
+
+func (s *orderService) GetOrderByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+	return s.orderRepo.GetByID(ctx, id)
+}
+
+func (s *orderService) GetAllOrders(ctx context.Context) ([]*entity.Order, error) {
+	return s.orderRepo.GetAll(ctx)
+}
+
+func (s *orderService) GetTimeline(ctx context.Context, id uuid.UUID) ([]entity.OrderEvent, error) {
+	if _, err := s.orderRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetTimeline(ctx, id)
+}
+
+func (s *orderService) UpdateOrder(ctx context.Context, order *entity.Order) error {
+	if order.Status == entity.StatusDelivered {
+		prev, err := s.orderRepo.GetByID(ctx, order.ID)
+		if err != nil {
+			return fmt.Errorf("get order: %w", err)
+		}
+		if prev.Status != entity.StatusDelivered {
+			return ErrProofRequired
+		}
+	}
+	return s.save(ctx, order)
+}
+
+// save сохраняет заказ и обновляет загрузку и маршрут его курьера.
+func (s *orderService) save(ctx context.Context, order *entity.Order) error {
+	if err := s.orderRepo.Update(ctx, order); err != nil {
+		return err
+	}
+	if order.CourierID != nil {
+		// заказ мог завершиться или отмениться — пересчитываем загрузку
+		// и маршрут курьера
+		return s.refreshCourier(ctx, *order.CourierID)
+	}
+	return nil
+}
+
+func (s *orderService) CancelOrder(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	if order.Status == entity.StatusDelivered || order.Status == entity.StatusCanceled {
+		return nil, ErrOrderNotCancelable
+	}
+	order.Status = entity.StatusCanceled
+	if err := s.orderRepo.SetETA(ctx, order.ID, nil, nil); err != nil {
+		return nil, fmt.Errorf("reset eta: %w", err)
+	}
+	order.EstimatedPickupAt, order.EstimatedDeliveryAt = nil, nil
+	if err := s.save(ctx, order); err != nil {
+		return nil, fmt.Errorf("cancel order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *orderService) DeleteOrder(ctx context.Context, id uuid.UUID) error {
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get order: %w", err)
+	}
+	// у взятого в работу заказа есть история и подтверждения вручения,
+	// которые нужны при спорах
+	if order.Status != entity.StatusCreated {
+		return ErrOrderNotDeletable
+	}
+	return s.orderRepo.Delete(ctx, id)
 }
 
 
 
 func (s *orderService) AssignCourierToOrder(ctx context.Context, orderID uuid.UUID) error {
-	order, err := s.orderRepo.GetByID(orderID)
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("get order: %w", err)
 	}
+	if order.Status != entity.StatusCreated {
+		return ErrOrderNotAssignable
+	}
+	now := time.Now().UTC()
+	if order.Scheduled(now) {
+		return ErrOrderScheduled
+	}
 
 	lat, lon, err := order.ParseCoords()
 	if err != nil {
 		return fmt.Errorf("parse delivery coords: %w", err)
 	}
 
-	couriers, err := s.courierRepo.FindNearestAvailable(lat, lon, 5_000)
+	filter := repository.CourierFilter{WeightKg: order.WeightKg, VolumeL: order.VolumeLiters()}
+	if s.opts.DispatchInZone {
+		filter.ZoneID = order.ZoneID
+	}
+	couriers, err := s.courierRepo.FindNearestAvailable(ctx, lat, lon, dispatchRadius, filter)
 	if err != nil {
 		return fmt.Errorf("find nearest couriers: %w", err)
 	}
-	if len(couriers) == 0 {
-		return fmt.Errorf("no available couriers found")
+
+	// свободный курьер оценивается расстоянием до первой точки заказа,
+	// курьер в пути — крюком, который придётся сделать ради заказа. Для
+	// заказа с окном сначала выбираем среди курьеров, успевающих в него
+	var chosen *entity.Courier
+	best, bestOnTime := math.Inf(1), false
+	for _, c := range couriers {
+		cost, err := s.dispatchCost(ctx, c, order)
+		if err != nil {
+			return err
+		}
+		if c.ActiveOrders > 0 && cost > s.opts.MaxDetourMeters {
+			continue
+		}
+		onTime := true
+		if order.WindowEnd != nil && !math.IsInf(cost, 1) {
+			if onTime, err = s.makesWindow(ctx, c, order, now); err != nil {
+				return err
+			}
+		}
+		if (onTime && !bestOnTime) || (onTime == bestOnTime && cost < best) {
+			chosen, best, bestOnTime = c, cost, onTime
+		}
+	}
+	if chosen == nil {
+		return ErrNoCourierAvailable
+	}
+
+	if err := s.assign(ctx, order, chosen); err != nil {
+		return err
+	}
+	if order.WindowEnd != nil && !bestOnTime {
+		if _, err := s.orderRepo.MarkWindowAtRisk(ctx, order.ID, now); err != nil {
+			return fmt.Errorf("flag window risk: %w", err)
+		}
+	}
+	return nil
+}
+
+// makesWindow — успеет ли курьер доставить заказ до конца окна. Если
+// оценить нельзя, считаем, что успеет.
+func (s *orderService) makesWindow(ctx context.Context, c *entity.Courier, order *entity.Order, now time.Time) (bool, error) {
+	at, err := s.etas.Project(ctx, c, order, now)
+	if err != nil {
+		return false, fmt.Errorf("project delivery: %w", err)
+	}
+	return at == nil || !at.After(*order.WindowEnd), nil
+}
+
+// AssignCourier назначает заказ конкретному курьеру. Если курьер уже везёт
+// другие заказы, новый должен укладываться в допустимый крюк.
+func (s *orderService) AssignCourier(ctx context.Context, orderID, courierID uuid.UUID) error {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("get order: %w", err)
+	}
+	if order.Status != entity.StatusCreated {
+		return ErrOrderNotAssignable
+	}
+	courier, err := s.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return fmt.Errorf("get courier: %w", err)
+	}
+	// те же условия, что у автоназначения в FindNearestAvailable
+	if courier.AccountStatus != entity.AccountActive {
+		return ErrCourierSuspended
+	}
+	if courier.Status == entity.CourierStatusOffline {
+		return ErrCourierOffline
+	}
+	if err := s.checkShift(ctx, courier, order); err != nil {
+		return err
+	}
+	if !courier.HasCapacity() {
+		return ErrCourierAtCapacity
+	}
+	if !courier.CanCarry(order.WeightKg, order.VolumeLiters()) {
+		return ErrVehicleTooSmall
+	}
+	if courier.ActiveOrders > 0 {
+		detour, err := s.dispatchCost(ctx, courier, order)
+		if err != nil {
+			return err
+		}
+		if detour > s.opts.MaxDetourMeters {
+			return ErrNotOnTheWay
+		}
+	}
+	return s.assign(ctx, order, courier)
+}
+
+// checkShift проверяет, что курьер на открытой смене, а при
+// DispatchInZone — что смена или домашние зоны курьера покрывают зону
+// заказа.
+func (s *orderService) checkShift(ctx context.Context, courier *entity.Courier, order *entity.Order) error {
+	shift, err := s.shifts.GetActive(ctx, courier.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrShiftNotFound) {
+			return ErrNoActiveShift
+		}
+		return fmt.Errorf("get active shift: %w", err)
+	}
+	if !s.opts.DispatchInZone || order.ZoneID == nil {
+		return nil
+	}
+	if shift.ZoneID != nil && *shift.ZoneID == *order.ZoneID {
+		return nil
+	}
+	if slices.Contains(courier.ZoneIDs, *order.ZoneID) {
+		return nil
+	}
+	return ErrCourierOutOfZone
+}
+
+// CompleteStop отмечает точку пройденной; после последней точки заказ
+// считается доставленным и освобождает место у курьера.
+func (s *orderService) CompleteStop(ctx context.Context, courierID, orderID, stopID uuid.UUID, proof *ProofInput) (*entity.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	// точки закрывает только курьер, который везёт заказ; чужой заказ
+	// неотличим от несуществующего
+	if order.CourierID == nil || *order.CourierID != courierID {
+		return nil, repository.ErrOrderNotFound
+	}
+	if order.Status != entity.StatusAssigned && order.Status != entity.StatusInTransit {
+		return nil, ErrOrderNotInProgress
+	}
+	stop := findStop(order.Stops, stopID)
+	if stop == nil {
+		return nil, repository.ErrStopNotFound
+	}
+	if stop.CompletedAt != nil {
+		return nil, repository.ErrStopCompleted
 	}
+	var recorded *entity.DeliveryProof
+	if stop.Kind == entity.StopDropoff {
+		if proof == nil {
+			return nil, ErrProofRequired
+		}
+		// место вручения — последняя известная точка курьера, а не то,
+		// что прислал клиент
+		courier, err := s.courierRepo.GetByID(ctx, courierID)
+		if err != nil {
+			return nil, fmt.Errorf("get courier: %w", err)
+		}
+		if courier.Location == nil {
+			return nil, ErrCourierLocationUnknown
+		}
+		proof.Location = *courier.Location
+		if recorded, err = s.proofs.Record(ctx, order, stop, *proof); err != nil {
+			return nil, fmt.Errorf("record proof: %w", err)
+		}
+	}
+
+	if err := s.orderRepo.CompleteStop(ctx, orderID, stopID, time.Now().UTC()); err != nil {
+		// точку закрыл параллельный запрос или она не закрылась вовсе:
+		// подтверждение без закрытой точки не оставляем
+		if recorded != nil {
+			s.proofs.Discard(ctx, recorded)
+		}
+		return nil, fmt.Errorf("complete stop: %w", err)
+	}
+	order, err = s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+
+	switch {
+	case len(order.PendingStops()) == 0:
+		order.Status = entity.StatusDelivered
+	case order.Status == entity.StatusAssigned:
+		order.Status = entity.StatusInTransit
+	default:
+		return order, nil
+	}
+	if err := s.save(ctx, order); err != nil {
+		return nil, fmt.Errorf("update order: %w", err)
+	}
+	// заказ уже доставлен: сбой начисления не повод отвечать ошибкой,
+	// недостающую проводку допишет EarningsReconciler
+	if order.Status == entity.StatusDelivered && s.earnings != nil {
+		if err := s.earnings.RecordDelivery(ctx, order); err != nil {
+			s.logger.Error("Failed to record delivery earnings",
+				zap.String("order_id", order.ID.String()),
+				zap.String("courier_id", order.CourierID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+	return order, nil
+}
+
+func findStop(stops []entity.OrderStop, id uuid.UUID) *entity.OrderStop {
+	for i := range stops {
+		if stops[i].ID == id {
+			return &stops[i]
+		}
+	}
+	return nil
+}
 
-	chosen := couriers[0]
-	order.CourierID = &chosen.UserID
+func (s *orderService) RequeueCourierOrders(ctx context.Context, courierID uuid.UUID) ([]uuid.UUID, error) {
+	orders, err := s.orderRepo.GetActiveByCourier(ctx, courierID)
+	if err != nil {
+		return nil, fmt.Errorf("get courier orders: %w", err)
+	}
+
+	var requeued []uuid.UUID
+	for _, order := range orders {
+		if order.Status != entity.StatusAssigned || len(order.PendingStops()) != len(order.Stops) {
+			continue
+		}
+		order.CourierID = nil
+		order.Status = entity.StatusCreated
+		order.UpdatedAt = time.Now().UTC()
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return requeued, fmt.Errorf("unassign order: %w", err)
+		}
+		if err := s.orderRepo.SetETA(ctx, order.ID, nil, nil); err != nil {
+			return requeued, fmt.Errorf("reset eta: %w", err)
+		}
+		requeued = append(requeued, order.ID)
+	}
+	if len(requeued) == 0 {
+		return nil, nil
+	}
+	if err := s.syncCourierLoad(ctx, courierID); err != nil {
+		return requeued, fmt.Errorf("sync courier load: %w", err)
+	}
+
+	// без свободного курьера заказ просто остаётся в очереди CREATED
+	for _, id := range requeued {
+		if err := s.AssignCourierToOrder(ctx, id); err != nil && !errors.Is(err, ErrNoCourierAvailable) {
+			return requeued, fmt.Errorf("reassign order %s: %w", id, err)
+		}
+	}
+	return requeued, nil
+}
+
+func (s *orderService) assign(ctx context.Context, order *entity.Order, courier *entity.Courier) error {
+	order.CourierID = &courier.UserID
 	order.Status = entity.StatusAssigned
 
-	if err := s.orderRepo.Update(order); err != nil {
+	if err := s.orderRepo.Update(ctx, order); err != nil {
 		return fmt.Errorf("assign courier to order: %w", err)
 	}
+	return s.refreshCourier(ctx, courier.UserID)
+}
 
+func (s *orderService) refreshCourier(ctx context.Context, courierID uuid.UUID) error {
+	if err := s.syncCourierLoad(ctx, courierID); err != nil {
+		return fmt.Errorf("sync courier load: %w", err)
+	}
+	if _, err := s.routes.Recompute(ctx, courierID); err != nil {
+		if errors.Is(err, ErrCourierLocationUnknown) {
+			return nil
+		}
+		return fmt.Errorf("recompute route: %w", err)
+	}
+	if err := s.etas.Refresh(ctx, courierID); err != nil {
+		return fmt.Errorf("refresh eta: %w", err)
+	}
 	return nil
 }
+
+// dispatchCost — цена добавления заказа курьеру в метрах.
+func (s *orderService) dispatchCost(ctx context.Context, c *entity.Courier, order *entity.Order) (float64, error) {
+	add := order.PendingStops()
+	if c.Location == nil || len(add) == 0 {
+		return math.Inf(1), nil
+	}
+	if c.ActiveOrders == 0 {
+		return geo.Haversine(*c.Location, add[0].Location), nil
+	}
+	return s.routes.Detour(ctx, c, order)
+}
+
+// syncCourierLoad приводит BUSY/AVAILABLE курьера в соответствие с числом
+// его активных заказов. Статус выводится из заказов, поэтому при
+// параллельном изменении курьера его можно просто перечитать и пересчитать.
+func (s *orderService) syncCourierLoad(ctx context.Context, courierID uuid.UUID) error {
+	for attempt := 0; ; attempt++ {
+		courier, err := s.courierRepo.GetByID(ctx, courierID)
+		if err != nil {
+			return err
+		}
+		status := courier.LoadStatus()
+		if status == courier.Status {
+			return nil
+		}
+		courier.Status = status
+		err = s.courierRepo.Update(ctx, courier)
+		if !errors.Is(err, repository.ErrVersionConflict) || attempt == maxVersionRetries {
+			return err
+		}
+	}
+}
+
+func hasDropoff(stops []entity.OrderStop) bool {
+	for _, s := range stops {
+		if s.Kind == entity.StopDropoff {
+			return true
+		}
+	}
+	return false
+}
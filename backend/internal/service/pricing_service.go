@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/pricing"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidSurgeOverride = errors.New("surge override must be positive and expire in the future")
+
+type PricingService interface {
+	CreateQuote(ctx context.Context, pickup, dropoff entity.Coordinates, weightKg float64) (*entity.Quote, error)
+	GetQuote(ctx context.Context, id uuid.UUID) (*entity.Quote, error)
+	GetRules(ctx context.Context) (*entity.PricingRules, error)
+	UpdateRules(ctx context.Context, rules *entity.PricingRules, actorID uuid.UUID) (*entity.PricingRules, error)
+	// ListSurge — загрузка и множители спроса по активным зонам.
+	ListSurge(ctx context.Context) ([]*entity.ZoneSurge, error)
+	// SetSurgeOverride фиксирует множитель зоны вручную до expiresAt
+	// (nil — до снятия).
+	SetSurgeOverride(ctx context.Context, zoneID uuid.UUID, multiplier float64, expiresAt *time.Time, actorID uuid.UUID) (*entity.ZoneSurge, error)
+	ClearSurgeOverride(ctx context.Context, zoneID uuid.UUID, actorID uuid.UUID) error
+}
+
+type pricingService struct {
+	repo     repository.PricingRepository
+	surge    repository.SurgeRepository
+	zones    ZoneService
+	quoteTTL time.Duration
+	logger   *zap.Logger
+}
+
+// NewPricingService: без surge котировки считаются без множителя спроса.
+func NewPricingService(
+	repo repository.PricingRepository,
+	surge repository.SurgeRepository,
+	zones ZoneService,
+	quoteTTL time.Duration,
+	logger *zap.Logger,
+) PricingService {
+	return &pricingService{
+		repo:     repo,
+		surge:    surge,
+		zones:    zones,
+		quoteTTL: quoteTTL,
+		logger:   logger,
+	}
+}
+
+func (s *pricingService) CreateQuote(ctx context.Context, pickup, dropoff entity.Coordinates, weightKg float64) (*entity.Quote, error) {
+	rules, err := s.repo.GetActiveRules(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load pricing rules", zap.Error(err))
+		return nil, fmt.Errorf("load pricing rules: %w", err)
+	}
+
+	now := time.Now().UTC()
+	zone, surge, err := s.zoneSurge(ctx, dropoff, now)
+	if err != nil {
+		return nil, err
+	}
+	in := pricing.Input{
+		Pickup:   pickup,
+		Dropoff:  dropoff,
+		WeightKg: weightKg,
+		At:       now,
+		Surge:    surge,
+	}
+	if zone != nil {
+		in.SurgeZone = zone.Name
+	}
+	res, err := pricing.Calculate(rules, in)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &entity.Quote{
+		Pickup:          pickup,
+		Dropoff:         dropoff,
+		DistanceMeters:  res.DistanceMeters,
+		WeightKg:        weightKg,
+		Price:           res.Total,
+		Currency:        rules.Currency,
+		Breakdown:       res.Breakdown,
+		RulesVersion:    rules.Version,
+		SurgeMultiplier: surge,
+		ExpiresAt:       now.Add(s.quoteTTL),
+		CreatedAt:       now,
+	}
+	if zone != nil {
+		q.ZoneID = &zone.ID
+	}
+	if err := s.repo.CreateQuote(ctx, q); err != nil {
+		return nil, fmt.Errorf("save quote: %w", err)
+	}
+	return q, nil
+}
+
+// zoneSurge находит зону адреса выдачи и действующий в ней множитель
+// спроса. Вне зон множителя нет.
+func (s *pricingService) zoneSurge(ctx context.Context, dropoff entity.Coordinates, now time.Time) (*entity.Zone, float64, error) {
+	if s.surge == nil || s.zones == nil {
+		return nil, 1, nil
+	}
+	zone, err := s.zones.Locate(ctx, dropoff.Latitude, dropoff.Longitude)
+	if err != nil {
+		if errors.Is(err, repository.ErrZoneNotFound) {
+			return nil, 1, nil
+		}
+		return nil, 0, fmt.Errorf("locate zone: %w", err)
+	}
+	state, err := s.surge.Get(ctx, zone.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("load surge: %w", err)
+	}
+	return zone, state.Effective(now), nil
+}
+
+func (s *pricingService) GetQuote(ctx context.Context, id uuid.UUID) (*entity.Quote, error) {
+	return s.repo.GetQuote(ctx, id)
+}
+
+func (s *pricingService) GetRules(ctx context.Context) (*entity.PricingRules, error) {
+	return s.repo.GetActiveRules(ctx)
+}
+
+// UpdateRules сохраняет новую версию правил; она действует сразу для
+// всех следующих котировок.
+func (s *pricingService) UpdateRules(ctx context.Context, rules *entity.PricingRules, actorID uuid.UUID) (*entity.PricingRules, error) {
+	if err := pricing.Validate(rules); err != nil {
+		return nil, err
+	}
+	rules.CreatedBy = &actorID
+	if err := s.repo.SaveRules(ctx, rules); err != nil {
+		return nil, fmt.Errorf("save pricing rules: %w", err)
+	}
+	s.logger.Info("Pricing rules updated", zap.Int64("version", rules.Version), zap.String("actor_id", actorID.String()))
+	return rules, nil
+}
+
+func (s *pricingService) ListSurge(ctx context.Context) ([]*entity.ZoneSurge, error) {
+	return s.surge.Load(ctx, time.Now().UTC())
+}
+
+func (s *pricingService) SetSurgeOverride(ctx context.Context, zoneID uuid.UUID, multiplier float64, expiresAt *time.Time, actorID uuid.UUID) (*entity.ZoneSurge, error) {
+	if multiplier <= 0 || (expiresAt != nil && !expiresAt.After(time.Now())) {
+		return nil, ErrInvalidSurgeOverride
+	}
+	if _, err := s.zones.GetZone(ctx, zoneID); err != nil {
+		return nil, err
+	}
+	if err := s.surge.SetOverride(ctx, zoneID, &multiplier, expiresAt, actorID); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Surge override set",
+		zap.String("zone_id", zoneID.String()),
+		zap.Float64("multiplier", multiplier),
+		zap.String("actor_id", actorID.String()),
+	)
+	return s.surge.Get(ctx, zoneID)
+}
+
+func (s *pricingService) ClearSurgeOverride(ctx context.Context, zoneID uuid.UUID, actorID uuid.UUID) error {
+	if _, err := s.zones.GetZone(ctx, zoneID); err != nil {
+		return err
+	}
+	if err := s.surge.SetOverride(ctx, zoneID, nil, nil, actorID); err != nil {
+		return err
+	}
+	s.logger.Info("Surge override cleared", zap.String("zone_id", zoneID.String()), zap.String("actor_id", actorID.String()))
+	return nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxCodeAttempts — сколько раз можно ошибиться в коде вручения; дальше
+// курьер подтверждает доставку подписью или фото.
+const maxCodeAttempts = 5
+
+var (
+	ErrProofRequired        = errors.New("delivery proof is required to complete a dropoff")
+	ErrInvalidProof         = errors.New("proof must be an OTP code, a signature or a photo")
+	ErrWrongDeliveryCode    = errors.New("wrong delivery code")
+	ErrProofTooLarge        = errors.New("proof file is too large")
+	ErrUnsupportedProofType = errors.New("proof file must be a PNG, JPEG or WebP image")
+)
+
+// allowedProofTypes — форматы подписи и фото, определяемые по содержимому.
+var allowedProofTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
+// ProofInput — то, что курьер присылает при вручении.
+type ProofInput struct {
+	Kind entity.ProofKind
+	Code string
+	// File — подпись или фото для SIGNATURE и PHOTO.
+	File       io.Reader
+	Location   entity.Coordinates
+	RecordedAt time.Time
+}
+
+type ProofService interface {
+	// NewCode выдаёт код вручения для нового заказа.
+	NewCode(ctx context.Context) (string, error)
+	// Record проверяет подтверждение вручения и сохраняет его вместе с
+	// файлом.
+	Record(ctx context.Context, order *entity.Order, stop *entity.OrderStop, in ProofInput) (*entity.DeliveryProof, error)
+	// Discard удаляет подтверждение и его файл, если точку после Record
+	// закрыть не удалось. Ошибки только пишутся в лог.
+	Discard(ctx context.Context, p *entity.DeliveryProof)
+	List(ctx context.Context, orderID uuid.UUID) ([]*entity.DeliveryProof, error)
+	// OpenFile отдаёт файл подтверждения заказа.
+	OpenFile(ctx context.Context, orderID, proofID uuid.UUID) (*entity.DeliveryProof, io.ReadCloser, error)
+	// DeliveryCode показывает код вручения клиенту, создавшему заказ.
+	DeliveryCode(ctx context.Context, clientID, orderID uuid.UUID) (string, error)
+}
+
+type proofService struct {
+	repo     repository.ProofRepository
+	files    storage.Storage
+	maxBytes int64
+	logger   *zap.Logger
+}
+
+func NewProofService(repo repository.ProofRepository, files storage.Storage, maxBytes int64, logger *zap.Logger) ProofService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &proofService{repo: repo, files: files, maxBytes: maxBytes, logger: logger}
+}
+
+func (s *proofService) NewCode(ctx context.Context) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("generate delivery code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func (s *proofService) Record(ctx context.Context, order *entity.Order, stop *entity.OrderStop, in ProofInput) (*entity.DeliveryProof, error) {
+	if !in.Kind.Valid() {
+		return nil, ErrInvalidProof
+	}
+	p := &entity.DeliveryProof{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		StopID:     stop.ID,
+		CourierID:  order.CourierID,
+		Kind:       in.Kind,
+		Location:   in.Location,
+		RecordedAt: in.RecordedAt,
+	}
+	if p.RecordedAt.IsZero() {
+		p.RecordedAt = time.Now().UTC()
+	}
+
+	switch {
+	case in.Kind == entity.ProofOTP:
+		if in.Code == "" {
+			return nil, ErrInvalidProof
+		}
+		ok, err := s.repo.CheckCode(order.ID, in.Code, maxCodeAttempts)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWrongDeliveryCode
+		}
+	case in.File == nil:
+		return nil, ErrInvalidProof
+	default:
+		if err := s.saveFile(ctx, p, in.File); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Create(p); err != nil {
+		return nil, fmt.Errorf("save proof: %w", err)
+	}
+	return p, nil
+}
+
+func (s *proofService) Discard(ctx context.Context, p *entity.DeliveryProof) {
+	// запрос мог быть отменён, а убрать запись всё равно нужно
+	ctx = context.WithoutCancel(ctx)
+	l := s.logger.With(zap.String("proof_id", p.ID.String()), zap.String("order_id", p.OrderID.String()))
+	if err := s.repo.Delete(p.ID); err != nil {
+		l.Error("Failed to delete orphaned proof", zap.Error(err))
+	}
+	if p.FileKey != "" {
+		if err := s.files.Delete(ctx, p.FileKey); err != nil {
+			l.Error("Failed to delete orphaned proof file", zap.String("file_key", p.FileKey), zap.Error(err))
+		}
+	}
+}
+
+// saveFile проверяет размер и формат изображения и кладёт его в хранилище.
+func (s *proofService) saveFile(ctx context.Context, p *entity.DeliveryProof, r io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("read proof file: %w", err)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return ErrProofTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedProofTypes[contentType]
+	if !ok {
+		return ErrUnsupportedProofType
+	}
+
+	p.FileKey = fmt.Sprintf("orders/%s/%s%s", p.OrderID, p.ID, ext)
+	p.ContentType = contentType
+	p.SizeBytes = int64(len(data))
+	if err := s.files.Put(ctx, p.FileKey, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("store proof file: %w", err)
+	}
+	return nil
+}
+
+func (s *proofService) List(ctx context.Context, orderID uuid.UUID) ([]*entity.DeliveryProof, error) {
+	return s.repo.ListByOrder(orderID)
+}
+
+func (s *proofService) OpenFile(ctx context.Context, orderID, proofID uuid.UUID) (*entity.DeliveryProof, io.ReadCloser, error) {
+	p, err := s.repo.GetByID(proofID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.OrderID != orderID || p.FileKey == "" {
+		return nil, nil, repository.ErrProofNotFound
+	}
+	f, err := s.files.Open(ctx, p.FileKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, repository.ErrProofNotFound
+		}
+		return nil, nil, fmt.Errorf("open proof file: %w", err)
+	}
+	return p, f, nil
+}
+
+func (s *proofService) DeliveryCode(ctx context.Context, clientID, orderID uuid.UUID) (string, error) {
+	owner, code, err := s.repo.DeliveryCode(orderID)
+	if err != nil {
+		return "", err
+	}
+	// чужой заказ неотличим от несуществующего
+	if owner != clientID {
+		return "", repository.ErrOrderNotFound
+	}
+	return code, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// maxReportPeriod — самый длинный период одного отчёта.
+const maxReportPeriod = 366 * 24 * time.Hour
+
+var (
+	ErrInvalidReportGroup  = errors.New("report can be grouped by day, zone or courier")
+	ErrInvalidReportPeriod = errors.New("report period must end after it starts and span at most 366 days")
+)
+
+// ReportService — операционные отчёты для руководства. Незаполненный
+// период — последние 7 дней, включая сегодняшний.
+type ReportService interface {
+	Volume(ctx context.Context, q entity.ReportQuery) ([]*entity.VolumeRow, entity.ReportQuery, error)
+	Timing(ctx context.Context, q entity.ReportQuery) ([]*entity.TimingRow, entity.ReportQuery, error)
+	Cancellations(ctx context.Context, q entity.ReportQuery) ([]*entity.CancellationRow, entity.ReportQuery, error)
+}
+
+type reportService struct {
+	repo   repository.ReportRepository
+	loc    *time.Location
+	logger *zap.Logger
+}
+
+func NewReportService(repo repository.ReportRepository, loc *time.Location, logger *zap.Logger) ReportService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &reportService{repo: repo, loc: loc, logger: logger}
+}
+
+// query дополняет запрос значениями по умолчанию и проверяет его; итоговый
+// запрос возвращается вызывающему, чтобы ответ показал фактический период.
+func (s *reportService) query(q entity.ReportQuery) (entity.ReportQuery, error) {
+	if q.GroupBy == "" {
+		q.GroupBy = entity.ReportByDay
+	}
+	if !q.GroupBy.Valid() {
+		return q, ErrInvalidReportGroup
+	}
+	if q.To.IsZero() {
+		y, m, d := time.Now().In(s.loc).Date()
+		q.To = time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -7)
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > maxReportPeriod {
+		return q, ErrInvalidReportPeriod
+	}
+	q.Location = s.loc.String()
+	return q, nil
+}
+
+func (s *reportService) Volume(ctx context.Context, q entity.ReportQuery) ([]*entity.VolumeRow, entity.ReportQuery, error) {
+	q, err := s.query(q)
+	if err != nil {
+		return nil, q, err
+	}
+	rows, err := s.repo.Volume(q)
+	if rows == nil {
+		rows = []*entity.VolumeRow{}
+	}
+	return rows, q, err
+}
+
+func (s *reportService) Timing(ctx context.Context, q entity.ReportQuery) ([]*entity.TimingRow, entity.ReportQuery, error) {
+	q, err := s.query(q)
+	if err != nil {
+		return nil, q, err
+	}
+	rows, err := s.repo.Timing(q)
+	if rows == nil {
+		rows = []*entity.TimingRow{}
+	}
+	return rows, q, err
+}
+
+func (s *reportService) Cancellations(ctx context.Context, q entity.ReportQuery) ([]*entity.CancellationRow, entity.ReportQuery, error) {
+	q, err := s.query(q)
+	if err != nil {
+		return nil, q, err
+	}
+	rows, err := s.repo.Cancellations(q)
+	if rows == nil {
+		rows = []*entity.CancellationRow{}
+	}
+	return rows, q, err
+}
+
+// ReportRefresher периодически пересчитывает витрину отчётов.
+type ReportRefresher struct {
+	repo     repository.ReportRepository
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewReportRefresher(repo repository.ReportRepository, interval time.Duration, logger *zap.Logger) *ReportRefresher {
+	return &ReportRefresher{repo: repo, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx.
+func (r *ReportRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.RunOnce(ctx, now); err != nil {
+				r.logger.Error("Report refresh failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *ReportRefresher) RunOnce(ctx context.Context, now time.Time) error {
+	start := time.Now()
+	if err := r.repo.Refresh(); err != nil {
+		return err
+	}
+	r.logger.Debug("Report facts refreshed", zap.Time("at", now), zap.Duration("took", time.Since(start)))
+	return nil
+}
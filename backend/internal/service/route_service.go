@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/route"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrCourierLocationUnknown = errors.New("courier location is unknown")
+
+type RouteService interface {
+	GetCourierRoute(ctx context.Context, courierID uuid.UUID) (*entity.Route, error)
+	Recompute(ctx context.Context, courierID uuid.UUID) (*entity.Route, error)
+	Detour(ctx context.Context, courier *entity.Courier, order *entity.Order) (float64, error)
+	// Preview строит маршрут курьера с добавленным заказом, не сохраняя
+	// порядок точек.
+	Preview(ctx context.Context, courier *entity.Courier, order *entity.Order) (*entity.Route, error)
+}
+
+type routeService struct {
+	orderRepo   repository.OrderRepository
+	courierRepo repository.CourierRepository
+	planner     *route.Planner
+	logger      *zap.Logger
+}
+
+func NewRouteService(
+	orderRepo repository.OrderRepository,
+	courierRepo repository.CourierRepository,
+	planner *route.Planner,
+	logger *zap.Logger,
+) RouteService {
+	return &routeService{
+		orderRepo:   orderRepo,
+		courierRepo: courierRepo,
+		planner:     planner,
+		logger:      logger,
+	}
+}
+
+// GetCourierRoute отдаёт сохранённый порядок объезда, пересчитывая участки
+// от текущей позиции курьера. Если порядок ещё не построен или в нём
+// появились новые точки — строит его заново.
+func (s *routeService) GetCourierRoute(ctx context.Context, courierID uuid.UUID) (*entity.Route, error) {
+	courier, stops, err := s.pendingStops(ctx, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := true
+	for _, st := range stops {
+		if st.RoutePosition == nil {
+			planned = false
+			break
+		}
+	}
+	if !planned {
+		return s.recompute(ctx, courier, stops)
+	}
+
+	sort.SliceStable(stops, func(i, j int) bool {
+		return *stops[i].RoutePosition < *stops[j].RoutePosition
+	})
+	plan, err := s.planner.Evaluate(*courier.Location, stops)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate route: %w", err)
+	}
+	return buildRoute(courier, plan), nil
+}
+
+// Recompute строит маршрут заново и сохраняет порядок точек.
+func (s *routeService) Recompute(ctx context.Context, courierID uuid.UUID) (*entity.Route, error) {
+	courier, stops, err := s.pendingStops(ctx, courierID)
+	if err != nil {
+		return nil, err
+	}
+	return s.recompute(ctx, courier, stops)
+}
+
+// Detour — на сколько метров удлинится оптимальный маршрут курьера, если
+// добавить ему точки order.
+func (s *routeService) Detour(ctx context.Context, courier *entity.Courier, order *entity.Order) (float64, error) {
+	if courier.Location == nil {
+		return 0, ErrCourierLocationUnknown
+	}
+	active, err := s.orderRepo.GetActiveByCourier(ctx, courier.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("get courier orders: %w", err)
+	}
+	var current []entity.OrderStop
+	for _, o := range active {
+		current = append(current, o.PendingStops()...)
+	}
+
+	before, err := s.planner.Plan(*courier.Location, current)
+	if err != nil {
+		return 0, fmt.Errorf("plan current route: %w", err)
+	}
+	after, err := s.planner.Plan(*courier.Location, append(current, order.PendingStops()...))
+	if err != nil {
+		return 0, fmt.Errorf("plan extended route: %w", err)
+	}
+	return after.Total - before.Total, nil
+}
+
+func (s *routeService) Preview(ctx context.Context, courier *entity.Courier, order *entity.Order) (*entity.Route, error) {
+	if courier.Location == nil {
+		return nil, ErrCourierLocationUnknown
+	}
+	active, err := s.orderRepo.GetActiveByCourier(ctx, courier.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get courier orders: %w", err)
+	}
+	var stops []entity.OrderStop
+	for _, o := range active {
+		stops = append(stops, o.PendingStops()...)
+	}
+	plan, err := s.planner.Plan(*courier.Location, append(stops, order.PendingStops()...))
+	if err != nil {
+		return nil, fmt.Errorf("plan extended route: %w", err)
+	}
+	return buildRoute(courier, plan), nil
+}
+
+func (s *routeService) recompute(ctx context.Context, courier *entity.Courier, stops []entity.OrderStop) (*entity.Route, error) {
+	plan, err := s.planner.Plan(*courier.Location, stops)
+	if err != nil {
+		return nil, fmt.Errorf("plan route: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(plan.Stops))
+	for i, st := range plan.Stops {
+		ids[i] = st.ID
+		pos := i + 1
+		plan.Stops[i].RoutePosition = &pos
+	}
+	if err := s.orderRepo.SetRoutePositions(ctx, ids); err != nil {
+		s.logger.Error("Failed to save route", zap.String("courier_id", courier.UserID.String()), zap.Error(err))
+		return nil, fmt.Errorf("save route: %w", err)
+	}
+
+	s.logger.Info("Route recomputed",
+		zap.String("courier_id", courier.UserID.String()),
+		zap.Int("stops", len(plan.Stops)),
+		zap.Float64("total_meters", plan.Total),
+	)
+	return buildRoute(courier, plan), nil
+}
+
+func (s *routeService) pendingStops(ctx context.Context, courierID uuid.UUID) (*entity.Courier, []entity.OrderStop, error) {
+	courier, err := s.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get courier: %w", err)
+	}
+	if courier.Location == nil {
+		return nil, nil, ErrCourierLocationUnknown
+	}
+	orders, err := s.orderRepo.GetActiveByCourier(ctx, courierID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get courier orders: %w", err)
+	}
+	var stops []entity.OrderStop
+	for _, o := range orders {
+		stops = append(stops, o.PendingStops()...)
+	}
+	return courier, stops, nil
+}
+
+func buildRoute(courier *entity.Courier, plan *route.Plan) *entity.Route {
+	ret := &entity.Route{
+		CourierID:   courier.UserID,
+		Start:       *courier.Location,
+		Stops:       make([]entity.RouteStop, len(plan.Stops)),
+		TotalMeters: plan.Total,
+	}
+	cumulative := 0.0
+	for i, st := range plan.Stops {
+		cumulative += plan.Legs[i]
+		ret.Stops[i] = entity.RouteStop{
+			OrderStop:        st,
+			LegMeters:        plan.Legs[i],
+			CumulativeMeters: cumulative,
+		}
+	}
+	return ret
+}
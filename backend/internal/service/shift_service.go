@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// shiftStartGrace — насколько раньше планового начала можно выйти на смену.
+	shiftStartGrace = 15 * time.Minute
+	maxShiftLength  = 24 * time.Hour
+)
+
+var (
+	ErrInvalidShift       = errors.New("shift must end after it starts and last at most 24h")
+	ErrShiftOverlap       = errors.New("shift overlaps another shift of the courier")
+	ErrShiftStarted       = errors.New("shift has already started")
+	ErrShiftAlreadyActive = errors.New("courier already has an active shift")
+	ErrNoPlannedShift     = errors.New("no planned shift for the courier right now")
+	ErrNoActiveShift      = errors.New("courier has no active shift")
+)
+
+type ShiftService interface {
+	PlanShift(ctx context.Context, s *entity.Shift) error
+	GetShift(ctx context.Context, id uuid.UUID) (*entity.Shift, error)
+	ListShifts(ctx context.Context, f repository.ShiftFilter) ([]*entity.Shift, error)
+	// UpdateShift меняет план смены, пока она не началась.
+	UpdateShift(ctx context.Context, s *entity.Shift) error
+	DeleteShift(ctx context.Context, id uuid.UUID) error
+	// StartShift отмечает выход курьера на ближайшую плановую смену и
+	// делает его доступным для назначения.
+	StartShift(ctx context.Context, courierID uuid.UUID) (*entity.Shift, error)
+	// EndShift закрывает смену, переводит курьера в OFFLINE и возвращает
+	// в очередь не начатые заказы.
+	EndShift(ctx context.Context, courierID uuid.UUID) (*entity.ShiftSummary, error)
+	GetSummary(ctx context.Context, id uuid.UUID) (*entity.ShiftSummary, error)
+}
+
+type shiftService struct {
+	repo        repository.ShiftRepository
+	courierRepo repository.CourierRepository
+	orders      OrderService
+	logger      *zap.Logger
+}
+
+func NewShiftService(
+	repo repository.ShiftRepository,
+	courierRepo repository.CourierRepository,
+	orders OrderService,
+	logger *zap.Logger,
+) ShiftService {
+	return &shiftService{
+		repo:        repo,
+		courierRepo: courierRepo,
+		orders:      orders,
+		logger:      logger,
+	}
+}
+
+func (s *shiftService) PlanShift(ctx context.Context, shift *entity.Shift) error {
+	if _, err := s.courierRepo.GetByID(ctx, shift.CourierID); err != nil {
+		return fmt.Errorf("get courier: %w", err)
+	}
+	if err := s.checkPlan(ctx, shift); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, shift)
+}
+
+func (s *shiftService) GetShift(ctx context.Context, id uuid.UUID) (*entity.Shift, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *shiftService) ListShifts(ctx context.Context, f repository.ShiftFilter) ([]*entity.Shift, error) {
+	return s.repo.List(ctx, f)
+}
+
+func (s *shiftService) UpdateShift(ctx context.Context, shift *entity.Shift) error {
+	current, err := s.repo.GetByID(ctx, shift.ID)
+	if err != nil {
+		return err
+	}
+	if current.StartedAt != nil {
+		return ErrShiftStarted
+	}
+	shift.CourierID = current.CourierID
+	shift.CreatedAt = current.CreatedAt
+	if err := s.checkPlan(ctx, shift); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, shift)
+}
+
+func (s *shiftService) DeleteShift(ctx context.Context, id uuid.UUID) error {
+	shift, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if shift.StartedAt != nil {
+		return ErrShiftStarted
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// checkPlan проверяет интервал смены и отсутствие пересечений с другими
+// сменами того же курьера.
+func (s *shiftService) checkPlan(ctx context.Context, shift *entity.Shift) error {
+	length := shift.PlannedEnd.Sub(shift.PlannedStart)
+	if length <= 0 || length > maxShiftLength {
+		return ErrInvalidShift
+	}
+
+	from := shift.PlannedStart.Add(-maxShiftLength)
+	others, err := s.repo.List(ctx, repository.ShiftFilter{
+		CourierID: &shift.CourierID,
+		From:      &from,
+		To:        &shift.PlannedEnd,
+	})
+	if err != nil {
+		return fmt.Errorf("list shifts: %w", err)
+	}
+	for _, o := range others {
+		if o.ID != shift.ID && o.Overlaps(shift) {
+			return ErrShiftOverlap
+		}
+	}
+	return nil
+}
+
+func (s *shiftService) StartShift(ctx context.Context, courierID uuid.UUID) (*entity.Shift, error) {
+	courier, err := s.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return nil, fmt.Errorf("get courier: %w", err)
+	}
+	if courier.AccountStatus != entity.AccountActive {
+		return nil, ErrCourierSuspended
+	}
+
+	if _, err := s.repo.GetActive(ctx, courierID); err == nil {
+		return nil, ErrShiftAlreadyActive
+	} else if !errors.Is(err, repository.ErrShiftNotFound) {
+		return nil, fmt.Errorf("get active shift: %w", err)
+	}
+
+	now := time.Now().UTC()
+	from, to := now.Add(-maxShiftLength), now.Add(shiftStartGrace)
+	planned, err := s.repo.List(ctx, repository.ShiftFilter{CourierID: &courierID, From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("list shifts: %w", err)
+	}
+	var shift *entity.Shift
+	for _, p := range planned {
+		if p.StartedAt == nil && now.Before(p.PlannedEnd) {
+			shift = p
+			break
+		}
+	}
+	if shift == nil {
+		return nil, ErrNoPlannedShift
+	}
+
+	shift.StartedAt = &now
+	if err := s.repo.Update(ctx, shift); err != nil {
+		return nil, fmt.Errorf("start shift: %w", err)
+	}
+
+	courier.Status = entity.CourierStatusAvailable
+	courier.Status = courier.LoadStatus()
+	if err := s.courierRepo.Update(ctx, courier); err != nil {
+		return nil, fmt.Errorf("update courier status: %w", err)
+	}
+	if err := s.courierRepo.Touch(ctx, courierID, now); err != nil {
+		s.logger.Warn("Failed to update last seen", zap.String("courier_id", courierID.String()), zap.Error(err))
+	}
+
+	s.logger.Info("Shift started", zap.String("courier_id", courierID.String()), zap.String("shift_id", shift.ID.String()))
+	return shift, nil
+}
+
+func (s *shiftService) EndShift(ctx context.Context, courierID uuid.UUID) (*entity.ShiftSummary, error) {
+	shift, err := s.repo.GetActive(ctx, courierID)
+	if err != nil {
+		if errors.Is(err, repository.ErrShiftNotFound) {
+			return nil, ErrNoActiveShift
+		}
+		return nil, fmt.Errorf("get active shift: %w", err)
+	}
+
+	now := time.Now().UTC()
+	shift.EndedAt = &now
+	if err := s.repo.Update(ctx, shift); err != nil {
+		return nil, fmt.Errorf("end shift: %w", err)
+	}
+
+	courier, err := s.courierRepo.GetByID(ctx, courierID)
+	if err != nil {
+		return nil, fmt.Errorf("get courier: %w", err)
+	}
+	courier.Status = entity.CourierStatusOffline
+	if err := s.courierRepo.Update(ctx, courier); err != nil {
+		return nil, fmt.Errorf("update courier status: %w", err)
+	}
+	if _, err := s.orders.RequeueCourierOrders(ctx, courierID); err != nil {
+		s.logger.Error("Failed to requeue orders", zap.String("courier_id", courierID.String()), zap.Error(err))
+	}
+
+	s.logger.Info("Shift ended", zap.String("courier_id", courierID.String()), zap.String("shift_id", shift.ID.String()))
+	return s.repo.Summary(ctx, shift, now)
+}
+
+func (s *shiftService) GetSummary(ctx context.Context, id uuid.UUID) (*entity.ShiftSummary, error) {
+	shift, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Summary(ctx, shift, time.Now().UTC())
+}
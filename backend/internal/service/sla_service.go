@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// slaLookback — заказы старше этого срока проверка SLA не рассматривает.
+const slaLookback = 7 * 24 * time.Hour
+
+var ErrInvalidSLAPolicy = errors.New("sla threshold must be positive and warning must be shorter than threshold")
+
+type SLAService interface {
+	ListPolicies(ctx context.Context) ([]*entity.SLAPolicy, error)
+	UpdatePolicy(ctx context.Context, p *entity.SLAPolicy, actorID uuid.UUID) (*entity.SLAPolicy, error)
+	// Report — нарушения по фильтру и их число по метрикам.
+	Report(ctx context.Context, f repository.SLABreachFilter) (*entity.SLAReport, error)
+	// AtRisk — заказы, которые вот-вот нарушат одну из политик.
+	AtRisk(ctx context.Context, now time.Time) ([]*entity.SLARisk, error)
+}
+
+type slaService struct {
+	repo   repository.SLARepository
+	logger *zap.Logger
+}
+
+func NewSLAService(repo repository.SLARepository, logger *zap.Logger) SLAService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &slaService{repo: repo, logger: logger}
+}
+
+func (s *slaService) ListPolicies(ctx context.Context) ([]*entity.SLAPolicy, error) {
+	return s.repo.ListPolicies()
+}
+
+func (s *slaService) UpdatePolicy(ctx context.Context, p *entity.SLAPolicy, actorID uuid.UUID) (*entity.SLAPolicy, error) {
+	if !p.Metric.Valid() {
+		return nil, repository.ErrSLAPolicyNotFound
+	}
+	if p.ThresholdSeconds <= 0 || p.WarnBeforeSeconds < 0 || p.WarnBeforeSeconds >= p.ThresholdSeconds {
+		return nil, ErrInvalidSLAPolicy
+	}
+	p.UpdatedBy = &actorID
+	if err := s.repo.SavePolicy(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *slaService) Report(ctx context.Context, f repository.SLABreachFilter) (*entity.SLAReport, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	breaches, err := s.repo.ListBreaches(f)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountBreaches(f)
+	if err != nil {
+		return nil, err
+	}
+	if breaches == nil {
+		breaches = []*entity.SLABreach{}
+	}
+	return &entity.SLAReport{Breaches: breaches, Counts: counts}, nil
+}
+
+func (s *slaService) AtRisk(ctx context.Context, now time.Time) ([]*entity.SLARisk, error) {
+	policies, err := s.repo.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	ret := []*entity.SLARisk{}
+	for _, p := range policies {
+		if !p.Enabled || p.WarnBeforeSeconds == 0 {
+			continue
+		}
+		risks, err := s.repo.ListAtRisk(p, now, now.Add(-slaLookback))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, risks...)
+	}
+	return ret, nil
+}
+
+// SLAMonitor периодически сверяет заказы с политиками SLA, сохраняет
+// нарушения и уведомляет администраторов о нарушениях и о заказах под
+// угрозой.
+type SLAMonitor struct {
+	repo     repository.SLARepository
+	notifier NotificationService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewSLAMonitor(repo repository.SLARepository, notifier NotificationService, interval time.Duration, logger *zap.Logger) *SLAMonitor {
+	return &SLAMonitor{repo: repo, notifier: notifier, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx.
+func (m *SLAMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := m.RunOnce(ctx, now); err != nil {
+				m.logger.Error("SLA monitor failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (m *SLAMonitor) RunOnce(ctx context.Context, now time.Time) error {
+	policies, err := m.repo.ListPolicies()
+	if err != nil {
+		return fmt.Errorf("list sla policies: %w", err)
+	}
+	since := now.Add(-slaLookback)
+	for _, p := range policies {
+		if !p.Enabled {
+			continue
+		}
+		breaches, err := m.repo.DetectBreaches(p, now, since)
+		if err != nil {
+			return fmt.Errorf("detect %s breaches: %w", p.Metric, err)
+		}
+		for _, b := range breaches {
+			m.notify(ctx, fmt.Sprintf("SLA breach: order %s exceeded %s of %s (deadline %s)",
+				b.OrderID, p.Metric, p.Threshold(), b.Deadline.UTC().Format(time.RFC3339)))
+		}
+
+		if p.WarnBeforeSeconds == 0 {
+			continue
+		}
+		risks, err := m.repo.ListAtRisk(p, now, since)
+		if err != nil {
+			return fmt.Errorf("list %s risks: %w", p.Metric, err)
+		}
+		for _, r := range risks {
+			fresh, err := m.repo.MarkWarned(r.OrderID, r.Metric, now)
+			if err != nil {
+				return fmt.Errorf("mark warned: %w", err)
+			}
+			if fresh {
+				m.notify(ctx, fmt.Sprintf("SLA at risk: order %s must reach %s by %s",
+					r.OrderID, r.Metric, r.Deadline.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+	return nil
+}
+
+// notify не прерывает проверку: нарушение уже сохранено и видно в отчёте.
+func (m *SLAMonitor) notify(ctx context.Context, message string) {
+	if err := m.notifier.NotifyAdmins(ctx, entity.PermOrdersRead, message); err != nil {
+		m.logger.Error("Failed to notify admins", zap.String("message", message), zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/pricing"
+	"backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// SurgeUpdater периодически пересчитывает множители спроса по зонам из
+// отношения открытых заказов к свободным курьерам.
+type SurgeUpdater struct {
+	surge    repository.SurgeRepository
+	rules    repository.PricingRepository
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewSurgeUpdater(surge repository.SurgeRepository, rules repository.PricingRepository, interval time.Duration, logger *zap.Logger) *SurgeUpdater {
+	return &SurgeUpdater{surge: surge, rules: rules, interval: interval, logger: logger}
+}
+
+// Run работает до отмены ctx.
+func (u *SurgeUpdater) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := u.RunOnce(ctx, now); err != nil {
+				u.logger.Error("Surge update failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (u *SurgeUpdater) RunOnce(ctx context.Context, now time.Time) error {
+	rules, err := u.rules.GetActiveRules(ctx)
+	if err != nil {
+		return fmt.Errorf("load pricing rules: %w", err)
+	}
+	zones, err := u.surge.Load(ctx, now)
+	if err != nil {
+		return fmt.Errorf("load zone surge: %w", err)
+	}
+	// с выключенным surge множители плавно возвращаются к 1
+	for _, z := range zones {
+		target := pricing.SurgeTarget(rules.Surge, z.OpenOrders, z.AvailableCouriers)
+		prev := z.Multiplier
+		z.Multiplier = pricing.SmoothSurge(rules.Surge, prev, target)
+		z.UpdatedAt = now
+		if err := u.surge.Save(ctx, z); err != nil {
+			return fmt.Errorf("save zone surge: %w", err)
+		}
+		if z.Multiplier != prev {
+			u.logger.Info("Zone surge changed",
+				zap.String("zone_id", z.ZoneID.String()),
+				zap.Int("open_orders", z.OpenOrders),
+				zap.Int("available_couriers", z.AvailableCouriers),
+				zap.Float64("multiplier", z.Multiplier),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+	"backend/internal/repository"
+	"backend/internal/tracking"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrTrackingLinkExpired — ссылка просрочена или отозвана клиентом.
+var ErrTrackingLinkExpired = errors.New("tracking link has expired")
+
+type TrackingOptions struct {
+	// Secret подписывает токены ссылок.
+	Secret []byte
+	// TTL — срок действия новой ссылки.
+	TTL time.Duration
+	// LocationCell — до ячейки какого размера в метрах огрубляется
+	// положение курьера.
+	LocationCell float64
+}
+
+type TrackingService interface {
+	// CreateLink выдаёт клиенту ссылку отслеживания его заказа для
+	// получателя.
+	CreateLink(ctx context.Context, clientID, orderID uuid.UUID) (string, *entity.TrackingLink, error)
+	// RevokeLinks отзывает все действующие ссылки заказа.
+	RevokeLinks(ctx context.Context, clientID, orderID uuid.UUID) (int64, error)
+	// Track показывает состояние заказа по токену ссылки.
+	Track(ctx context.Context, token string) (*entity.PublicTracking, error)
+}
+
+type trackingService struct {
+	repo        repository.TrackingRepository
+	orderRepo   repository.OrderRepository
+	courierRepo repository.CourierRepository
+	opts        TrackingOptions
+	logger      *zap.Logger
+}
+
+func NewTrackingService(
+	repo repository.TrackingRepository,
+	orderRepo repository.OrderRepository,
+	courierRepo repository.CourierRepository,
+	opts TrackingOptions,
+	logger *zap.Logger,
+) TrackingService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &trackingService{
+		repo:        repo,
+		orderRepo:   orderRepo,
+		courierRepo: courierRepo,
+		opts:        opts,
+		logger:      logger,
+	}
+}
+
+// ownedOrder загружает заказ клиента; чужой заказ неотличим от
+// несуществующего.
+func (s *trackingService) ownedOrder(ctx context.Context, clientID, orderID uuid.UUID) (*entity.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.ClientID != clientID {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (s *trackingService) CreateLink(ctx context.Context, clientID, orderID uuid.UUID) (string, *entity.TrackingLink, error) {
+	if _, err := s.ownedOrder(ctx, clientID, orderID); err != nil {
+		return "", nil, err
+	}
+	link := &entity.TrackingLink{
+		ID:        uuid.New(),
+		OrderID:   orderID,
+		ExpiresAt: time.Now().UTC().Add(s.opts.TTL).Truncate(time.Second),
+	}
+	if err := s.repo.Create(link); err != nil {
+		return "", nil, fmt.Errorf("save tracking link: %w", err)
+	}
+	return tracking.Sign(s.opts.Secret, link.ID, link.ExpiresAt), link, nil
+}
+
+func (s *trackingService) RevokeLinks(ctx context.Context, clientID, orderID uuid.UUID) (int64, error) {
+	if _, err := s.ownedOrder(ctx, clientID, orderID); err != nil {
+		return 0, err
+	}
+	return s.repo.RevokeByOrder(orderID, time.Now().UTC())
+}
+
+func (s *trackingService) Track(ctx context.Context, token string) (*entity.PublicTracking, error) {
+	now := time.Now().UTC()
+	linkID, err := tracking.Parse(s.opts.Secret, token, now)
+	switch {
+	case errors.Is(err, tracking.ErrExpiredToken):
+		return nil, ErrTrackingLinkExpired
+	case err != nil:
+		return nil, repository.ErrTrackingLinkNotFound
+	}
+
+	link, err := s.repo.GetByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt != nil || !now.Before(link.ExpiresAt) {
+		return nil, ErrTrackingLinkExpired
+	}
+	order, err := s.orderRepo.GetByID(ctx, link.OrderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, repository.ErrTrackingLinkNotFound
+		}
+		return nil, err
+	}
+
+	view := &entity.PublicTracking{
+		Status:    order.Status,
+		Stops:     make([]entity.PublicStop, 0, len(order.Stops)),
+		UpdatedAt: order.UpdatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+	for _, st := range order.Stops {
+		view.Stops = append(view.Stops, entity.PublicStop{Kind: st.Kind, CompletedAt: st.CompletedAt})
+	}
+	if !order.IsActive() {
+		return view, nil
+	}
+	view.EstimatedPickupAt = order.EstimatedPickupAt
+	view.EstimatedDeliveryAt = order.EstimatedDeliveryAt
+
+	if order.CourierID != nil {
+		fix, err := s.courierRepo.LastLocation(ctx, *order.CourierID)
+		if err != nil {
+			// без координат страница всё равно полезна
+			s.logger.Warn("Failed to load courier location for tracking", zap.Error(err))
+		} else if fix != nil {
+			loc := geo.Snap(fix.Location, s.opts.LocationCell)
+			view.CourierLocation = &loc
+			view.LocationUpdatedAt = &fix.RecordedAt
+		}
+	}
+	return view, nil
+}
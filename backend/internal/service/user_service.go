@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"backend/internal/entity"
@@ -10,8 +11,8 @@ import (
 )
 
 type UserService interface {
-	Register(email, password string) (*entity.User, error)
-	Login(email, password string) (*entity.User, error)
+	Register(ctx context.Context, email, password string) (*entity.User, error)
+	Login(ctx context.Context, email, password string) (*entity.User, error)
 }
 
 type userService struct {
@@ -22,8 +23,8 @@ func NewUserService(repo repository.UserRepository) UserService {
 	return &userService{repo: repo}
 }
 
-func (s *userService) Register(email, password string) (*entity.User, error) {
-	_, err := s.repo.GetByEmail(email)
+func (s *userService) Register(ctx context.Context, email, password string) (*entity.User, error) {
+	_, err := s.repo.GetByEmail(ctx, email)
 	if err == nil {
 		return nil, errors.New("user already exists")
 	}
@@ -42,14 +43,14 @@ func (s *userService) Register(email, password string) (*entity.User, error) {
 		Role:         entity.RoleClient, 
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	if err := s.repo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (s *userService) Login(email, password string) (*entity.User, error) {
-	user, err := s.repo.GetByEmail(email)
+func (s *userService) Login(ctx context.Context, email, password string) (*entity.User, error) {
+	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, errors.New("invalid email or password")
 	}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidZoneGeometry = errors.New("zone boundary must be a GeoJSON Polygon or MultiPolygon")
+
+type ZoneService interface {
+	CreateZone(ctx context.Context, name string, boundary json.RawMessage, active bool) (*entity.Zone, error)
+	// ImportZones создаёт зоны из GeoJSON FeatureCollection; имя зоны
+	// берётся из properties.name.
+	ImportZones(ctx context.Context, data []byte) ([]*entity.Zone, error)
+	GetZone(ctx context.Context, id uuid.UUID) (*entity.Zone, error)
+	GetAllZones(ctx context.Context) ([]*entity.Zone, error)
+	UpdateZone(ctx context.Context, z *entity.Zone) error
+	DeleteZone(ctx context.Context, id uuid.UUID) error
+	Locate(ctx context.Context, lat, lon float64) (*entity.Zone, error)
+	SetCourierZones(ctx context.Context, courierID uuid.UUID, zoneIDs []uuid.UUID) error
+}
+
+type zoneService struct {
+	repo   repository.ZoneRepository
+	logger *zap.Logger
+}
+
+func NewZoneService(repo repository.ZoneRepository, logger *zap.Logger) ZoneService {
+	return &zoneService{repo: repo, logger: logger}
+}
+
+func (s *zoneService) CreateZone(ctx context.Context, name string, boundary json.RawMessage, active bool) (*entity.Zone, error) {
+	if err := validateBoundary(boundary); err != nil {
+		return nil, err
+	}
+	z := &entity.Zone{Name: name, Boundary: boundary, Active: active}
+	if err := s.repo.Create(z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+func (s *zoneService) ImportZones(ctx context.Context, data []byte) ([]*entity.Zone, error) {
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidZoneGeometry, err)
+	}
+
+	zones := make([]*entity.Zone, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		name, _ := f.Properties["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%w: feature %d has no properties.name", ErrInvalidZoneGeometry, i)
+		}
+		if !isPolygonal(f.Geometry) {
+			return nil, fmt.Errorf("%w: feature %q", ErrInvalidZoneGeometry, name)
+		}
+		boundary, err := geojson.Marshal(f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("encode feature %q: %w", name, err)
+		}
+		zones = append(zones, &entity.Zone{Name: name, Boundary: boundary, Active: true})
+	}
+
+	// пишем только после проверки всех фич, чтобы ошибка в середине
+	// файла не оставляла импорт наполовину
+	for _, z := range zones {
+		if err := s.repo.Create(z); err != nil {
+			return nil, err
+		}
+	}
+	s.logger.Info("Zones imported", zap.Int("count", len(zones)))
+	return zones, nil
+}
+
+func (s *zoneService) GetZone(ctx context.Context, id uuid.UUID) (*entity.Zone, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *zoneService) GetAllZones(ctx context.Context) ([]*entity.Zone, error) {
+	return s.repo.GetAll()
+}
+
+func (s *zoneService) UpdateZone(ctx context.Context, z *entity.Zone) error {
+	if err := validateBoundary(z.Boundary); err != nil {
+		return err
+	}
+	return s.repo.Update(z)
+}
+
+func (s *zoneService) DeleteZone(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(id)
+}
+
+func (s *zoneService) Locate(ctx context.Context, lat, lon float64) (*entity.Zone, error) {
+	return s.repo.FindByPoint(lat, lon)
+}
+
+func (s *zoneService) SetCourierZones(ctx context.Context, courierID uuid.UUID, zoneIDs []uuid.UUID) error {
+	for _, id := range zoneIDs {
+		if _, err := s.repo.GetByID(id); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetCourierZones(courierID, zoneIDs)
+}
+
+func validateBoundary(boundary json.RawMessage) error {
+	var g geom.T
+	if err := geojson.Unmarshal(boundary, &g); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidZoneGeometry, err)
+	}
+	if !isPolygonal(g) {
+		return ErrInvalidZoneGeometry
+	}
+	return nil
+}
+
+func isPolygonal(g geom.T) bool {
+	switch g.(type) {
+	case *geom.Polygon, *geom.MultiPolygon:
+		return true
+	default:
+		return false
+	}
+}
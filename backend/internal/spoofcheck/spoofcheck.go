@@ -0,0 +1,73 @@
+// Package spoofcheck проверяет правдоподобие координат курьера
+// относительно предыдущего принятого фикса.
+package spoofcheck
+
+import (
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+)
+
+// minInterval защищает от деления на ноль, когда два фикса пришли с
+// одинаковым временем.
+const minInterval = time.Second
+
+type Limits struct {
+	// MaxSpeed — предельная скорость в м/с.
+	MaxSpeed float64
+	// TeleportMeters — скачок не меньше этого расстояния с невозможной
+	// скоростью считается телепортом, а не выбросом GPS.
+	TeleportMeters float64
+	// MaxClockSkew — насколько время фикса может опережать время сервера.
+	MaxClockSkew time.Duration
+}
+
+// Result — найденные аномалии и скорость от предыдущего фикса (если он был
+// и время идёт вперёд).
+type Result struct {
+	Kinds    []entity.AnomalyKind
+	SpeedMps *float64
+}
+
+func (r Result) Suspicious() bool {
+	return len(r.Kinds) > 0
+}
+
+// Check сравнивает fix с prev; prev == nil — у курьера ещё нет истории.
+// now — время сервера: фикс «из будущего» со скоростью не сверяется, иначе
+// он сделал бы правдоподобным любой скачок.
+func Check(prev *entity.LocationFix, fix entity.LocationFix, now time.Time, lim Limits) Result {
+	var res Result
+	if fix.Location.Latitude == 0 && fix.Location.Longitude == 0 {
+		res.Kinds = append(res.Kinds, entity.AnomalyNullIsland)
+	}
+	if fix.RecordedAt.After(now.Add(lim.MaxClockSkew)) {
+		res.Kinds = append(res.Kinds, entity.AnomalyFutureTimestamp)
+		return res
+	}
+	if prev == nil {
+		return res
+	}
+
+	dt := fix.RecordedAt.Sub(prev.RecordedAt)
+	if dt < 0 {
+		res.Kinds = append(res.Kinds, entity.AnomalyOutOfOrder)
+		return res
+	}
+	if dt < minInterval {
+		dt = minInterval
+	}
+
+	dist := geo.Haversine(prev.Location, fix.Location)
+	speed := dist / dt.Seconds()
+	res.SpeedMps = &speed
+	if speed > lim.MaxSpeed {
+		if dist >= lim.TeleportMeters {
+			res.Kinds = append(res.Kinds, entity.AnomalyTeleport)
+		} else {
+			res.Kinds = append(res.Kinds, entity.AnomalyImpossibleSpeed)
+		}
+	}
+	return res
+}
@@ -0,0 +1,19 @@
+// Package storage хранит загруженные файлы: подписи и фото вручения.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// Storage — хранилище файлов по ключу вида "orders/<id>/<file>".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open возвращает ErrNotFound, если файла нет.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет файл; отсутствующий файл — не ошибка.
+	Delete(ctx context.Context, key string) error
+}
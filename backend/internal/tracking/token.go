@@ -0,0 +1,62 @@
+// Package tracking выдаёт и проверяет токены публичных ссылок
+// отслеживания.
+//
+// Токен — base64url от идентификатора ссылки, срока действия и HMAC-SHA256
+// над ними. Подделанные и просроченные токены отбрасываются без обращения
+// к базе, а сам токен не содержит идентификатора заказа.
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	idLen  = 16
+	expLen = 8
+	macLen = 16
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid tracking token")
+	ErrExpiredToken = errors.New("tracking link has expired")
+)
+
+// Sign выдаёт токен ссылки linkID, действующий до expires.
+func Sign(secret []byte, linkID uuid.UUID, expires time.Time) string {
+	buf := make([]byte, idLen+expLen, idLen+expLen+macLen)
+	copy(buf, linkID[:])
+	binary.BigEndian.PutUint64(buf[idLen:], uint64(expires.Unix()))
+	buf = append(buf, sum(secret, buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Parse проверяет подпись и срок токена и возвращает идентификатор ссылки.
+func Parse(secret []byte, token string, now time.Time) (uuid.UUID, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != idLen+expLen+macLen {
+		return uuid.Nil, ErrInvalidToken
+	}
+	payload, mac := buf[:idLen+expLen], buf[idLen+expLen:]
+	if !hmac.Equal(mac, sum(secret, payload)) {
+		return uuid.Nil, ErrInvalidToken
+	}
+	expires := time.Unix(int64(binary.BigEndian.Uint64(payload[idLen:])), 0)
+	if !now.Before(expires) {
+		return uuid.Nil, ErrExpiredToken
+	}
+	id, _ := uuid.FromBytes(payload[:idLen])
+	return id, nil
+}
+
+func sum(secret, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(payload)
+	return h.Sum(nil)[:macLen]
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS orders_courier_id_idx;
+DROP TABLE IF EXISTS order_stops;
+ALTER TABLE couriers DROP COLUMN IF EXISTS capacity;
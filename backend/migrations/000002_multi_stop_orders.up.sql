@@ -0,0 +1,15 @@
+ALTER TABLE couriers ADD COLUMN capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0);
+
+CREATE TABLE order_stops (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('PICKUP', 'DROPOFF')),
+    address VARCHAR(255) NOT NULL,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (order_id, seq)
+);
+
+CREATE INDEX order_stops_order_id_idx ON order_stops (order_id);
+CREATE INDEX orders_courier_id_idx ON orders (courier_id);
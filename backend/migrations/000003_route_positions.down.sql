@@ -0,0 +1 @@
+ALTER TABLE order_stops DROP COLUMN IF EXISTS route_position;
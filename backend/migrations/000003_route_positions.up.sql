@@ -0,0 +1 @@
+ALTER TABLE order_stops ADD COLUMN route_position INTEGER;
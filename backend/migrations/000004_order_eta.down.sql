@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS estimated_delivery_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS estimated_pickup_at;
+DROP TABLE IF EXISTS courier_locations;
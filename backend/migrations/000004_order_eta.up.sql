@@ -0,0 +1,11 @@
+CREATE TABLE courier_locations (
+    id BIGSERIAL PRIMARY KEY,
+    courier_id UUID NOT NULL REFERENCES couriers(user_id) ON DELETE CASCADE,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX courier_locations_courier_id_recorded_at_idx ON courier_locations (courier_id, recorded_at);
+
+ALTER TABLE orders ADD COLUMN estimated_pickup_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE orders ADD COLUMN estimated_delivery_at TIMESTAMP WITH TIME ZONE;
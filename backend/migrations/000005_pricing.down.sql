@@ -0,0 +1,5 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS price;
+ALTER TABLE orders DROP COLUMN IF EXISTS quote_id;
+DROP TABLE IF EXISTS quotes;
+DROP TABLE IF EXISTS pricing_rules;
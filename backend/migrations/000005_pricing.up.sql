@@ -0,0 +1,42 @@
+CREATE TABLE pricing_rules (
+    id BIGSERIAL PRIMARY KEY,
+    rules JSONB NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE quotes (
+    id UUID PRIMARY KEY,
+    pickup GEOMETRY(Point, 4326) NOT NULL,
+    dropoff GEOMETRY(Point, 4326) NOT NULL,
+    distance_meters DOUBLE PRECISION NOT NULL,
+    weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    currency VARCHAR(3) NOT NULL,
+    breakdown JSONB NOT NULL,
+    rules_version BIGINT REFERENCES pricing_rules(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE orders ADD COLUMN quote_id UUID REFERENCES quotes(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN price BIGINT;
+ALTER TABLE orders ADD COLUMN currency VARCHAR(3);
+
+INSERT INTO pricing_rules (rules) VALUES ('{
+  "currency": "RUB",
+  "base_fare": 9900,
+  "per_km": 2500,
+  "minimum_fare": 14900,
+  "timezone": "Europe/Moscow",
+  "weight_tiers": [
+    {"up_to_kg": 5, "surcharge": 0},
+    {"up_to_kg": 15, "surcharge": 10000},
+    {"up_to_kg": 30, "surcharge": 25000}
+  ],
+  "time_multipliers": [
+    {"from": "22:00", "to": "06:00", "multiplier": 1.3}
+  ],
+  "zone_surcharges": []
+}');
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS out_of_zone;
+ALTER TABLE orders DROP COLUMN IF EXISTS zone_id;
+DROP TABLE IF EXISTS courier_zones;
+DROP TABLE IF EXISTS zones;
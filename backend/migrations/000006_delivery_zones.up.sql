@@ -0,0 +1,21 @@
+CREATE TABLE zones (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    boundary GEOMETRY(MultiPolygon, 4326) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX zones_boundary_idx ON zones USING GIST (boundary);
+
+CREATE TABLE courier_zones (
+    courier_id UUID NOT NULL REFERENCES couriers(user_id) ON DELETE CASCADE,
+    zone_id UUID NOT NULL REFERENCES zones(id) ON DELETE CASCADE,
+    PRIMARY KEY (courier_id, zone_id)
+);
+
+CREATE INDEX courier_zones_zone_id_idx ON courier_zones (zone_id);
+
+ALTER TABLE orders ADD COLUMN zone_id UUID REFERENCES zones(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN out_of_zone BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_events;
+ALTER TABLE order_stops DROP COLUMN IF EXISTS arrived_at;
+ALTER TABLE order_stops DROP COLUMN IF EXISTS entered_at;
@@ -0,0 +1,13 @@
+ALTER TABLE order_stops ADD COLUMN entered_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE order_stops ADD COLUMN arrived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE order_events (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    stop_id UUID REFERENCES order_stops(id) ON DELETE SET NULL,
+    type VARCHAR(50) NOT NULL,
+    location GEOMETRY(Point, 4326),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX order_events_order_id_idx ON order_events (order_id);
@@ -0,0 +1,14 @@
+CREATE TABLE location_anomalies (
+    id UUID PRIMARY KEY,
+    courier_id UUID NOT NULL REFERENCES couriers(user_id) ON DELETE CASCADE,
+    kinds TEXT[] NOT NULL,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    prev_location GEOMETRY(Point, 4326),
+    speed_mps DOUBLE PRECISION,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rejected BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX location_anomalies_courier_id_idx ON location_anomalies (courier_id, created_at);
+CREATE INDEX location_anomalies_created_at_idx ON location_anomalies (created_at);
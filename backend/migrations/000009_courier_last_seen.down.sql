@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS couriers_status_last_seen_at_idx;
+ALTER TABLE couriers DROP COLUMN IF EXISTS last_seen_at;
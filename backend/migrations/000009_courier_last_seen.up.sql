@@ -0,0 +1,3 @@
+ALTER TABLE couriers ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX couriers_status_last_seen_at_idx ON couriers (status, last_seen_at);
@@ -0,0 +1,17 @@
+CREATE TABLE shifts (
+    id UUID PRIMARY KEY,
+    courier_id UUID NOT NULL REFERENCES couriers(user_id) ON DELETE CASCADE,
+    zone_id UUID REFERENCES zones(id) ON DELETE SET NULL,
+    planned_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    planned_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (planned_end > planned_start)
+);
+
+CREATE INDEX shifts_courier_id_planned_start_idx ON shifts (courier_id, planned_start);
+-- у курьера не больше одной открытой смены
+CREATE UNIQUE INDEX shifts_active_courier_idx ON shifts (courier_id)
+    WHERE started_at IS NOT NULL AND ended_at IS NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS height_cm;
+ALTER TABLE orders DROP COLUMN IF EXISTS width_cm;
+ALTER TABLE orders DROP COLUMN IF EXISTS length_cm;
+ALTER TABLE orders DROP COLUMN IF EXISTS weight_kg;
+
+ALTER TABLE couriers DROP COLUMN IF EXISTS max_volume_l;
+ALTER TABLE couriers DROP COLUMN IF EXISTS max_weight_kg;
+ALTER TABLE couriers DROP COLUMN IF EXISTS vehicle_type;
@@ -0,0 +1,9 @@
+ALTER TABLE couriers ADD COLUMN vehicle_type VARCHAR(20) NOT NULL DEFAULT 'BIKE'
+    CHECK (vehicle_type IN ('FOOT', 'BIKE', 'SCOOTER', 'CAR', 'VAN'));
+ALTER TABLE couriers ADD COLUMN max_weight_kg DOUBLE PRECISION NOT NULL DEFAULT 15;
+ALTER TABLE couriers ADD COLUMN max_volume_l DOUBLE PRECISION NOT NULL DEFAULT 50;
+
+ALTER TABLE orders ADD COLUMN weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN length_cm DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN width_cm DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN height_cm DOUBLE PRECISION NOT NULL DEFAULT 0;
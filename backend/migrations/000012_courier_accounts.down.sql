@@ -0,0 +1,3 @@
+ALTER TABLE couriers DROP COLUMN IF EXISTS account_status_changed_at;
+ALTER TABLE couriers DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE couriers DROP COLUMN IF EXISTS account_status;
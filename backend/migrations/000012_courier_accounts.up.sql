@@ -0,0 +1,4 @@
+ALTER TABLE couriers ADD COLUMN account_status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE'
+    CHECK (account_status IN ('ACTIVE', 'SUSPENDED', 'DEACTIVATED'));
+ALTER TABLE couriers ADD COLUMN status_reason TEXT;
+ALTER TABLE couriers ADD COLUMN account_status_changed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_notes,
+    DROP COLUMN IF EXISTS address_id;
+
+DROP TABLE IF EXISTS client_addresses;
@@ -0,0 +1,16 @@
+CREATE TABLE client_addresses (
+    id UUID PRIMARY KEY,
+    client_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX client_addresses_client_id_idx ON client_addresses (client_id);
+
+ALTER TABLE orders
+    ADD COLUMN address_id UUID REFERENCES client_addresses(id) ON DELETE SET NULL,
+    ADD COLUMN delivery_notes TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE admins ALTER COLUMN permissions DROP NOT NULL;
+ALTER TABLE admins ALTER COLUMN permissions DROP DEFAULT;
@@ -0,0 +1,5 @@
+-- действующие администраторы сохраняют полный доступ
+UPDATE admins SET permissions = '["*"]'::jsonb WHERE permissions IS NULL;
+
+ALTER TABLE admins ALTER COLUMN permissions SET DEFAULT '[]'::jsonb;
+ALTER TABLE admins ALTER COLUMN permissions SET NOT NULL;
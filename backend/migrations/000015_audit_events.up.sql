@@ -0,0 +1,28 @@
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY,
+    actor_id UUID,
+    actor_role VARCHAR(50),
+    action VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    status INTEGER NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_id, created_at);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor_id, created_at);
+
+-- журнал только дописывается
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS delivery_proofs;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_code_attempts,
+    DROP COLUMN IF EXISTS delivery_code;
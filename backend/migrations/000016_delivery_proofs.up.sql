@@ -0,0 +1,19 @@
+ALTER TABLE orders
+    ADD COLUMN delivery_code VARCHAR(6),
+    ADD COLUMN delivery_code_attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE delivery_proofs (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    stop_id UUID NOT NULL REFERENCES order_stops(id) ON DELETE CASCADE,
+    courier_id UUID REFERENCES couriers(user_id) ON DELETE SET NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('OTP', 'SIGNATURE', 'PHOTO')),
+    file_key TEXT,
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX delivery_proofs_order_id_idx ON delivery_proofs (order_id);
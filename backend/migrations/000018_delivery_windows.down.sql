@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS orders_scheduled_idx;
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_window_check,
+    DROP COLUMN IF EXISTS window_at_risk,
+    DROP COLUMN IF EXISTS release_at,
+    DROP COLUMN IF EXISTS window_end,
+    DROP COLUMN IF EXISTS window_start;
@@ -0,0 +1,9 @@
+ALTER TABLE orders
+    ADD COLUMN window_start TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN window_end TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN release_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN window_at_risk BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT orders_window_check CHECK (window_start IS NULL OR window_end IS NULL OR window_end > window_start);
+
+CREATE INDEX orders_scheduled_idx ON orders (release_at)
+    WHERE status = 'CREATED' AND courier_id IS NULL AND (window_start IS NOT NULL OR window_end IS NOT NULL);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS notifications_unread_idx;
+DROP TABLE IF EXISTS sla_warnings;
+DROP TABLE IF EXISTS sla_breaches;
+DROP TABLE IF EXISTS sla_policies;
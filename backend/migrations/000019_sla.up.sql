@@ -0,0 +1,38 @@
+CREATE TABLE sla_policies (
+    metric VARCHAR(30) PRIMARY KEY CHECK (metric IN ('TIME_TO_ASSIGN', 'TIME_TO_PICKUP', 'TIME_TO_DELIVER')),
+    threshold_seconds INTEGER NOT NULL CHECK (threshold_seconds > 0),
+    warn_before_seconds INTEGER NOT NULL DEFAULT 0 CHECK (warn_before_seconds >= 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO sla_policies (metric, threshold_seconds, warn_before_seconds) VALUES
+    ('TIME_TO_ASSIGN', 600, 180),
+    ('TIME_TO_PICKUP', 2700, 600),
+    ('TIME_TO_DELIVER', 7200, 1200);
+
+CREATE TABLE sla_breaches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    metric VARCHAR(30) NOT NULL,
+    threshold_seconds INTEGER NOT NULL,
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- reached_at — когда заказ всё-таки достиг этапа; NULL на момент
+    -- обнаружения
+    reached_at TIMESTAMP WITH TIME ZONE,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (order_id, metric)
+);
+
+CREATE INDEX sla_breaches_deadline_idx ON sla_breaches (deadline DESC);
+
+-- предупреждения о риске нарушения, чтобы не слать их повторно
+CREATE TABLE sla_warnings (
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    metric VARCHAR(30) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (order_id, metric)
+);
+
+CREATE INDEX notifications_unread_idx ON notifications (user_id, created_at DESC) WHERE NOT is_read;
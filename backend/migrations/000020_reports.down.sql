@@ -0,0 +1 @@
+DROP MATERIALIZED VIEW IF EXISTS report_order_facts;
@@ -0,0 +1,20 @@
+-- report_order_facts — по строке на заказ с моментами ключевых этапов,
+-- чтобы отчёты не пересчитывали order_status_logs на каждый запрос.
+-- Обновляется фоновой задачей (REFRESH ... CONCURRENTLY).
+CREATE MATERIALIZED VIEW report_order_facts AS
+SELECT o.id AS order_id,
+       o.zone_id,
+       o.courier_id,
+       o.status,
+       o.created_at,
+       coalesce(o.release_at, o.created_at) AS started_at,
+       min(sl.created_at) FILTER (WHERE sl.status IN ('ASSIGNED', 'IN_TRANSIT', 'DELIVERED')) AS assigned_at,
+       min(sl.created_at) FILTER (WHERE sl.status = 'DELIVERED') AS delivered_at,
+       min(sl.created_at) FILTER (WHERE sl.status = 'CANCELED') AS canceled_at
+  FROM orders o
+  LEFT JOIN order_status_logs sl ON sl.order_id = o.id
+ GROUP BY o.id;
+
+-- уникальный индекс обязателен для REFRESH CONCURRENTLY
+CREATE UNIQUE INDEX report_order_facts_order_id_idx ON report_order_facts (order_id);
+CREATE INDEX report_order_facts_created_at_idx ON report_order_facts (created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS surge_multiplier;
+ALTER TABLE quotes DROP COLUMN IF EXISTS surge_multiplier;
+ALTER TABLE quotes DROP COLUMN IF EXISTS zone_id;
+DROP TABLE IF EXISTS zone_surge;
@@ -0,0 +1,17 @@
+-- zone_surge — текущий сглаженный множитель спроса по зоне и ручное
+-- переопределение администратора
+CREATE TABLE zone_surge (
+    zone_id UUID PRIMARY KEY REFERENCES zones(id) ON DELETE CASCADE,
+    open_orders INTEGER NOT NULL DEFAULT 0,
+    available_couriers INTEGER NOT NULL DEFAULT 0,
+    multiplier DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (multiplier > 0),
+    override_multiplier DOUBLE PRECISION CHECK (override_multiplier > 0),
+    override_expires_at TIMESTAMP WITH TIME ZONE,
+    override_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- множитель, по которому посчитана цена, хранится вместе с ней
+ALTER TABLE quotes ADD COLUMN zone_id UUID REFERENCES zones(id) ON DELETE SET NULL;
+ALTER TABLE quotes ADD COLUMN surge_multiplier DOUBLE PRECISION NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN surge_multiplier DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ledger_entries;
+DROP FUNCTION IF EXISTS ledger_entries_append_only();
+DROP FUNCTION IF EXISTS ledger_entries_balanced();
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS payout_batches;
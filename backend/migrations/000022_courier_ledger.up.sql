@@ -0,0 +1,69 @@
+-- payout_batches — выплаты курьерам одним реестром для бухгалтерии
+CREATE TABLE payout_batches (
+    id UUID PRIMARY KEY,
+    -- в реестр попадает всё, что начислено до cutoff
+    cutoff TIMESTAMP WITH TIME ZONE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    total BIGINT NOT NULL,
+    couriers INTEGER NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- ledger_transactions — проводки по счетам курьеров. courier_id без
+-- внешнего ключа: финансовые записи переживают удаление курьера.
+CREATE TABLE ledger_transactions (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('DELIVERY', 'TIP', 'ADJUSTMENT', 'PAYOUT')),
+    courier_id UUID NOT NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    payout_id UUID REFERENCES payout_batches(id),
+    currency VARCHAR(3) NOT NULL,
+    description TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX ledger_transactions_courier_idx ON ledger_transactions (courier_id, created_at);
+-- заработок за заказ и чаевые начисляются по одному разу
+CREATE UNIQUE INDEX ledger_transactions_delivery_idx ON ledger_transactions (order_id) WHERE kind = 'DELIVERY';
+CREATE UNIQUE INDEX ledger_transactions_tip_idx ON ledger_transactions (order_id) WHERE kind = 'TIP';
+
+-- ledger_entries — двойная запись: дебет положительный, кредит
+-- отрицательный, сумма по проводке равна нулю
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES ledger_transactions(id),
+    account VARCHAR(30) NOT NULL
+        CHECK (account IN ('COURIER_PAYABLE', 'EARNINGS_EXPENSE', 'TIPS_CLEARING', 'PAYOUTS_CLEARING')),
+    component VARCHAR(20) NOT NULL
+        CHECK (component IN ('BASE', 'DISTANCE', 'TIP', 'BONUS', 'ADJUSTMENT', 'PAYOUT')),
+    amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX ledger_entries_transaction_idx ON ledger_entries (transaction_id);
+
+CREATE FUNCTION ledger_entries_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT sum(amount) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- баланс проверяется при коммите, когда записаны все строки проводки
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_balanced();
+
+CREATE FUNCTION ledger_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_append_only();
@@ -0,0 +1,20 @@
+-- idempotency_keys — ответы на изменяющие запросы с заголовком
+-- Idempotency-Key. Ключ действует в пределах пользователя; для запросов
+-- без токена scope — "anon:" и IP-адрес соединения.
+CREATE TABLE idempotency_keys (
+    scope VARCHAR(64) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    -- sha256 метода, адреса и тела запроса
+    request_hash CHAR(64) NOT NULL,
+    -- status NULL — запрос ещё выполняется
+    status INTEGER,
+    content_type TEXT,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,4 @@
+-- version растёт при каждом изменении заказа или профиля курьера через
+-- Update; API отдаёт его как ETag и сверяет с If-Match
+ALTER TABLE orders ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE couriers ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,9 @@
+-- подтверждения вручения — доказательство при спорах: заказ и точку с
+-- подтверждением удалить нельзя
+ALTER TABLE delivery_proofs
+    DROP CONSTRAINT delivery_proofs_order_id_fkey,
+    ADD CONSTRAINT delivery_proofs_order_id_fkey
+        FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE RESTRICT,
+    DROP CONSTRAINT delivery_proofs_stop_id_fkey,
+    ADD CONSTRAINT delivery_proofs_stop_id_fkey
+        FOREIGN KEY (stop_id) REFERENCES order_stops(id) ON DELETE RESTRICT;
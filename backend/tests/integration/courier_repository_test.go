@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
@@ -61,13 +62,18 @@ func TestCourierRepository_FindNearestAvailable(t *testing.T) {
 	}
 
 	// 3) Миграция
-	migrationPath := filepath.Join("..", "..", "migrations", "000001_create_initial_schema_with_postgis.up.sql")
-	sqlBytes, err := ioutil.ReadFile(migrationPath)
+	migrationPaths, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
 	if err != nil {
-		t.Fatalf("could not read migration file %q: %v", migrationPath, err)
+		t.Fatalf("could not list migrations: %v", err)
 	}
-	if _, err := db.Exec(string(sqlBytes)); err != nil {
-		t.Fatalf("could not apply migration: %v", err)
+	for _, migrationPath := range migrationPaths {
+		sqlBytes, err := ioutil.ReadFile(migrationPath)
+		if err != nil {
+			t.Fatalf("could not read migration file %q: %v", migrationPath, err)
+		}
+		if _, err := db.Exec(string(sqlBytes)); err != nil {
+			t.Fatalf("could not apply migration %q: %v", migrationPath, err)
+		}
 	}
 
 	// 4) Сидим двух курьеров
@@ -91,10 +97,19 @@ func TestCourierRepository_FindNearestAvailable(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not seed couriers: %v", err)
 	}
+	_, err = db.Exec(`
+		INSERT INTO shifts (id,courier_id,planned_start,planned_end,started_at)
+		VALUES
+		  ($1,'11111111-1111-1111-1111-111111111111',$3,$4,$3),
+		  ($2,'22222222-2222-2222-2222-222222222222',$3,$4,$3)
+	`, uuid.New(), uuid.New(), now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("could not seed shifts: %v", err)
+	}
 
 	// 5) Проверяем FindNearestAvailable
-	repo := repository.NewCourierRepository(db, zap.NewNop())
-	couriers, err := repo.FindNearestAvailable(52.37, 4.90, 5000)
+	repo := repository.NewCourierRepository(db, 5*time.Second, zap.NewNop())
+	couriers, err := repo.FindNearestAvailable(context.Background(), 52.37, 4.90, 5000, repository.CourierFilter{})
 	if err != nil {
 		t.Fatalf("FindNearestAvailable() error: %v", err)
 	}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
@@ -56,13 +57,18 @@ func TestOrderRepository_CRUD(t *testing.T) {
 		t.Fatalf("db ping: %v", err)
 	}
 
-	migrationPath := filepath.Join("..", "..", "migrations", "000001_create_initial_schema_with_postgis.up.sql")
-	migrationSQL, err := ioutil.ReadFile(migrationPath)
+	migrationPaths, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
 	if err != nil {
-		t.Fatalf("read migration: %v", err)
+		t.Fatalf("list migrations: %v", err)
 	}
-	if _, err := db.Exec(string(migrationSQL)); err != nil {
-		t.Fatalf("apply migration: %v", err)
+	for _, migrationPath := range migrationPaths {
+		migrationSQL, err := ioutil.ReadFile(migrationPath)
+		if err != nil {
+			t.Fatalf("read migration: %v", err)
+		}
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			t.Fatalf("apply migration %s: %v", migrationPath, err)
+		}
 	}
 
 	now := time.Now().UTC()
@@ -73,7 +79,7 @@ func TestOrderRepository_CRUD(t *testing.T) {
 	}
 	_, _ = db.Exec(`INSERT INTO clients (user_id,name,phone) VALUES ($1,'Test Client','+10000000000')`, clientID)
 
-	repo := repository.NewOrderRepository(db, zap.NewNop())
+	repo := repository.NewOrderRepository(db, 5*time.Second, zap.NewNop())
 
 	order := &entity.Order{
 		ClientID:        clientID,
@@ -81,14 +87,14 @@ func TestOrderRepository_CRUD(t *testing.T) {
 		DeliveryAddress: "123 Test St",
 		DeliveryCoords:  "10.0,20.0",
 	}
-	if err := repo.Create(order); err != nil {
+	if err := repo.Create(context.Background(), order); err != nil {
 		t.Fatalf("Create(): %v", err)
 	}
 	if order.ID == uuid.Nil {
 		t.Fatal("ID not set after Create()")
 	}
 
-	got, err := repo.GetByID(order.ID)
+	got, err := repo.GetByID(context.Background(), order.ID)
 	if err != nil {
 		t.Fatalf("GetByID(): %v", err)
 	}
@@ -96,7 +102,7 @@ func TestOrderRepository_CRUD(t *testing.T) {
 		t.Fatalf("clientID mismatch")
 	}
 
-	all, err := repo.GetAll()
+	all, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Fatalf("GetAll(): %v", err)
 	}
@@ -107,18 +113,18 @@ func TestOrderRepository_CRUD(t *testing.T) {
 	got.Status = entity.StatusAssigned
 	got.DeliveryAddress = "456 New St"
 	got.DeliveryCoords = "30.0,40.0"
-	if err := repo.Update(got); err != nil {
+	if err := repo.Update(context.Background(), got); err != nil {
 		t.Fatalf("Update(): %v", err)
 	}
-	updated, _ := repo.GetByID(order.ID)
+	updated, _ := repo.GetByID(context.Background(), order.ID)
 	if updated.Status != entity.StatusAssigned {
 		t.Fatalf("status not updated")
 	}
 
-	if err := repo.Delete(order.ID); err != nil {
+	if err := repo.Delete(context.Background(), order.ID); err != nil {
 		t.Fatalf("Delete(): %v", err)
 	}
-	if _, err := repo.GetByID(order.ID); err == nil {
+	if _, err := repo.GetByID(context.Background(), order.ID); err == nil {
 		t.Fatalf("expected error after delete")
 	}
 }
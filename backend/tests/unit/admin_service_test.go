@@ -0,0 +1,76 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeAdminRepo struct {
+	admins map[uuid.UUID]*entity.Admin
+}
+
+func (f *fakeAdminRepo) Create(u *entity.User, ps entity.Permissions) error {
+	u.ID = uuid.New()
+	f.admins[u.ID] = &entity.Admin{UserID: u.ID, Email: u.Email, Permissions: ps}
+	return nil
+}
+
+func (f *fakeAdminRepo) GetByID(id uuid.UUID) (*entity.Admin, error) {
+	a, ok := f.admins[id]
+	if !ok {
+		return nil, repository.ErrAdminNotFound
+	}
+	cp := *a
+	cp.Permissions = append(entity.Permissions{}, a.Permissions...)
+	return &cp, nil
+}
+
+func (f *fakeAdminRepo) List() ([]*entity.Admin, error) {
+	var list []*entity.Admin
+	for _, a := range f.admins {
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+func (f *fakeAdminRepo) SetPermissions(id uuid.UUID, ps entity.Permissions) error {
+	f.admins[id].Permissions = ps
+	return nil
+}
+
+func TestAdminPermissions_GrantRevoke(t *testing.T) {
+	svc := service.NewAdminService(&fakeAdminRepo{admins: make(map[uuid.UUID]*entity.Admin)}, zap.NewNop())
+
+	_, err := svc.CreateAdmin(context.Background(), "agent@example.com", "password1", entity.Permissions{"orders:delete"})
+	assert.ErrorIs(t, err, service.ErrUnknownPermission)
+
+	owner, err := svc.CreateAdmin(context.Background(), "owner@example.com", "password1", entity.Permissions{entity.PermUsersManage})
+	assert.NoError(t, err)
+	agent, err := svc.CreateAdmin(context.Background(), "agent@example.com", "password1",
+		entity.Permissions{entity.PermOrdersRead, entity.PermOrdersRead})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Permissions{entity.PermOrdersRead}, agent.Permissions)
+
+	a, err := svc.Grant(context.Background(), agent.UserID, entity.PermOrdersCancel)
+	assert.NoError(t, err)
+	assert.True(t, a.Permissions.Has(entity.PermOrdersCancel))
+	assert.False(t, a.Permissions.Has(entity.PermPricingEdit))
+
+	_, err = svc.Revoke(context.Background(), owner.UserID, agent.UserID, entity.PermOrdersCancel)
+	assert.NoError(t, err)
+	perms, err := svc.Permissions(context.Background(), agent.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Permissions{entity.PermOrdersRead}, perms)
+
+	// отозвать у себя управление правами нельзя
+	_, err = svc.Revoke(context.Background(), owner.UserID, owner.UserID, entity.PermUsersManage)
+	assert.ErrorIs(t, err, service.ErrSelfLockout)
+}
@@ -0,0 +1,125 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/audit"
+	"backend/internal/entity"
+	"backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeAuditRecorder struct {
+	events []*entity.AuditEvent
+}
+
+func (f *fakeAuditRecorder) Record(_ context.Context, e *entity.AuditEvent) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func TestDiff(t *testing.T) {
+	before := &entity.Order{Status: entity.StatusCreated, DeliveryAddress: "A"}
+	after := &entity.Order{Status: entity.StatusCanceled, DeliveryAddress: "A"}
+
+	changes, err := audit.Diff(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]entity.Change{
+		"status": {From: "CREATED", To: "CANCELED"},
+	}, changes)
+
+	// удаление: все поля уходят в nil
+	changes, err = audit.Diff(before, (*entity.Order)(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Change{From: "A", To: nil}, changes["delivery_address"])
+}
+
+func setupAuditRouter(rec *fakeAuditRecorder, orders map[uuid.UUID]*entity.Order) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Identify("testsecret"))
+	router.Use(middleware.Audit(rec, map[string]middleware.Snapshot{
+		"orders": func(_ context.Context, id uuid.UUID) (any, error) {
+			o, ok := orders[id]
+			if !ok {
+				return nil, nil
+			}
+			cp := *o
+			return &cp, nil
+		},
+	}, zap.NewNop()))
+
+	router.POST("/login", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"token": "secret"}) })
+	router.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/orders", func(c *gin.Context) {
+		id := uuid.New()
+		orders[id] = &entity.Order{ID: id, Status: entity.StatusCreated}
+		resp := *orders[id]
+		resp.DeliveryCode = "482913"
+		c.JSON(http.StatusCreated, &resp)
+	})
+	router.POST("/orders/:id/cancel", func(c *gin.Context) {
+		orders[uuid.MustParse(c.Param("id"))].Status = entity.StatusCanceled
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	rec := &fakeAuditRecorder{}
+	orders := map[uuid.UUID]*entity.Order{}
+	router := setupAuditRouter(rec, orders)
+	token := tokenFor(t, entity.RoleAdmin)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Request-ID", "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	do("POST", "/login")
+	assert.Empty(t, rec.events, "login must not be audited")
+
+	w := do("POST", "/orders")
+	assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+	if assert.Len(t, rec.events, 1) {
+		e := rec.events[0]
+		assert.Equal(t, "POST /orders", e.Action)
+		assert.Equal(t, "orders", e.EntityType)
+		assert.NotNil(t, e.EntityID)
+		assert.NotNil(t, e.ActorID)
+		assert.Equal(t, entity.RoleAdmin, e.ActorRole)
+		assert.Equal(t, "req-1", e.RequestID)
+		assert.Equal(t, entity.Change{From: nil, To: "CREATED"}, e.Changes["status"])
+		// код вручения из ответа не должен остаться в журнале
+		assert.NotContains(t, e.Changes, "delivery_code")
+		assert.NotContains(t, fmt.Sprint(e.Changes), "482913")
+	}
+
+	id := *rec.events[0].EntityID
+	do("GET", "/orders/"+id.String())
+	assert.Len(t, rec.events, 1, "reads are not audited")
+
+	do("POST", "/orders/"+id.String()+"/cancel")
+	if assert.Len(t, rec.events, 2) {
+		e := rec.events[1]
+		assert.Equal(t, "POST /orders/:id/cancel", e.Action)
+		assert.Equal(t, id, *e.EntityID)
+		assert.Equal(t, http.StatusOK, e.Status)
+		assert.Equal(t, map[string]entity.Change{
+			"status": {From: "CREATED", To: "CANCELED"},
+		}, e.Changes)
+	}
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/config"
+	"backend/internal/auth"
+	"backend/internal/entity"
+	"backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAdminRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/admin", middleware.Auth("testsecret"), middleware.RequireRole(entity.RoleAdmin))
+	admin.GET("/ping", func(c *gin.Context) {
+		id, _ := middleware.CurrentUserID(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": id})
+	})
+	return router
+}
+
+func tokenFor(t *testing.T, role entity.Role) string {
+	token, err := auth.GenerateToken(&config.Config{JWTSecret: "testsecret"}, uuid.New().String(), role)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuth_MissingToken(t *testing.T) {
+	router := setupAdminRouter()
+	req, _ := http.NewRequest("GET", "/admin/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuth_WrongRole(t *testing.T) {
+	router := setupAdminRouter()
+	req, _ := http.NewRequest("GET", "/admin/ping", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenFor(t, entity.RoleClient))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAuth_Admin(t *testing.T) {
+	router := setupAdminRouter()
+	req, _ := http.NewRequest("GET", "/admin/ping", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenFor(t, entity.RoleAdmin))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+type fakePermissions map[uuid.UUID]entity.Permissions
+
+func (f fakePermissions) Permissions(_ context.Context, id uuid.UUID) (entity.Permissions, error) {
+	return f[id], nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	support, owner := uuid.New(), uuid.New()
+	perms := fakePermissions{
+		support: {entity.PermOrdersRead, entity.PermOrdersCancel},
+		owner:   {entity.PermAll},
+	}
+	router := gin.New()
+	admin := router.Group("/admin", middleware.Auth("testsecret"), middleware.RequireRole(entity.RoleAdmin))
+	admin.PUT("/pricing/rules", middleware.RequirePermission(perms, entity.PermPricingEdit), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name  string
+		admin uuid.UUID
+		code  int
+	}{
+		{"support cannot edit pricing", support, http.StatusForbidden},
+		{"wildcard grants everything", owner, http.StatusOK},
+		{"admin without record", uuid.New(), http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := auth.GenerateToken(&config.Config{JWTSecret: "testsecret"}, tc.admin.String(), entity.RoleAdmin)
+			assert.NoError(t, err)
+			req, _ := http.NewRequest("PUT", "/admin/pricing/rules", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeClientRepo struct {
+	profiles  map[uuid.UUID]*entity.Client
+	addresses map[uuid.UUID]*entity.ClientAddress
+}
+
+func newFakeClientRepo(clients ...uuid.UUID) *fakeClientRepo {
+	f := &fakeClientRepo{
+		profiles:  make(map[uuid.UUID]*entity.Client),
+		addresses: make(map[uuid.UUID]*entity.ClientAddress),
+	}
+	for _, id := range clients {
+		f.profiles[id] = &entity.Client{UserID: id, Email: id.String() + "@example.com"}
+	}
+	return f
+}
+
+func (f *fakeClientRepo) GetProfile(id uuid.UUID) (*entity.Client, error) {
+	c, ok := f.profiles[id]
+	if !ok {
+		return nil, repository.ErrClientNotFound
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (f *fakeClientRepo) SaveProfile(c *entity.Client) error {
+	cp := *c
+	cp.Email = f.profiles[c.UserID].Email
+	f.profiles[c.UserID] = &cp
+	return nil
+}
+
+func (f *fakeClientRepo) CreateAddress(a *entity.ClientAddress) error {
+	a.ID = uuid.New()
+	f.addresses[a.ID] = a
+	return nil
+}
+
+func (f *fakeClientRepo) GetAddress(id uuid.UUID) (*entity.ClientAddress, error) {
+	a, ok := f.addresses[id]
+	if !ok {
+		return nil, repository.ErrAddressNotFound
+	}
+	return a, nil
+}
+
+func (f *fakeClientRepo) ListAddresses(clientID uuid.UUID) ([]*entity.ClientAddress, error) {
+	var list []*entity.ClientAddress
+	for _, a := range f.addresses {
+		if a.ClientID == clientID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeClientRepo) UpdateAddress(a *entity.ClientAddress) error {
+	f.addresses[a.ID] = a
+	return nil
+}
+
+func (f *fakeClientRepo) DeleteAddress(id uuid.UUID) error {
+	delete(f.addresses, id)
+	return nil
+}
+
+func TestUpdateProfile(t *testing.T) {
+	clientID := uuid.New()
+	svc := service.NewClientService(newFakeClientRepo(clientID), zap.NewNop())
+
+	_, err := svc.UpdateProfile(context.Background(), &entity.Client{UserID: clientID, Name: "  "})
+	assert.ErrorIs(t, err, service.ErrInvalidProfile)
+
+	_, err = svc.UpdateProfile(context.Background(), &entity.Client{UserID: uuid.New(), Name: "Bob"})
+	assert.ErrorIs(t, err, repository.ErrClientNotFound)
+
+	profile, err := svc.UpdateProfile(context.Background(), &entity.Client{UserID: clientID, Name: " Alice ", Phone: "+7 900 000-00-00"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", profile.Name)
+	assert.Equal(t, clientID.String()+"@example.com", profile.Email)
+}
+
+func TestAddressBook_OwnerOnly(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	svc := service.NewClientService(newFakeClientRepo(alice, bob), zap.NewNop())
+
+	home := &entity.ClientAddress{
+		ClientID: alice,
+		Label:    "Home",
+		Address:  "Lenina 1",
+		Location: entity.Coordinates{Latitude: 55.75, Longitude: 37.61},
+		Notes:    "code 42",
+	}
+	assert.NoError(t, svc.CreateAddress(context.Background(), home))
+
+	invalid := &entity.ClientAddress{ClientID: alice, Label: "Work", Address: "Somewhere", Location: entity.Coordinates{Latitude: 91}}
+	assert.ErrorIs(t, svc.CreateAddress(context.Background(), invalid), service.ErrInvalidAddress)
+
+	got, err := svc.GetAddress(context.Background(), alice, home.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "code 42", got.Notes)
+
+	// чужой адрес не виден и не меняется
+	_, err = svc.GetAddress(context.Background(), bob, home.ID)
+	assert.ErrorIs(t, err, repository.ErrAddressNotFound)
+	assert.ErrorIs(t, svc.DeleteAddress(context.Background(), bob, home.ID), repository.ErrAddressNotFound)
+	assert.ErrorIs(t, svc.UpdateAddress(context.Background(), &entity.ClientAddress{
+		ID: home.ID, ClientID: bob, Label: "Mine", Address: "Lenina 1",
+	}), repository.ErrAddressNotFound)
+
+	list, err := svc.ListAddresses(context.Background(), bob)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	assert.NoError(t, svc.DeleteAddress(context.Background(), alice, home.ID))
+	_, err = svc.GetAddress(context.Background(), alice, home.ID)
+	assert.ErrorIs(t, err, repository.ErrAddressNotFound)
+}
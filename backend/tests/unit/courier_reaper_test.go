@@ -0,0 +1,47 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// staleCourierRepo реализует только то, что нужно CourierReaper.
+type staleCourierRepo struct {
+	repository.CourierRepository
+	stale  []uuid.UUID
+	before time.Time
+}
+
+func (r *staleCourierRepo) MarkStaleOffline(_ context.Context, before time.Time) ([]uuid.UUID, error) {
+	r.before = before
+	return r.stale, nil
+}
+
+func TestCourierReaper_RequeuesStaleCourierOrders(t *testing.T) {
+	courierID := uuid.New()
+	orders := newFakeOrderService()
+	assigned := &entity.Order{ID: uuid.New(), CourierID: &courierID, Status: entity.StatusAssigned}
+	inTransit := &entity.Order{ID: uuid.New(), CourierID: &courierID, Status: entity.StatusInTransit}
+	orders.orders[assigned.ID] = assigned
+	orders.orders[inTransit.ID] = inTransit
+
+	repo := &staleCourierRepo{stale: []uuid.UUID{courierID}}
+	reaper := service.NewCourierReaper(repo, orders, 5*time.Minute, time.Minute, zap.NewNop())
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, reaper.ReapOnce(context.Background(), now))
+
+	assert.Equal(t, now.Add(-5*time.Minute), repo.before)
+	assert.Equal(t, entity.StatusCreated, assigned.Status)
+	assert.Nil(t, assigned.CourierID)
+	assert.Equal(t, entity.StatusInTransit, inTransit.Status)
+}
@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// accountCourierRepo — курьер в памяти для проверки админских операций.
+type accountCourierRepo struct {
+	shiftCourierRepo
+	deleted bool
+}
+
+func (r *accountCourierRepo) SetAccountStatus(_ context.Context, id uuid.UUID, status entity.AccountStatus, reason string, at time.Time) error {
+	r.courier.AccountStatus = status
+	r.courier.StatusReason = reason
+	r.courier.AccountStatusChangedAt = &at
+	return nil
+}
+
+func (r *accountCourierRepo) Delete(_ context.Context, id uuid.UUID) error {
+	r.deleted = true
+	return nil
+}
+
+func newAccountFixture(retention time.Duration) (service.CourierService, *accountCourierRepo) {
+	repo := &accountCourierRepo{shiftCourierRepo: shiftCourierRepo{courier: &entity.Courier{
+		UserID:        uuid.New(),
+		Status:        entity.CourierStatusAvailable,
+		AccountStatus: entity.AccountActive,
+		Capacity:      1,
+		Location:      &entity.Coordinates{},
+	}}}
+	svc := service.NewCourierService(repo, nil, nil, nil, newFakeOrderService(),
+		service.CourierOptions{Retention: retention}, zap.NewNop())
+	return svc, repo
+}
+
+func TestSuspendCourier_TakesOffLine(t *testing.T) {
+	svc, repo := newAccountFixture(time.Hour)
+	id := repo.courier.UserID
+
+	courier, err := svc.SuspendCourier(context.Background(), id, "fake GPS")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.AccountSuspended, courier.AccountStatus)
+	assert.Equal(t, "fake GPS", courier.StatusReason)
+	assert.Equal(t, entity.CourierStatusOffline, courier.Status)
+
+	// заблокированный курьер не может сам выйти на линию
+	err = svc.UpdateCourierStatus(context.Background(), id, entity.CourierStatusAvailable, 0)
+	assert.ErrorIs(t, err, service.ErrCourierSuspended)
+
+	_, err = svc.ReactivateCourier(context.Background(), id)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.UpdateCourierStatus(context.Background(), id, entity.CourierStatusAvailable, 0))
+}
+
+func TestUpdateCourierStatus_VersionCheck(t *testing.T) {
+	svc, repo := newAccountFixture(time.Hour)
+	id := repo.courier.UserID
+	repo.courier.Version = 3
+
+	err := svc.UpdateCourierStatus(context.Background(), id, entity.CourierStatusOffline, 2)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict, "курьер изменился после чтения клиентом")
+	assert.Equal(t, entity.CourierStatusAvailable, repo.courier.Status)
+
+	assert.NoError(t, svc.UpdateCourierStatus(context.Background(), id, entity.CourierStatusOffline, 3))
+	assert.Equal(t, 4, repo.courier.Version)
+
+	_, err = svc.SetVehicle(context.Background(), id, entity.VehicleBike, 0, 0, 3)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+}
+
+func TestDeleteCourier_RetentionRules(t *testing.T) {
+	svc, repo := newAccountFixture(30 * 24 * time.Hour)
+	id := repo.courier.UserID
+
+	// активного курьера удалить нельзя
+	assert.ErrorIs(t, svc.DeleteCourier(context.Background(), id), service.ErrRetentionNotElapsed)
+
+	_, err := svc.DeactivateCourier(context.Background(), id, "left the company")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, svc.DeleteCourier(context.Background(), id), service.ErrRetentionNotElapsed)
+	assert.False(t, repo.deleted)
+
+	old := time.Now().Add(-31 * 24 * time.Hour)
+	repo.courier.AccountStatusChangedAt = &old
+	assert.NoError(t, svc.DeleteCourier(context.Background(), id))
+	assert.True(t, repo.deleted)
+}
+
+var _ repository.CourierRepository = (*accountCourierRepo)(nil)
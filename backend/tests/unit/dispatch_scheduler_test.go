@@ -0,0 +1,129 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// windowOrderRepo отдаёт заранее заданные выборки планировщика.
+type windowOrderRepo struct {
+	repository.OrderRepository
+	created []*entity.Order
+	due     []*entity.Order
+	late    []*entity.Order
+	flagged map[uuid.UUID]bool
+	now     time.Time
+}
+
+func (r *windowOrderRepo) Create(_ context.Context, o *entity.Order) error {
+	r.created = append(r.created, o)
+	return nil
+}
+
+func (r *windowOrderRepo) ListDueForDispatch(_ context.Context, now time.Time) ([]*entity.Order, error) {
+	r.now = now
+	return r.due, nil
+}
+
+func (r *windowOrderRepo) ListWindowAtRisk(_ context.Context, now time.Time, lead time.Duration) ([]*entity.Order, error) {
+	return r.late, nil
+}
+
+func (r *windowOrderRepo) MarkWindowAtRisk(_ context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	if r.flagged[id] {
+		return false, nil
+	}
+	r.flagged[id] = true
+	return true, nil
+}
+
+// recordingDispatcher запоминает заказы, отправленные в автоназначение.
+type recordingDispatcher struct {
+	service.OrderService
+	assigned []uuid.UUID
+	err      error
+}
+
+func (d *recordingDispatcher) AssignCourierToOrder(ctx context.Context, id uuid.UUID) error {
+	d.assigned = append(d.assigned, id)
+	return d.err
+}
+
+func TestDispatchScheduler_ReleasesAndFlags(t *testing.T) {
+	end := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	due := &entity.Order{ID: uuid.New(), Status: entity.StatusCreated, WindowEnd: &end}
+	late := &entity.Order{ID: uuid.New(), Status: entity.StatusAssigned, WindowEnd: &end}
+	repo := &windowOrderRepo{
+		due:     []*entity.Order{due},
+		late:    []*entity.Order{late},
+		flagged: make(map[uuid.UUID]bool),
+	}
+	orders := &recordingDispatcher{err: service.ErrNoCourierAvailable}
+	scheduler := service.NewDispatchScheduler(repo, orders, 45*time.Minute, time.Minute, zap.NewNop())
+
+	now := end.Add(-time.Hour)
+	assert.NoError(t, scheduler.RunOnce(context.Background(), now))
+	assert.Equal(t, now, repo.now)
+	assert.Equal(t, []uuid.UUID{due.ID}, orders.assigned, "нет курьера — не ошибка")
+	assert.True(t, repo.flagged[late.ID])
+}
+
+func newWindowOrderService(repo *windowOrderRepo, lead time.Duration) service.OrderService {
+	proofs := service.NewProofService(nil, nil, 0, nil)
+	return service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil, proofs, nil, service.OrderOptions{
+		DispatchLeadTime: lead,
+	}, nil)
+}
+
+func TestCreateOrder_DeliveryWindow(t *testing.T) {
+	repo := &windowOrderRepo{flagged: make(map[uuid.UUID]bool)}
+	svc := newWindowOrderService(repo, 45*time.Minute)
+	newOrder := func(start, end *time.Time) *entity.Order {
+		return &entity.Order{
+			ClientID:       uuid.New(),
+			Status:         entity.StatusCreated,
+			DeliveryCoords: "55.75,37.61",
+			WindowStart:    start,
+			WindowEnd:      end,
+		}
+	}
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().UTC().Add(d)
+		return &t
+	}
+
+	// окно через 3 часа — заказ ждёт, пока до него не останется 45 минут
+	o, err := svc.CreateOrder(context.Background(), newOrder(at(3*time.Hour), at(4*time.Hour)))
+	assert.NoError(t, err)
+	if assert.NotNil(t, o.ReleaseAt) {
+		assert.Equal(t, o.WindowStart.Add(-45*time.Minute), *o.ReleaseAt)
+	}
+	assert.True(t, o.Scheduled(time.Now()))
+
+	// окно скоро — назначать можно сразу
+	o, err = svc.CreateOrder(context.Background(), newOrder(at(10*time.Minute), at(time.Hour)))
+	assert.NoError(t, err)
+	assert.Nil(t, o.ReleaseAt)
+
+	// только крайний срок
+	o, err = svc.CreateOrder(context.Background(), newOrder(nil, at(time.Hour)))
+	assert.NoError(t, err)
+	assert.Nil(t, o.ReleaseAt)
+
+	_, err = svc.CreateOrder(context.Background(), newOrder(at(2*time.Hour), at(time.Hour)))
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	_, err = svc.CreateOrder(context.Background(), newOrder(nil, at(-time.Minute)))
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	assert.Len(t, repo.created, 3)
+}
@@ -0,0 +1,227 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeLedgerRepo struct {
+	repository.LedgerRepository
+	posted  []*entity.LedgerTransaction
+	orders  map[string]bool
+	balance int64
+	unpaid  []uuid.UUID
+}
+
+func (f *fakeLedgerRepo) Post(_ context.Context, t *entity.LedgerTransaction) error {
+	if t.OrderID != nil {
+		key := string(t.Kind) + t.OrderID.String()
+		if f.orders[key] {
+			return repository.ErrLedgerDuplicate
+		}
+		f.orders[key] = true
+	}
+	f.posted = append(f.posted, t)
+	return nil
+}
+
+func (f *fakeLedgerRepo) ListByCourier(_ context.Context, courierID uuid.UUID, from, to time.Time) ([]*entity.LedgerTransaction, error) {
+	var ret []*entity.LedgerTransaction
+	for _, t := range f.posted {
+		if t.CourierID == courierID && !t.CreatedAt.Before(from) && t.CreatedAt.Before(to) {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (f *fakeLedgerRepo) Balance(context.Context, uuid.UUID, string) (int64, error) {
+	return f.balance, nil
+}
+
+func (f *fakeLedgerRepo) UnpaidDeliveries(context.Context, time.Time, int) ([]uuid.UUID, error) {
+	return f.unpaid, nil
+}
+
+type ledgerOrderRepo struct {
+	repository.OrderRepository
+	order *entity.Order
+}
+
+func (r *ledgerOrderRepo) GetByID(_ context.Context, id uuid.UUID) (*entity.Order, error) {
+	if r.order == nil || r.order.ID != id {
+		return nil, repository.ErrOrderNotFound
+	}
+	return r.order, nil
+}
+
+func newEarnings(ledger *fakeLedgerRepo, orders repository.OrderRepository) service.EarningsService {
+	return service.NewEarningsService(ledger, orders, nil, service.EarningsOptions{
+		BasePay:  15000,
+		PerKm:    1000,
+		Currency: "RUB",
+	}, nil)
+}
+
+func deliveredOrder() *entity.Order {
+	courierID := uuid.New()
+	surge := 1.5
+	return &entity.Order{
+		ID:              uuid.New(),
+		ClientID:        uuid.New(),
+		CourierID:       &courierID,
+		Status:          entity.StatusDelivered,
+		SurgeMultiplier: &surge,
+		Stops: []entity.OrderStop{
+			// точки нарочно не по порядку: считать надо по Sequence
+			{Sequence: 2, Kind: entity.StopDropoff, Location: entity.Coordinates{Latitude: 55.77, Longitude: 37.62}},
+			{Sequence: 1, Kind: entity.StopPickup, Location: entity.Coordinates{Latitude: 55.75, Longitude: 37.62}},
+		},
+	}
+}
+
+func TestDeliveryEarnings(t *testing.T) {
+	order := deliveredOrder()
+	parts := service.DeliveryEarnings(order, 15000, 1000)
+
+	assert.Equal(t, int64(15000), parts[entity.EarningBase])
+	// 0.02° широты ≈ 2.22 км
+	assert.InDelta(t, 2224, parts[entity.EarningDistance], 2)
+	assert.Equal(t, (parts[entity.EarningBase]+parts[entity.EarningDistance])/2, parts[entity.EarningBonus])
+
+	order.SurgeMultiplier = nil
+	assert.NotContains(t, service.DeliveryEarnings(order, 15000, 1000), entity.EarningBonus)
+}
+
+func TestEarningsService_RecordDelivery(t *testing.T) {
+	ledger := &fakeLedgerRepo{orders: map[string]bool{}}
+	svc := newEarnings(ledger, nil)
+	order := deliveredOrder()
+
+	assert.NoError(t, svc.RecordDelivery(context.Background(), order))
+	assert.NoError(t, svc.RecordDelivery(context.Background(), order), "повторное начисление молча пропускается")
+	if assert.Len(t, ledger.posted, 1) {
+		tx := ledger.posted[0]
+		assert.True(t, tx.Balanced())
+		assert.Equal(t, *order.CourierID, tx.CourierID)
+		assert.Len(t, tx.Entries, 6)
+		for _, e := range tx.Entries {
+			if e.Account == entity.AccountCourierPayable {
+				assert.Negative(t, e.Amount, "долг перед курьером — в кредите")
+			}
+		}
+	}
+
+	order.Status = entity.StatusInTransit
+	order.ID = uuid.New()
+	assert.NoError(t, svc.RecordDelivery(context.Background(), order))
+	assert.Len(t, ledger.posted, 1, "недоставленный заказ не оплачивается")
+}
+
+func TestEarningsReconciler_RecordsMissingDeliveries(t *testing.T) {
+	order := deliveredOrder()
+	// второй заказ уже удалён: его пропускаем, не останавливая сверку
+	ledger := &fakeLedgerRepo{orders: map[string]bool{}, unpaid: []uuid.UUID{uuid.New(), order.ID}}
+	orders := &ledgerOrderRepo{order: order}
+	r := service.NewEarningsReconciler(ledger, orders, newEarnings(ledger, orders), time.Minute, zap.NewNop())
+
+	assert.NoError(t, r.RunOnce(context.Background(), time.Now()))
+	if assert.Len(t, ledger.posted, 1) {
+		assert.Equal(t, order.ID, *ledger.posted[0].OrderID)
+		assert.Equal(t, entity.LedgerDelivery, ledger.posted[0].Kind)
+	}
+
+	// повторный проход ничего не удваивает
+	assert.NoError(t, r.RunOnce(context.Background(), time.Now()))
+	assert.Len(t, ledger.posted, 1)
+}
+
+func TestEarningsService_Tip(t *testing.T) {
+	ledger := &fakeLedgerRepo{orders: map[string]bool{}}
+	order := deliveredOrder()
+	svc := newEarnings(ledger, &ledgerOrderRepo{order: order})
+
+	_, err := svc.Tip(context.Background(), uuid.New(), order.ID, 5000)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound, "чужой заказ")
+
+	tx, err := svc.Tip(context.Background(), order.ClientID, order.ID, 5000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5000), tx.Earned()[entity.EarningTip])
+
+	_, err = svc.Tip(context.Background(), order.ClientID, order.ID, 5000)
+	assert.ErrorIs(t, err, service.ErrAlreadyTipped)
+
+	_, err = svc.Tip(context.Background(), order.ClientID, order.ID, 0)
+	assert.ErrorIs(t, err, service.ErrInvalidTip)
+
+	order.Status = entity.StatusInTransit
+	order.ID = uuid.New()
+	_, err = svc.Tip(context.Background(), order.ClientID, order.ID, 5000)
+	assert.ErrorIs(t, err, service.ErrOrderNotDelivered)
+}
+
+func TestEarningsService_Adjust(t *testing.T) {
+	svc := newEarnings(&fakeLedgerRepo{orders: map[string]bool{}}, nil)
+
+	_, err := svc.Adjust(context.Background(), uuid.New(), service.AdjustmentInput{Amount: 0}, uuid.New())
+	assert.ErrorIs(t, err, service.ErrInvalidAdjustment)
+	_, err = svc.Adjust(context.Background(), uuid.New(), service.AdjustmentInput{Amount: 100, Component: entity.EarningTip}, uuid.New())
+	assert.ErrorIs(t, err, service.ErrInvalidAdjustment, "чаевые вручную не начисляются")
+}
+
+func TestEarningsService_Summary(t *testing.T) {
+	courierID := uuid.New()
+	// понедельник
+	monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	delivery := func(at time.Time, amount int64) *entity.LedgerTransaction {
+		tx := &entity.LedgerTransaction{Kind: entity.LedgerDelivery, CourierID: courierID, Currency: "RUB", CreatedAt: at}
+		tx.Credit(entity.AccountEarningsExpense, entity.EarningBase, amount)
+		return tx
+	}
+	payout := &entity.LedgerTransaction{Kind: entity.LedgerPayout, CourierID: courierID, Currency: "RUB", CreatedAt: monday.Add(30 * time.Hour)}
+	payout.Credit(entity.AccountPayoutsClearing, entity.EarningPayout, -20000)
+	adjustment := &entity.LedgerTransaction{Kind: entity.LedgerAdjustment, CourierID: courierID, Currency: "RUB", CreatedAt: monday.AddDate(0, 0, 8)}
+	adjustment.Credit(entity.AccountEarningsExpense, entity.EarningAdjustment, -3000)
+
+	ledger := &fakeLedgerRepo{balance: 7000, posted: []*entity.LedgerTransaction{
+		delivery(monday.Add(10*time.Hour), 10000),
+		delivery(monday.Add(20*time.Hour), 10000),
+		payout,
+		delivery(monday.AddDate(0, 0, 2), 10000),
+		adjustment,
+	}}
+	svc := newEarnings(ledger, nil)
+
+	sum, err := svc.Summary(context.Background(), courierID, entity.EarningsQuery{From: monday, To: monday.AddDate(0, 0, 14), Period: entity.EarningsByWeek})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(27000), sum.Total, "выплата не уменьшает заработок")
+	assert.Equal(t, 3, sum.Orders)
+	assert.Equal(t, int64(30000), sum.Components[entity.EarningBase])
+	assert.Equal(t, int64(-3000), sum.Components[entity.EarningAdjustment])
+	assert.Equal(t, int64(7000), sum.Balance)
+	if assert.Len(t, sum.Periods, 2) {
+		assert.Equal(t, monday, sum.Periods[0].Start)
+		assert.Equal(t, int64(30000), sum.Periods[0].Total)
+		assert.Equal(t, int64(-3000), sum.Periods[1].Total)
+	}
+
+	sum, err = svc.Summary(context.Background(), courierID, entity.EarningsQuery{From: monday, To: monday.AddDate(0, 0, 3)})
+	assert.NoError(t, err)
+	if assert.Len(t, sum.Periods, 3) {
+		assert.Equal(t, 2, sum.Periods[0].Orders)
+		assert.Equal(t, 0, sum.Periods[1].Orders)
+		assert.Equal(t, 1, sum.Periods[2].Orders)
+	}
+
+	_, err = svc.Summary(context.Background(), courierID, entity.EarningsQuery{From: monday, To: monday.AddDate(2, 0, 0)})
+	assert.ErrorIs(t, err, service.ErrInvalidEarningsPeriod)
+}
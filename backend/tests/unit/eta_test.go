@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/eta"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtaForRoute(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	a, b := uuid.New(), uuid.New()
+	route := &entity.Route{Stops: []entity.RouteStop{
+		{OrderStop: entity.OrderStop{OrderID: a, Kind: entity.StopPickup}, LegMeters: 600},
+		{OrderStop: entity.OrderStop{OrderID: b, Kind: entity.StopDropoff}, LegMeters: 1200},
+		{OrderStop: entity.OrderStop{OrderID: a, Kind: entity.StopDropoff}, LegMeters: 600},
+	}}
+
+	// 10 м/с и минута на каждой точке
+	got := eta.ForRoute(route, 10, time.Minute, now)
+
+	assert.Equal(t, now.Add(time.Minute), *got[a].PickupAt)
+	assert.Equal(t, now.Add(6*time.Minute), *got[a].DeliveryAt)
+	assert.Nil(t, got[b].PickupAt)
+	assert.Equal(t, now.Add(4*time.Minute), *got[b].DeliveryAt)
+}
+
+func TestEtaAverageSpeed(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// ~111 м по долготе на экваторе каждые 10 секунд ≈ 11 м/с
+	var fixes []entity.LocationFix
+	for i := 0; i < 10; i++ {
+		fixes = append(fixes, entity.LocationFix{
+			Location:   entity.Coordinates{Longitude: float64(i) * 0.001},
+			RecordedAt: start.Add(time.Duration(i) * 10 * time.Second),
+		})
+	}
+
+	speed, ok := eta.AverageSpeed(fixes, 5*time.Minute)
+	assert.True(t, ok)
+	assert.InDelta(t, 11.1, speed, 0.2)
+}
+
+func TestEtaAverageSpeed_NotEnoughHistory(t *testing.T) {
+	start := time.Now()
+	fixes := []entity.LocationFix{
+		{Location: entity.Coordinates{}, RecordedAt: start},
+		{Location: entity.Coordinates{Longitude: 0.001}, RecordedAt: start.Add(10 * time.Second)},
+	}
+	_, ok := eta.AverageSpeed(fixes, 5*time.Minute)
+	assert.False(t, ok)
+
+	// разрыв больше maxGap не учитывается
+	fixes[1].RecordedAt = start.Add(time.Hour)
+	_, ok = eta.AverageSpeed(fixes, 5*time.Minute)
+	assert.False(t, ok)
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/geofence"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var geofenceCfg = geofence.Config{Radius: 50, ExitRadius: 75, Dwell: 30 * time.Second}
+
+func TestGeofenceStep_ArrivesAfterDwell(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	st, arrived := geofence.Step(geofenceCfg, geofence.State{}, 40, start)
+	assert.False(t, arrived)
+	assert.Equal(t, start, *st.EnteredAt)
+
+	st, arrived = geofence.Step(geofenceCfg, st, 30, start.Add(20*time.Second))
+	assert.False(t, arrived)
+
+	st, arrived = geofence.Step(geofenceCfg, st, 10, start.Add(30*time.Second))
+	assert.True(t, arrived)
+	assert.Equal(t, start.Add(30*time.Second), *st.ArrivedAt)
+
+	// прибытие фиксируется один раз
+	_, arrived = geofence.Step(geofenceCfg, st, 5, start.Add(time.Minute))
+	assert.False(t, arrived)
+}
+
+func TestGeofenceStep_Hysteresis(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	st, _ := geofence.Step(geofenceCfg, geofence.State{}, 45, start)
+
+	// дрожание GPS между Radius и ExitRadius не сбрасывает вход
+	st, arrived := geofence.Step(geofenceCfg, st, 60, start.Add(10*time.Second))
+	assert.False(t, arrived)
+	assert.NotNil(t, st.EnteredAt)
+
+	// выход за ExitRadius сбрасывает
+	st, _ = geofence.Step(geofenceCfg, st, 80, start.Add(20*time.Second))
+	assert.Nil(t, st.EnteredAt)
+
+	st, arrived = geofence.Step(geofenceCfg, st, 40, start.Add(40*time.Second))
+	assert.False(t, arrived)
+	assert.Equal(t, start.Add(40*time.Second), *st.EnteredAt)
+}
@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/geo"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	// пример из описания формата
+	p := entity.Coordinates{Latitude: 57.64911, Longitude: 10.40744}
+	assert.Equal(t, "u4pruydqqvj", geo.Geohash(p, 11))
+	assert.Equal(t, "u4pru", geo.Geohash(p, 5))
+
+	sw, ne, err := geo.GeohashBounds("u4pruydqqvj")
+	assert.NoError(t, err)
+	assert.True(t, sw.Latitude <= p.Latitude && p.Latitude <= ne.Latitude)
+	assert.True(t, sw.Longitude <= p.Longitude && p.Longitude <= ne.Longitude)
+	assert.InDelta(t, p.Latitude, (sw.Latitude+ne.Latitude)/2, 1e-5)
+
+	_, _, err = geo.GeohashBounds("u4pa")
+	assert.ErrorIs(t, err, geo.ErrInvalidGeohash)
+}
+
+type fakeHeatmapRepo struct {
+	repository.HeatmapRepository
+	query entity.HeatmapQuery
+	cells []*entity.HeatmapCell
+}
+
+func (f *fakeHeatmapRepo) Cells(q entity.HeatmapQuery) ([]*entity.HeatmapCell, error) {
+	f.query = q
+	return f.cells, nil
+}
+
+func TestHeatmapService_GeoJSON(t *testing.T) {
+	repo := &fakeHeatmapRepo{cells: []*entity.HeatmapCell{
+		{Geohash: "ucfv0j", Demand: 4, Supply: 2},
+		{Geohash: "ucfv0k", Demand: 0, Supply: 3},
+	}}
+	svc := service.NewHeatmapService(repo, nil, nil)
+
+	fc, err := svc.Heatmap(context.Background(), entity.HeatmapQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, repo.query.Precision)
+	assert.Equal(t, time.Hour, repo.query.To.Sub(repo.query.From))
+
+	data, err := json.Marshal(fc)
+	assert.NoError(t, err)
+	var got struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string        `json:"type"`
+				Coordinates [][][]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "FeatureCollection", got.Type)
+	if assert.Len(t, got.Features, 2) {
+		f := got.Features[0]
+		assert.Equal(t, "Polygon", f.Geometry.Type)
+		assert.Len(t, f.Geometry.Coordinates[0], 5)
+		assert.Equal(t, 0.5, f.Properties["ratio"])
+		assert.Nil(t, got.Features[1].Properties["ratio"], "без заказов соотношение не определено")
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = svc.Heatmap(context.Background(), entity.HeatmapQuery{From: from, To: from.AddDate(0, 1, 0)})
+	assert.ErrorIs(t, err, service.ErrInvalidHeatmapPeriod)
+}
@@ -0,0 +1,210 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeIdempotencyStore struct {
+	records map[string]*entity.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyStore) Reserve(_ context.Context, rec *entity.IdempotencyRecord, staleBefore time.Time) (*entity.IdempotencyRecord, error) {
+	if existing, ok := f.records[rec.Scope+"/"+rec.Key]; ok {
+		cp := *existing
+		return &cp, nil
+	}
+	cp := *rec
+	f.records[rec.Scope+"/"+rec.Key] = &cp
+	return nil, nil
+}
+
+func (f *fakeIdempotencyStore) Complete(_ context.Context, rec *entity.IdempotencyRecord) error {
+	cp := *rec
+	f.records[rec.Scope+"/"+rec.Key] = &cp
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(_ context.Context, scope, key string) error {
+	delete(f.records, scope+"/"+key)
+	return nil
+}
+
+func setupIdempotencyRouter(store *fakeIdempotencyStore, created *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.Identify("testsecret"))
+	router.Use(middleware.Idempotency(store, time.Hour, 1<<10, zap.NewNop()))
+
+	router.POST("/orders", func(c *gin.Context) {
+		var req struct {
+			Address string `json:"address"`
+		}
+		c.ShouldBindJSON(&req)
+		*created++
+		c.JSON(http.StatusCreated, gin.H{"id": uuid.NewString(), "address": req.Address})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		*created++
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+	})
+	router.POST("/panic", func(c *gin.Context) {
+		*created++
+		panic("boom")
+	})
+	return router
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*entity.IdempotencyRecord{}}
+	var created int
+	router := setupIdempotencyRouter(store, &created)
+
+	do := func(path, key, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do("/orders", "k1", `{"address":"A"}`, "")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	retry := do("/orders", "k1", `{"address":"A"}`, "")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String(), "повтор получает исходный ответ")
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 1, created)
+
+	w := do("/orders", "k1", `{"address":"B"}`, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, created)
+
+	// ключи у каждого пользователя свои
+	w = do("/orders", "k1", `{"address":"A"}`, tokenFor(t, entity.RoleClient))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, created)
+
+	do("/orders", "", `{"address":"A"}`, "")
+	assert.Equal(t, 3, created, "без ключа запрос выполняется как обычно")
+
+	do("/fail", "k2", `{}`, "")
+	do("/fail", "k2", `{}`, "")
+	assert.Equal(t, 5, created, "ответ 5xx не сохраняется, запрос можно повторить")
+
+	w = do("/orders", strings.Repeat("x", 256), `{}`, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*entity.IdempotencyRecord{}}
+	var created int
+	router := setupIdempotencyRouter(store, &created)
+
+	body := `{"address":"A"}`
+	// первый запрос ещё выполняется: запись занята, ответа нет
+	req, _ := http.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	rec := store.records["anon:192.0.2.1/k1"]
+	rec.Status, rec.Body = 0, nil
+
+	req, _ = http.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 1, created)
+}
+
+func TestIdempotencyMiddleware_AnonymousScope(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*entity.IdempotencyRecord{}}
+	var created int
+	router := setupIdempotencyRouter(store, &created)
+
+	do := func(addr, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/orders", strings.NewReader(body))
+		req.RemoteAddr = addr
+		req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusCreated, do("198.51.100.1:1000", `{"address":"A"}`).Code)
+	// чужой клиент с тем же ключом не получает чужой ответ и не упирается в 422
+	w := do("198.51.100.2:1000", `{"address":"B"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, created)
+
+	w = do("198.51.100.1:2000", `{"address":"A"}`)
+	assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, created)
+
+	// подставленный X-Forwarded-For не открывает чужое пространство ключей
+	req, _ := http.NewRequest("POST", "/orders", strings.NewReader(`{"address":"A"}`))
+	req.RemoteAddr = "198.51.100.3:1000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 3, created)
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*entity.IdempotencyRecord{}}
+	var created int
+	router := setupIdempotencyRouter(store, &created)
+
+	body := `{"address":"` + strings.Repeat("a", 2<<10) + `"}`
+	req, _ := http.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 0, created)
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotencyMiddleware_PanicReleasesKey(t *testing.T) {
+	store := &fakeIdempotencyStore{records: map[string]*entity.IdempotencyRecord{}}
+	var created int
+	router := setupIdempotencyRouter(store, &created)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "/panic", strings.NewReader(`{}`))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	}
+	assert.Equal(t, 2, created, "после паники запрос можно повторить с тем же ключом")
+	assert.Empty(t, store.records)
+}
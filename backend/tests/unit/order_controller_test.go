@@ -12,6 +12,8 @@ import (
 
 	"backend/internal/controller"
 	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,6 +36,7 @@ func (f *fakeOrderService) CreateOrder(ctx context.Context, order *entity.Order)
 		return nil, errors.New("client does not exist")
 	}
 	order.ID = uuid.New()
+	order.Version = 1
 	now := time.Now()
 	order.CreatedAt = now
 	order.UpdatedAt = now
@@ -45,7 +48,42 @@ func (f *fakeOrderService) AssignCourierToOrder(ctx context.Context, orderID uui
 	return nil
 }
 
-func (f *fakeOrderService) GetOrderByID(id uuid.UUID) (*entity.Order, error) {
+func (f *fakeOrderService) AssignCourier(ctx context.Context, orderID, courierID uuid.UUID) error {
+	order, exists := f.orders[orderID]
+	if !exists {
+		return errors.New("order not found")
+	}
+	if courierID == fullCourierID {
+		return service.ErrCourierAtCapacity
+	}
+	order.CourierID = &courierID
+	order.Status = entity.StatusAssigned
+	return nil
+}
+
+func (f *fakeOrderService) CompleteStop(ctx context.Context, courierID, orderID, stopID uuid.UUID, proof *service.ProofInput) (*entity.Order, error) {
+	order, exists := f.orders[orderID]
+	if !exists {
+		return nil, errors.New("order not found")
+	}
+	// как и репозиторий, закрытие точки увеличивает версию заказа
+	order.Version++
+	return order, nil
+}
+
+func (f *fakeOrderService) RequeueCourierOrders(ctx context.Context, courierID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, o := range f.orders {
+		if o.CourierID != nil && *o.CourierID == courierID && o.Status == entity.StatusAssigned {
+			o.CourierID = nil
+			o.Status = entity.StatusCreated
+			ids = append(ids, o.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeOrderService) GetOrderByID(_ context.Context, id uuid.UUID) (*entity.Order, error) {
 	order, exists := f.orders[id]
 	if !exists {
 		return nil, errors.New("order not found")
@@ -53,7 +91,7 @@ func (f *fakeOrderService) GetOrderByID(id uuid.UUID) (*entity.Order, error) {
 	return order, nil
 }
 
-func (f *fakeOrderService) GetAllOrders() ([]*entity.Order, error) {
+func (f *fakeOrderService) GetAllOrders(_ context.Context) ([]*entity.Order, error) {
 	var orders []*entity.Order
 	for _, o := range f.orders {
 		orders = append(orders, o)
@@ -61,25 +99,53 @@ func (f *fakeOrderService) GetAllOrders() ([]*entity.Order, error) {
 	return orders, nil
 }
 
-func (f *fakeOrderService) UpdateOrder(order *entity.Order) error {
+func (f *fakeOrderService) GetTimeline(_ context.Context, id uuid.UUID) ([]entity.OrderEvent, error) {
+	order, exists := f.orders[id]
+	if !exists {
+		return nil, repository.ErrOrderNotFound
+	}
+	return []entity.OrderEvent{{
+		OrderID:   id,
+		Type:      entity.EventStatusChanged,
+		Status:    order.Status,
+		CreatedAt: order.CreatedAt,
+	}}, nil
+}
+
+func (f *fakeOrderService) UpdateOrder(_ context.Context, order *entity.Order) error {
 	_, exists := f.orders[order.ID]
 	if !exists {
 		return errors.New("order not found")
 	}
 	order.UpdatedAt = time.Now()
+	order.Version++
 	f.orders[order.ID] = order
 	return nil
 }
 
-func (f *fakeOrderService) DeleteOrder(id uuid.UUID) error {
+func (f *fakeOrderService) CancelOrder(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+	order, exists := f.orders[id]
+	if !exists {
+		return nil, repository.ErrOrderNotFound
+	}
+	if order.Status == entity.StatusDelivered || order.Status == entity.StatusCanceled {
+		return nil, service.ErrOrderNotCancelable
+	}
+	order.Status = entity.StatusCanceled
+	return order, nil
+}
+
+func (f *fakeOrderService) DeleteOrder(_ context.Context, id uuid.UUID) error {
 	_, exists := f.orders[id]
 	if !exists {
-		return errors.New("order not found")
+		return repository.ErrOrderNotFound
 	}
 	delete(f.orders, id)
 	return nil
 }
 
+var fullCourierID = uuid.MustParse("00000000-0000-0000-0000-0000000000ff")
+
 func setupOrderRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -88,8 +154,11 @@ func setupOrderRouter() *gin.Engine {
 	router.POST("/orders", oc.CreateOrder)
 	router.GET("/orders", oc.GetOrders)
 	router.GET("/orders/:id", oc.GetOrder)
+	router.GET("/orders/:id/timeline", oc.GetTimeline)
 	router.PUT("/orders/:id", oc.UpdateOrder)
 	router.DELETE("/orders/:id", oc.DeleteOrder)
+	router.POST("/orders/:id/assign", oc.AssignCourier)
+	router.POST("/me/orders/:id/stops/:stop_id/complete", oc.CompleteStop)
 	return router
 }
 
@@ -168,21 +237,92 @@ func TestUpdateOrder(t *testing.T) {
 	assert.NoError(t, err)
 
 	updateBody, _ := json.Marshal(map[string]interface{}{
-		"status":           string(entity.StatusAssigned),
+		"status":           string(entity.StatusCreated),
 		"delivery_address": "456 Elm St",
 		"delivery_coords":  "40.7128,-74.0060",
 	})
-	updateReq, _ := http.NewRequest("PUT", "/orders/"+order.ID.String(), bytes.NewBuffer(updateBody))
-	updateReq.Header.Set("Content-Type", "application/json")
-	updateRec := httptest.NewRecorder()
-	router.ServeHTTP(updateRec, updateReq)
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/orders/"+order.ID.String(), bytes.NewBuffer(updateBody))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusPreconditionRequired, put("").Code)
+
+	getReq, _ := http.NewRequest("GET", "/orders/"+order.ID.String(), nil)
+	getRec := httptest.NewRecorder()
+	router.ServeHTTP(getRec, getReq)
+	etag := getRec.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
 
+	updateRec := put(etag)
 	assert.Equal(t, http.StatusOK, updateRec.Code)
+	assert.Equal(t, `"2"`, updateRec.Header().Get("ETag"))
 	var updatedOrder entity.Order
 	err = json.Unmarshal(updateRec.Body.Bytes(), &updatedOrder)
 	assert.NoError(t, err)
-	assert.Equal(t, entity.StatusAssigned, updatedOrder.Status)
+	assert.Equal(t, entity.StatusCreated, updatedOrder.Status)
 	assert.Equal(t, "456 Elm St", updatedOrder.DeliveryAddress)
+
+	// второй клиент пишет по устаревшей версии
+	assert.Equal(t, http.StatusPreconditionFailed, put(etag).Code)
+	assert.Equal(t, http.StatusOK, put("*").Code)
+
+	// отмена идёт только через /admin/orders/{id}/cancel с правом orders:cancel
+	updateBody, _ = json.Marshal(map[string]interface{}{
+		"status":           string(entity.StatusCanceled),
+		"delivery_address": "456 Elm St",
+		"delivery_coords":  "40.7128,-74.0060",
+	})
+	assert.Equal(t, http.StatusConflict, put("*").Code)
+	getRec = httptest.NewRecorder()
+	router.ServeHTTP(getRec, getReq)
+	assert.NoError(t, json.Unmarshal(getRec.Body.Bytes(), &updatedOrder))
+	assert.Equal(t, entity.StatusCreated, updatedOrder.Status)
+}
+
+func TestUpdateOrder_StaleAfterCompleteStop(t *testing.T) {
+	router := setupOrderRouter()
+
+	createBody, _ := json.Marshal(map[string]string{
+		"client_id":        uuid.New().String(),
+		"delivery_address": "123 Main St",
+		"delivery_coords":  "37.7749,-122.4194",
+	})
+	createReq, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	assert.Equal(t, http.StatusCreated, createRec.Code)
+	var order entity.Order
+	assert.NoError(t, json.Unmarshal(createRec.Body.Bytes(), &order))
+
+	getReq, _ := http.NewRequest("GET", "/orders/"+order.ID.String(), nil)
+	getRec := httptest.NewRecorder()
+	router.ServeHTTP(getRec, getReq)
+	etag := getRec.Header().Get("ETag")
+
+	// курьер закрывает точку после того, как диспетчер прочитал заказ
+	completeReq, _ := http.NewRequest("POST", "/me/orders/"+order.ID.String()+"/stops/"+uuid.New().String()+"/complete", nil)
+	completeRec := httptest.NewRecorder()
+	router.ServeHTTP(completeRec, completeReq)
+	assert.Equal(t, http.StatusOK, completeRec.Code)
+
+	updateBody, _ := json.Marshal(map[string]interface{}{
+		"delivery_address": "456 Elm St",
+		"delivery_coords":  "40.7128,-74.0060",
+	})
+	req, _ := http.NewRequest("PUT", "/orders/"+order.ID.String(), bytes.NewBuffer(updateBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", etag)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
 }
 
 func TestDeleteOrder(t *testing.T) {
@@ -214,3 +354,114 @@ func TestDeleteOrder(t *testing.T) {
 	router.ServeHTTP(getRec, getReq)
 	assert.Equal(t, http.StatusNotFound, getRec.Code)
 }
+
+func TestCreateOrder_WithStops(t *testing.T) {
+	router := setupOrderRouter()
+	body, _ := json.Marshal(map[string]interface{}{
+		"client_id": uuid.New().String(),
+		"stops": []map[string]interface{}{
+			{"kind": "PICKUP", "address": "Warehouse", "latitude": 52.37, "longitude": 4.90},
+			{"kind": "DROPOFF", "address": "1 First St", "latitude": 52.36, "longitude": 4.91},
+			{"kind": "DROPOFF", "address": "2 Second St", "latitude": 52.35, "longitude": 4.92},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var order entity.Order
+	err := json.Unmarshal(w.Body.Bytes(), &order)
+	assert.NoError(t, err)
+	assert.Len(t, order.Stops, 3)
+	assert.Equal(t, entity.StopPickup, order.Stops[0].Kind)
+	assert.Equal(t, "2 Second St", order.DeliveryAddress)
+}
+
+func TestCreateOrder_MissingDelivery(t *testing.T) {
+	router := setupOrderRouter()
+	body, _ := json.Marshal(map[string]string{
+		"client_id": uuid.New().String(),
+	})
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAssignCourier(t *testing.T) {
+	router := setupOrderRouter()
+
+	createBody, _ := json.Marshal(map[string]string{
+		"client_id":        uuid.New().String(),
+		"delivery_address": "123 Main St",
+		"delivery_coords":  "37.7749,-122.4194",
+	})
+	createReq, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	assert.Equal(t, http.StatusCreated, createRec.Code)
+
+	var order entity.Order
+	err := json.Unmarshal(createRec.Body.Bytes(), &order)
+	assert.NoError(t, err)
+
+	courierID := uuid.New()
+	body, _ := json.Marshal(map[string]string{"courier_id": courierID.String()})
+	req, _ := http.NewRequest("POST", "/orders/"+order.ID.String()+"/assign", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var assigned entity.Order
+	err = json.Unmarshal(w.Body.Bytes(), &assigned)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusAssigned, assigned.Status)
+	assert.Equal(t, courierID, *assigned.CourierID)
+
+	body, _ = json.Marshal(map[string]string{"courier_id": fullCourierID.String()})
+	req, _ = http.NewRequest("POST", "/orders/"+order.ID.String()+"/assign", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestGetTimeline(t *testing.T) {
+	router := setupOrderRouter()
+
+	createBody, _ := json.Marshal(map[string]string{
+		"client_id":        uuid.New().String(),
+		"delivery_address": "123 Main St",
+		"delivery_coords":  "37.7749,-122.4194",
+	})
+	createReq, _ := http.NewRequest("POST", "/orders", bytes.NewBuffer(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	assert.Equal(t, http.StatusCreated, createRec.Code)
+
+	var order entity.Order
+	assert.NoError(t, json.Unmarshal(createRec.Body.Bytes(), &order))
+
+	req, _ := http.NewRequest("GET", "/orders/"+order.ID.String()+"/timeline", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var events []entity.OrderEvent
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	assert.Len(t, events, 1)
+	assert.Equal(t, entity.EventStatusChanged, events[0].Type)
+
+	req, _ = http.NewRequest("GET", "/orders/"+uuid.New().String()+"/timeline", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
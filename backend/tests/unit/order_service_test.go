@@ -0,0 +1,247 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stopOrderRepo хранит один заказ и считает отметки точек; completeErr
+// имитирует точку, закрытую параллельным запросом.
+type stopOrderRepo struct {
+	repository.OrderRepository
+	order       *entity.Order
+	completed   int
+	completeErr error
+	deleted     bool
+}
+
+func (r *stopOrderRepo) GetByID(_ context.Context, id uuid.UUID) (*entity.Order, error) {
+	if r.order == nil || r.order.ID != id {
+		return nil, repository.ErrOrderNotFound
+	}
+	cp := *r.order
+	return &cp, nil
+}
+
+func (r *stopOrderRepo) CompleteStop(_ context.Context, orderID, stopID uuid.UUID, at time.Time) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	r.completed++
+	return nil
+}
+
+func (r *stopOrderRepo) Delete(_ context.Context, id uuid.UUID) error {
+	r.deleted = true
+	return nil
+}
+
+func TestCompleteStop_RequiresOrderInProgress(t *testing.T) {
+	stopID := uuid.New()
+	courierID := uuid.New()
+	repo := &stopOrderRepo{order: &entity.Order{
+		ID:        uuid.New(),
+		CourierID: &courierID,
+		Stops:     []entity.OrderStop{{ID: stopID, Kind: entity.StopPickup}},
+	}}
+	svc := service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, service.OrderOptions{}, nil)
+
+	for _, status := range []entity.OrderStatus{entity.StatusCreated, entity.StatusCanceled, entity.StatusDelivered} {
+		repo.order.Status = status
+		_, err := svc.CompleteStop(context.Background(), courierID, repo.order.ID, stopID, nil)
+		assert.ErrorIs(t, err, service.ErrOrderNotInProgress, status)
+	}
+	assert.Zero(t, repo.completed)
+
+	// уже пройденную точку повторно не отмечаем
+	done := time.Now().UTC()
+	repo.order.Status = entity.StatusInTransit
+	repo.order.Stops[0].CompletedAt = &done
+	_, err := svc.CompleteStop(context.Background(), courierID, repo.order.ID, stopID, nil)
+	assert.ErrorIs(t, err, repository.ErrStopCompleted)
+	assert.Zero(t, repo.completed)
+}
+
+func TestCompleteStop_OnlyAssignedCourier(t *testing.T) {
+	stopID := uuid.New()
+	courierID := uuid.New()
+	repo := &stopOrderRepo{order: &entity.Order{
+		ID:        uuid.New(),
+		CourierID: &courierID,
+		Status:    entity.StatusInTransit,
+		Stops:     []entity.OrderStop{{ID: stopID, Kind: entity.StopDropoff}},
+	}}
+	couriers := &shiftCourierRepo{courier: &entity.Courier{UserID: courierID}}
+	proofRepo := newFakeProofRepo("123456", uuid.New())
+	proofs := service.NewProofService(proofRepo, nil, 0, nil)
+	svc := service.NewOrderService(repo, couriers, nil, nil, nil, nil, nil, nil, proofs, nil, service.OrderOptions{}, nil)
+	proof := &service.ProofInput{Kind: entity.ProofOTP, Code: "123456", Location: entity.Coordinates{Latitude: 1, Longitude: 1}}
+
+	_, err := svc.CompleteStop(context.Background(), uuid.New(), repo.order.ID, stopID, proof)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound, "чужой заказ")
+
+	// без координаты курьера место вручения неизвестно
+	_, err = svc.CompleteStop(context.Background(), courierID, repo.order.ID, stopID, proof)
+	assert.ErrorIs(t, err, service.ErrCourierLocationUnknown)
+	assert.Zero(t, repo.completed)
+
+	// точку закрыл параллельный запрос: подтверждение не остаётся
+	at := entity.Coordinates{Latitude: 55.75, Longitude: 37.61}
+	couriers.courier.Location = &at
+	repo.completeErr = repository.ErrStopCompleted
+	_, err = svc.CompleteStop(context.Background(), courierID, repo.order.ID, stopID, proof)
+	assert.ErrorIs(t, err, repository.ErrStopCompleted)
+	assert.Empty(t, proofRepo.proofs)
+	repo.completeErr = nil
+
+	// место вручения берётся у курьера, а не из запроса
+	_, err = svc.CompleteStop(context.Background(), courierID, repo.order.ID, stopID, proof)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.completed)
+	for _, p := range proofRepo.proofs {
+		assert.Equal(t, at, p.Location)
+	}
+	assert.Len(t, proofRepo.proofs, 1)
+}
+
+func TestDeleteOrder_OnlyCreated(t *testing.T) {
+	repo := &stopOrderRepo{order: &entity.Order{ID: uuid.New(), Status: entity.StatusDelivered}}
+	svc := service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, service.OrderOptions{}, nil)
+
+	// доставленный заказ хранит подтверждения вручения
+	assert.ErrorIs(t, svc.DeleteOrder(context.Background(), repo.order.ID), service.ErrOrderNotDeletable)
+	assert.False(t, repo.deleted)
+
+	repo.order.Status = entity.StatusCreated
+	assert.NoError(t, svc.DeleteOrder(context.Background(), repo.order.ID))
+	assert.True(t, repo.deleted)
+}
+
+func TestAssignCourier_RequiresActiveShift(t *testing.T) {
+	zoneID, otherZone := uuid.New(), uuid.New()
+	repo := &stopOrderRepo{order: &entity.Order{ID: uuid.New(), Status: entity.StatusCreated, ZoneID: &zoneID}}
+	courier := &entity.Courier{
+		UserID:        uuid.New(),
+		Status:        entity.CourierStatusAvailable,
+		AccountStatus: entity.AccountSuspended,
+		// без места: дошедшее до проверки загрузки назначение
+		// заканчивается ErrCourierAtCapacity
+		Capacity: 0,
+	}
+	couriers := &shiftCourierRepo{courier: courier}
+	shifts := &fakeShiftRepo{shifts: map[uuid.UUID]*entity.Shift{}}
+	svc := service.NewOrderService(repo, couriers, shifts, nil, nil, nil, nil, nil, nil, nil, service.OrderOptions{DispatchInZone: true}, nil)
+	assign := func() error { return svc.AssignCourier(context.Background(), repo.order.ID, courier.UserID) }
+
+	assert.ErrorIs(t, assign(), service.ErrCourierSuspended)
+
+	courier.AccountStatus = entity.AccountActive
+	assert.ErrorIs(t, assign(), service.ErrNoActiveShift)
+
+	started := time.Now().Add(-time.Hour)
+	shift := &entity.Shift{ID: uuid.New(), CourierID: courier.UserID, ZoneID: &otherZone, StartedAt: &started}
+	shifts.shifts[shift.ID] = shift
+	assert.ErrorIs(t, assign(), service.ErrCourierOutOfZone)
+
+	// домашняя зона курьера тоже подходит
+	courier.ZoneIDs = []uuid.UUID{zoneID}
+	assert.ErrorIs(t, assign(), service.ErrCourierAtCapacity)
+}
+
+// quotePricing отдаёт одну заранее посчитанную котировку.
+type quotePricing struct {
+	service.PricingService
+	quote *entity.Quote
+}
+
+func (p *quotePricing) GetQuote(_ context.Context, id uuid.UUID) (*entity.Quote, error) {
+	if p.quote.ID != id {
+		return nil, repository.ErrQuoteNotFound
+	}
+	cp := *p.quote
+	return &cp, nil
+}
+
+func newQuoteOrderService(q *entity.Quote) (service.OrderService, *windowOrderRepo) {
+	repo := &windowOrderRepo{flagged: make(map[uuid.UUID]bool)}
+	proofs := service.NewProofService(nil, nil, 0, nil)
+	return service.NewOrderService(repo, nil, nil, nil, nil, &quotePricing{quote: q}, nil, nil, proofs, nil, service.OrderOptions{}, nil), repo
+}
+
+func TestCreateOrder_QuoteMustMatchRoute(t *testing.T) {
+	q := &entity.Quote{
+		ID:        uuid.New(),
+		Pickup:    entity.Coordinates{Latitude: 55.75, Longitude: 37.61},
+		Dropoff:   entity.Coordinates{Latitude: 55.76, Longitude: 37.62},
+		WeightKg:  2,
+		Price:     30000,
+		Currency:  "RUB",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	svc, repo := newQuoteOrderService(q)
+	newOrder := func(pickup entity.Coordinates) *entity.Order {
+		return &entity.Order{
+			ClientID:       uuid.New(),
+			Status:         entity.StatusCreated,
+			DeliveryCoords: "55.76,37.62",
+			QuoteID:        &q.ID,
+			Stops: []entity.OrderStop{
+				{Kind: entity.StopPickup, Location: pickup},
+				{Kind: entity.StopDropoff, Location: q.Dropoff},
+			},
+		}
+	}
+
+	o, err := svc.CreateOrder(context.Background(), newOrder(q.Pickup))
+	assert.NoError(t, err)
+	if assert.NotNil(t, o.Price) {
+		assert.Equal(t, q.Price, *o.Price)
+	}
+
+	// забор в другом конце города по котировке короткой поездки
+	_, err = svc.CreateOrder(context.Background(), newOrder(entity.Coordinates{Latitude: 55.60, Longitude: 37.40}))
+	assert.ErrorIs(t, err, service.ErrQuoteMismatch)
+	assert.Len(t, repo.created, 1)
+}
+
+func TestCreateOrder_QuoteWeight(t *testing.T) {
+	q := &entity.Quote{
+		ID:        uuid.New(),
+		Dropoff:   entity.Coordinates{Latitude: 55.76, Longitude: 37.62},
+		WeightKg:  2,
+		Price:     30000,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	svc, repo := newQuoteOrderService(q)
+	newOrder := func(weight float64) *entity.Order {
+		return &entity.Order{
+			ClientID:       uuid.New(),
+			Status:         entity.StatusCreated,
+			DeliveryCoords: "55.76,37.62",
+			QuoteID:        &q.ID,
+			WeightKg:       weight,
+		}
+	}
+
+	// вес не указан — берётся из котировки
+	o, err := svc.CreateOrder(context.Background(), newOrder(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, o.WeightKg)
+
+	o, err = svc.CreateOrder(context.Background(), newOrder(1.5))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, o.WeightKg)
+
+	// тяжёлая посылка по цене лёгкой
+	_, err = svc.CreateOrder(context.Background(), newOrder(20))
+	assert.ErrorIs(t, err, service.ErrQuoteTooLight)
+	assert.Len(t, repo.created, 2)
+}
@@ -0,0 +1,126 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/pricing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRules() *entity.PricingRules {
+	return &entity.PricingRules{
+		Currency:    "RUB",
+		BaseFare:    10000,
+		PerKm:       2000,
+		MinimumFare: 15000,
+		Timezone:    "UTC",
+		WeightTiers: []entity.WeightTier{
+			{UpToKg: 5, Surcharge: 0},
+			{UpToKg: 20, Surcharge: 5000},
+		},
+		TimeMultipliers: []entity.TimeMultiplier{
+			{From: "22:00", To: "06:00", Multiplier: 1.5},
+		},
+		ZoneSurcharges: []entity.ZoneSurcharge{
+			{Name: "center", Center: entity.Coordinates{Latitude: 0, Longitude: 0.1}, RadiusMeters: 500, Surcharge: 3000},
+		},
+	}
+}
+
+var (
+	quotePickup  = entity.Coordinates{Latitude: 0, Longitude: 0}
+	quoteDropoff = entity.Coordinates{Latitude: 0, Longitude: 0.1} // ~11.1 км
+	daytime      = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func TestPricingCalculate(t *testing.T) {
+	res, err := pricing.Calculate(testRules(), pricing.Input{
+		Pickup: quotePickup, Dropoff: quoteDropoff, WeightKg: 10, At: daytime,
+	})
+	assert.NoError(t, err)
+	// 10000 база + ~22239 за км + 5000 вес + 3000 зона
+	assert.InDelta(t, 40239, res.Total, 5)
+	assert.Len(t, res.Breakdown, 4)
+}
+
+func TestPricingCalculate_NightMultiplier(t *testing.T) {
+	day, err := pricing.Calculate(testRules(), pricing.Input{Pickup: quotePickup, Dropoff: quoteDropoff, At: daytime})
+	assert.NoError(t, err)
+	night, err := pricing.Calculate(testRules(), pricing.Input{Pickup: quotePickup, Dropoff: quoteDropoff, At: daytime.Add(11 * time.Hour)})
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(day.Total)*1.5, night.Total, 1)
+}
+
+func TestPricingCalculate_MinimumFare(t *testing.T) {
+	near := entity.Coordinates{Latitude: 0, Longitude: 0.001}
+	res, err := pricing.Calculate(testRules(), pricing.Input{Pickup: quotePickup, Dropoff: near, At: daytime})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15000), res.Total)
+}
+
+func TestPricingCalculate_TooHeavy(t *testing.T) {
+	_, err := pricing.Calculate(testRules(), pricing.Input{Pickup: quotePickup, Dropoff: quoteDropoff, WeightKg: 25, At: daytime})
+	assert.ErrorIs(t, err, pricing.ErrTooHeavy)
+}
+
+func TestPricingValidate(t *testing.T) {
+	assert.NoError(t, pricing.Validate(testRules()))
+
+	bad := testRules()
+	bad.TimeMultipliers[0].From = "25:00"
+	assert.ErrorIs(t, pricing.Validate(bad), pricing.ErrInvalidRules)
+
+	bad = testRules()
+	bad.WeightTiers = []entity.WeightTier{{UpToKg: 10}, {UpToKg: 5}}
+	assert.ErrorIs(t, pricing.Validate(bad), pricing.ErrInvalidRules)
+}
+
+func testSurge() *entity.SurgeRules {
+	return &entity.SurgeRules{Enabled: true, Threshold: 1, Step: 0.25, Max: 2, Smoothing: 0.5}
+}
+
+func TestSurgeTarget(t *testing.T) {
+	rules := testSurge()
+	assert.Equal(t, 1.0, pricing.SurgeTarget(rules, 3, 3), "заказов не больше, чем курьеров")
+	assert.Equal(t, 1.5, pricing.SurgeTarget(rules, 9, 3))
+	assert.Equal(t, 2.0, pricing.SurgeTarget(rules, 40, 2), "не выше потолка")
+	assert.Equal(t, 1.5, pricing.SurgeTarget(rules, 3, 0), "без курьеров — как один курьер")
+
+	rules.Enabled = false
+	assert.Equal(t, 1.0, pricing.SurgeTarget(rules, 40, 0))
+}
+
+func TestSmoothSurge(t *testing.T) {
+	rules := testSurge()
+	m := 1.0
+	m = pricing.SmoothSurge(rules, m, 2)
+	assert.Equal(t, 1.5, m)
+	m = pricing.SmoothSurge(rules, m, 2)
+	assert.Equal(t, 1.75, m)
+	// спад тоже плавный и доходит ровно до 1
+	for i := 0; i < 10; i++ {
+		m = pricing.SmoothSurge(rules, m, 1)
+	}
+	assert.Equal(t, 1.0, m)
+}
+
+func TestPricingCalculate_Surge(t *testing.T) {
+	base, err := pricing.Calculate(testRules(), pricing.Input{Pickup: quotePickup, Dropoff: quoteDropoff, At: daytime})
+	assert.NoError(t, err)
+	surged, err := pricing.Calculate(testRules(), pricing.Input{
+		Pickup: quotePickup, Dropoff: quoteDropoff, At: daytime, Surge: 1.4, SurgeZone: "center",
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(base.Total)*1.4, surged.Total, 1)
+	last := surged.Breakdown[len(surged.Breakdown)-1]
+	assert.Equal(t, entity.PriceLine{Kind: "surge", Label: "center", Factor: 1.4}, last)
+
+	rules := testRules()
+	rules.Surge = testSurge()
+	assert.NoError(t, pricing.Validate(rules))
+	rules.Surge.Smoothing = 0
+	assert.ErrorIs(t, pricing.Validate(rules), pricing.ErrInvalidRules)
+}
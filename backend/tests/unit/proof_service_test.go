@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProofRepo struct {
+	proofs   map[uuid.UUID]*entity.DeliveryProof
+	code     string
+	clientID uuid.UUID
+	attempts int
+}
+
+func newFakeProofRepo(code string, clientID uuid.UUID) *fakeProofRepo {
+	return &fakeProofRepo{
+		proofs:   make(map[uuid.UUID]*entity.DeliveryProof),
+		code:     code,
+		clientID: clientID,
+	}
+}
+
+func (f *fakeProofRepo) Create(p *entity.DeliveryProof) error {
+	f.proofs[p.ID] = p
+	return nil
+}
+
+func (f *fakeProofRepo) Delete(id uuid.UUID) error {
+	delete(f.proofs, id)
+	return nil
+}
+
+func (f *fakeProofRepo) GetByID(id uuid.UUID) (*entity.DeliveryProof, error) {
+	p, ok := f.proofs[id]
+	if !ok {
+		return nil, repository.ErrProofNotFound
+	}
+	return p, nil
+}
+
+func (f *fakeProofRepo) ListByOrder(orderID uuid.UUID) ([]*entity.DeliveryProof, error) {
+	var list []*entity.DeliveryProof
+	for _, p := range f.proofs {
+		if p.OrderID == orderID {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeProofRepo) DeliveryCode(orderID uuid.UUID) (uuid.UUID, string, error) {
+	return f.clientID, f.code, nil
+}
+
+func (f *fakeProofRepo) CheckCode(orderID uuid.UUID, code string, maxAttempts int) (bool, error) {
+	if f.attempts >= maxAttempts {
+		return false, repository.ErrCodeAttemptsExceeded
+	}
+	if code != f.code {
+		f.attempts++
+		return false, nil
+	}
+	return true, nil
+}
+
+// pngHeader достаточно, чтобы http.DetectContentType опознал PNG.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func proofFixture() (*entity.Order, *entity.OrderStop) {
+	courierID := uuid.New()
+	order := &entity.Order{ID: uuid.New(), ClientID: uuid.New(), CourierID: &courierID}
+	stop := &entity.OrderStop{ID: uuid.New(), Kind: entity.StopDropoff}
+	return order, stop
+}
+
+func TestProofService_OTP(t *testing.T) {
+	order, stop := proofFixture()
+	repo := newFakeProofRepo("123456", order.ClientID)
+	svc := service.NewProofService(repo, storage.NewLocal(t.TempDir()), 1<<20, nil)
+
+	_, err := svc.Record(context.Background(), order, stop, service.ProofInput{Kind: entity.ProofOTP, Code: "000000"})
+	assert.ErrorIs(t, err, service.ErrWrongDeliveryCode)
+
+	p, err := svc.Record(context.Background(), order, stop, service.ProofInput{Kind: entity.ProofOTP, Code: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ProofOTP, p.Kind)
+	assert.Equal(t, stop.ID, p.StopID)
+	assert.False(t, p.RecordedAt.IsZero())
+
+	// после лимита неверных попыток код больше не принимается
+	for i := 0; i < 5; i++ {
+		_, _ = svc.Record(context.Background(), order, stop, service.ProofInput{Kind: entity.ProofOTP, Code: "999999"})
+	}
+	_, err = svc.Record(context.Background(), order, stop, service.ProofInput{Kind: entity.ProofOTP, Code: "123456"})
+	assert.ErrorIs(t, err, repository.ErrCodeAttemptsExceeded)
+}
+
+func TestProofService_Photo(t *testing.T) {
+	order, stop := proofFixture()
+	repo := newFakeProofRepo("123456", order.ClientID)
+	svc := service.NewProofService(repo, storage.NewLocal(t.TempDir()), 1<<10, nil)
+
+	p, err := svc.Record(context.Background(), order, stop, service.ProofInput{
+		Kind: entity.ProofPhoto,
+		File: bytes.NewReader(pngHeader),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", p.ContentType)
+
+	got, f, err := svc.OpenFile(context.Background(), order.ID, p.ID)
+	assert.NoError(t, err)
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	assert.Equal(t, pngHeader, data)
+	assert.Equal(t, p.ID, got.ID)
+
+	_, _, err = svc.OpenFile(context.Background(), uuid.New(), p.ID)
+	assert.ErrorIs(t, err, repository.ErrProofNotFound)
+
+	_, err = svc.Record(context.Background(), order, stop, service.ProofInput{
+		Kind: entity.ProofSignature,
+		File: bytes.NewReader([]byte("not an image")),
+	})
+	assert.ErrorIs(t, err, service.ErrUnsupportedProofType)
+
+	_, err = svc.Record(context.Background(), order, stop, service.ProofInput{
+		Kind: entity.ProofPhoto,
+		File: bytes.NewReader(append(pngHeader, make([]byte, 2<<10)...)),
+	})
+	assert.ErrorIs(t, err, service.ErrProofTooLarge)
+
+	_, err = svc.Record(context.Background(), order, stop, service.ProofInput{Kind: entity.ProofPhoto})
+	assert.ErrorIs(t, err, service.ErrInvalidProof)
+}
+
+func TestProofService_DeliveryCodeOwner(t *testing.T) {
+	clientID := uuid.New()
+	svc := service.NewProofService(newFakeProofRepo("123456", clientID), storage.NewLocal(t.TempDir()), 1<<10, nil)
+
+	code, err := svc.DeliveryCode(context.Background(), clientID, uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", code)
+
+	_, err = svc.DeliveryCode(context.Background(), uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/controller"
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReportRepo запоминает запрос и отдаёт заданные строки.
+type fakeReportRepo struct {
+	repository.ReportRepository
+	query   entity.ReportQuery
+	cancels []*entity.CancellationRow
+}
+
+func (f *fakeReportRepo) Volume(q entity.ReportQuery) ([]*entity.VolumeRow, error) {
+	f.query = q
+	return nil, nil
+}
+
+func (f *fakeReportRepo) Cancellations(q entity.ReportQuery) ([]*entity.CancellationRow, error) {
+	f.query = q
+	return f.cancels, nil
+}
+
+func TestReportService_Period(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := &fakeReportRepo{}
+	svc := service.NewReportService(repo, moscow, nil)
+
+	rows, q, err := svc.Volume(context.Background(), entity.ReportQuery{})
+	assert.NoError(t, err)
+	assert.NotNil(t, rows, "пустой отчёт — пустой список, а не null")
+	assert.Equal(t, entity.ReportByDay, q.GroupBy)
+	assert.Equal(t, "MSK", repo.query.Location)
+	// неделя целыми днями по часовому поясу отчётов
+	assert.Equal(t, 7*24*time.Hour, q.To.Sub(q.From))
+	assert.Equal(t, 0, q.To.In(moscow).Hour())
+	assert.True(t, q.To.After(time.Now()))
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err = svc.Volume(context.Background(), entity.ReportQuery{From: from, To: from})
+	assert.ErrorIs(t, err, service.ErrInvalidReportPeriod)
+	_, _, err = svc.Volume(context.Background(), entity.ReportQuery{From: from, To: from.AddDate(2, 0, 0)})
+	assert.ErrorIs(t, err, service.ErrInvalidReportPeriod)
+	_, _, err = svc.Volume(context.Background(), entity.ReportQuery{GroupBy: "client", From: from, To: from.AddDate(0, 1, 0)})
+	assert.ErrorIs(t, err, service.ErrInvalidReportGroup)
+}
+
+func TestReportController_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeReportRepo{cancels: []*entity.CancellationRow{
+		{Key: "zone-1", Label: "Центр, север", Orders: 8, Canceled: 2, CanceledUnassigned: 1, CancellationRate: 0.25},
+	}}
+	rc := controller.NewReportController(service.NewReportService(repo, time.UTC, nil))
+	router := gin.New()
+	router.GET("/admin/reports/cancellations", rc.Cancellations)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet,
+		"/admin/reports/cancellations?group_by=zone&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z", nil)
+	req.Header.Set("Accept", "text/csv")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "cancellations-by-zone-20250101-20250201.csv")
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"zone", "name", "orders", "canceled", "canceled_unassigned", "cancellation_rate"},
+		{"zone-1", "Центр, север", "8", "2", "1", "0.2500"},
+	}, records)
+	assert.Equal(t, entity.ReportByZone, repo.query.GroupBy)
+
+	// без Accept — JSON
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/admin/reports/cancellations?group_by=zone", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"cancellation_rate":0.25`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/admin/reports/cancellations?group_by=client", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
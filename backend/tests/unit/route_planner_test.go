@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"testing"
+
+	"backend/internal/entity"
+	"backend/internal/route"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func stopAt(orderID uuid.UUID, kind entity.StopKind, lon float64) entity.OrderStop {
+	return entity.OrderStop{
+		ID:       uuid.New(),
+		OrderID:  orderID,
+		Kind:     kind,
+		Location: entity.Coordinates{Latitude: 0, Longitude: lon},
+	}
+}
+
+func TestPlanner_Empty(t *testing.T) {
+	plan, err := route.NewPlanner(nil).Plan(entity.Coordinates{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Stops)
+	assert.Zero(t, plan.Total)
+}
+
+func TestPlanner_VisitsPointsAlongLine(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	stops := []entity.OrderStop{
+		stopAt(a, entity.StopDropoff, 0.03),
+		stopAt(b, entity.StopDropoff, 0.01),
+		stopAt(c, entity.StopDropoff, 0.02),
+	}
+
+	plan, err := route.NewPlanner(route.HaversineMatrix{}).Plan(entity.Coordinates{}, stops)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b, c, a}, []uuid.UUID{
+		plan.Stops[0].OrderID, plan.Stops[1].OrderID, plan.Stops[2].OrderID,
+	})
+	assert.InDelta(t, plan.Legs[0]+plan.Legs[1]+plan.Legs[2], plan.Total, 1e-6)
+}
+
+func TestPlanner_PickupBeforeDropoff(t *testing.T) {
+	// выдача совсем рядом со стартом, забор — далеко: ближайший сосед
+	// сам по себе поехал бы сначала на выдачу
+	order := uuid.New()
+	other := uuid.New()
+	stops := []entity.OrderStop{
+		stopAt(order, entity.StopDropoff, 0.001),
+		stopAt(order, entity.StopPickup, 0.05),
+		stopAt(other, entity.StopDropoff, 0.02),
+	}
+
+	plan, err := route.NewPlanner(nil).Plan(entity.Coordinates{}, stops)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Stops, 3)
+
+	pickupAt, dropoffAt := -1, -1
+	for i, s := range plan.Stops {
+		if s.OrderID != order {
+			continue
+		}
+		if s.Kind == entity.StopPickup {
+			pickupAt = i
+		} else {
+			dropoffAt = i
+		}
+	}
+	assert.Less(t, pickupAt, dropoffAt)
+}
+
+type badMatrix struct{}
+
+func (badMatrix) Matrix(points []entity.Coordinates) ([][]float64, error) {
+	return [][]float64{{0}}, nil
+}
+
+func TestPlanner_PluggableMatrix(t *testing.T) {
+	stops := []entity.OrderStop{
+		stopAt(uuid.New(), entity.StopDropoff, 0.01),
+		stopAt(uuid.New(), entity.StopDropoff, 0.02),
+	}
+	_, err := route.NewPlanner(badMatrix{}).Plan(entity.Coordinates{}, stops)
+	assert.ErrorIs(t, err, route.ErrBadMatrix)
+}
@@ -0,0 +1,181 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeShiftRepo struct {
+	shifts map[uuid.UUID]*entity.Shift
+}
+
+func (f *fakeShiftRepo) Create(_ context.Context, s *entity.Shift) error {
+	s.ID = uuid.New()
+	f.shifts[s.ID] = s
+	return nil
+}
+
+func (f *fakeShiftRepo) GetByID(_ context.Context, id uuid.UUID) (*entity.Shift, error) {
+	s, ok := f.shifts[id]
+	if !ok {
+		return nil, repository.ErrShiftNotFound
+	}
+	return s, nil
+}
+
+func (f *fakeShiftRepo) List(_ context.Context, filter repository.ShiftFilter) ([]*entity.Shift, error) {
+	var ret []*entity.Shift
+	for _, s := range f.shifts {
+		if filter.CourierID != nil && s.CourierID != *filter.CourierID {
+			continue
+		}
+		if filter.From != nil && s.PlannedStart.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !s.PlannedStart.Before(*filter.To) {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+func (f *fakeShiftRepo) Update(_ context.Context, s *entity.Shift) error {
+	f.shifts[s.ID] = s
+	return nil
+}
+
+func (f *fakeShiftRepo) Delete(_ context.Context, id uuid.UUID) error {
+	delete(f.shifts, id)
+	return nil
+}
+
+func (f *fakeShiftRepo) GetActive(_ context.Context, courierID uuid.UUID) (*entity.Shift, error) {
+	for _, s := range f.shifts {
+		if s.CourierID == courierID && s.IsActive() {
+			return s, nil
+		}
+	}
+	return nil, repository.ErrShiftNotFound
+}
+
+func (f *fakeShiftRepo) Summary(_ context.Context, s *entity.Shift, now time.Time) (*entity.ShiftSummary, error) {
+	return &entity.ShiftSummary{ShiftID: s.ID, CourierID: s.CourierID, StartedAt: s.StartedAt, EndedAt: s.EndedAt}, nil
+}
+
+// shiftCourierRepo хранит одного курьера в памяти.
+type shiftCourierRepo struct {
+	repository.CourierRepository
+	courier *entity.Courier
+}
+
+func (r *shiftCourierRepo) GetByID(_ context.Context, id uuid.UUID) (*entity.Courier, error) {
+	if r.courier.UserID != id {
+		return nil, repository.ErrCourierNotFound
+	}
+	c := *r.courier
+	return &c, nil
+}
+
+func (r *shiftCourierRepo) Update(_ context.Context, c *entity.Courier) error {
+	if c.Version != r.courier.Version {
+		return repository.ErrVersionConflict
+	}
+	c.Version++
+	r.courier = c
+	return nil
+}
+
+func (r *shiftCourierRepo) Touch(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.courier.LastSeenAt = at
+	return nil
+}
+
+func newShiftFixture() (service.ShiftService, *fakeShiftRepo, *shiftCourierRepo) {
+	shifts := &fakeShiftRepo{shifts: make(map[uuid.UUID]*entity.Shift)}
+	couriers := &shiftCourierRepo{courier: &entity.Courier{
+		UserID:        uuid.New(),
+		Status:        entity.CourierStatusOffline,
+		AccountStatus: entity.AccountActive,
+		Capacity:      1,
+		Location:      &entity.Coordinates{},
+	}}
+	svc := service.NewShiftService(shifts, couriers, newFakeOrderService(), zap.NewNop())
+	return svc, shifts, couriers
+}
+
+func TestPlanShift_RejectsOverlapAndBadInterval(t *testing.T) {
+	svc, _, couriers := newShiftFixture()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	courierID := couriers.courier.UserID
+
+	err := svc.PlanShift(context.Background(), &entity.Shift{CourierID: courierID, PlannedStart: start, PlannedEnd: start.Add(8 * time.Hour)})
+	assert.NoError(t, err)
+
+	err = svc.PlanShift(context.Background(), &entity.Shift{CourierID: courierID, PlannedStart: start.Add(7 * time.Hour), PlannedEnd: start.Add(10 * time.Hour)})
+	assert.ErrorIs(t, err, service.ErrShiftOverlap)
+
+	// смена сразу после предыдущей не пересекается с ней
+	err = svc.PlanShift(context.Background(), &entity.Shift{CourierID: courierID, PlannedStart: start.Add(8 * time.Hour), PlannedEnd: start.Add(10 * time.Hour)})
+	assert.NoError(t, err)
+
+	err = svc.PlanShift(context.Background(), &entity.Shift{CourierID: courierID, PlannedStart: start, PlannedEnd: start})
+	assert.ErrorIs(t, err, service.ErrInvalidShift)
+}
+
+func TestStartEndShift_DrivesCourierStatus(t *testing.T) {
+	svc, shifts, couriers := newShiftFixture()
+	courierID := couriers.courier.UserID
+	ctx := context.Background()
+
+	_, err := svc.StartShift(ctx, courierID)
+	assert.ErrorIs(t, err, service.ErrNoPlannedShift)
+
+	// выйти на смену можно чуть раньше планового начала
+	now := time.Now().UTC()
+	assert.NoError(t, shifts.Create(context.Background(), &entity.Shift{
+		CourierID:    courierID,
+		PlannedStart: now.Add(10 * time.Minute),
+		PlannedEnd:   now.Add(4 * time.Hour),
+	}))
+
+	shift, err := svc.StartShift(ctx, courierID)
+	assert.NoError(t, err)
+	assert.NotNil(t, shift.StartedAt)
+	assert.Equal(t, entity.CourierStatusAvailable, couriers.courier.Status)
+
+	_, err = svc.StartShift(ctx, courierID)
+	assert.ErrorIs(t, err, service.ErrShiftAlreadyActive)
+
+	summary, err := svc.EndShift(ctx, courierID)
+	assert.NoError(t, err)
+	assert.Equal(t, shift.ID, summary.ShiftID)
+	assert.NotNil(t, summary.EndedAt)
+	assert.Equal(t, entity.CourierStatusOffline, couriers.courier.Status)
+
+	_, err = svc.EndShift(ctx, courierID)
+	assert.ErrorIs(t, err, service.ErrNoActiveShift)
+}
+
+func TestStartShift_SuspendedCourier(t *testing.T) {
+	svc, shifts, couriers := newShiftFixture()
+	couriers.courier.AccountStatus = entity.AccountSuspended
+	now := time.Now().UTC()
+	assert.NoError(t, shifts.Create(context.Background(), &entity.Shift{
+		CourierID:    couriers.courier.UserID,
+		PlannedStart: now,
+		PlannedEnd:   now.Add(time.Hour),
+	}))
+
+	_, err := svc.StartShift(context.Background(), couriers.courier.UserID)
+	assert.ErrorIs(t, err, service.ErrCourierSuspended)
+}
@@ -0,0 +1,145 @@
+package config_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeSLARepo отдаёт заданные нарушения и риски по метрике.
+type fakeSLARepo struct {
+	repository.SLARepository
+	policies []*entity.SLAPolicy
+	breaches map[entity.SLAMetric][]*entity.SLABreach
+	risks    map[entity.SLAMetric][]*entity.SLARisk
+	warned   map[uuid.UUID]bool
+	saved    *entity.SLAPolicy
+}
+
+func (f *fakeSLARepo) ListPolicies() ([]*entity.SLAPolicy, error) {
+	return f.policies, nil
+}
+
+func (f *fakeSLARepo) SavePolicy(p *entity.SLAPolicy) error {
+	f.saved = p
+	return nil
+}
+
+func (f *fakeSLARepo) DetectBreaches(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLABreach, error) {
+	// повторная проверка не находит уже сохранённые нарушения
+	ret := f.breaches[p.Metric]
+	delete(f.breaches, p.Metric)
+	return ret, nil
+}
+
+func (f *fakeSLARepo) ListAtRisk(p *entity.SLAPolicy, now, since time.Time) ([]*entity.SLARisk, error) {
+	return f.risks[p.Metric], nil
+}
+
+func (f *fakeSLARepo) MarkWarned(orderID uuid.UUID, metric entity.SLAMetric, at time.Time) (bool, error) {
+	if f.warned[orderID] {
+		return false, nil
+	}
+	f.warned[orderID] = true
+	return true, nil
+}
+
+type fakeNotifier struct {
+	service.NotificationService
+	messages []string
+}
+
+func (f *fakeNotifier) NotifyAdmins(_ context.Context, p entity.Permission, message string) error {
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func TestSLAMonitor_NotifiesOnce(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	late, soon := uuid.New(), uuid.New()
+	repo := &fakeSLARepo{
+		policies: []*entity.SLAPolicy{
+			{Metric: entity.SLATimeToAssign, ThresholdSeconds: 600, WarnBeforeSeconds: 180, Enabled: true},
+			{Metric: entity.SLATimeToDeliver, ThresholdSeconds: 7200, Enabled: false},
+		},
+		breaches: map[entity.SLAMetric][]*entity.SLABreach{
+			entity.SLATimeToAssign:  {{OrderID: late, Metric: entity.SLATimeToAssign, Deadline: now.Add(-time.Minute)}},
+			entity.SLATimeToDeliver: {{OrderID: late, Metric: entity.SLATimeToDeliver}},
+		},
+		risks: map[entity.SLAMetric][]*entity.SLARisk{
+			entity.SLATimeToAssign: {{OrderID: soon, Metric: entity.SLATimeToAssign, Deadline: now.Add(time.Minute)}},
+		},
+		warned: make(map[uuid.UUID]bool),
+	}
+	notifier := &fakeNotifier{}
+	monitor := service.NewSLAMonitor(repo, notifier, time.Minute, zap.NewNop())
+
+	assert.NoError(t, monitor.RunOnce(context.Background(), now))
+	if assert.Len(t, notifier.messages, 2, "выключенная политика не проверяется") {
+		assert.True(t, strings.HasPrefix(notifier.messages[0], "SLA breach: order "+late.String()))
+		assert.True(t, strings.HasPrefix(notifier.messages[1], "SLA at risk: order "+soon.String()))
+	}
+
+	assert.NoError(t, monitor.RunOnce(context.Background(), now.Add(time.Minute)))
+	assert.Len(t, notifier.messages, 2, "о том же заказе не предупреждаем дважды")
+}
+
+func TestSLAService_UpdatePolicy(t *testing.T) {
+	repo := &fakeSLARepo{}
+	svc := service.NewSLAService(repo, nil)
+	actor := uuid.New()
+
+	_, err := svc.UpdatePolicy(context.Background(), &entity.SLAPolicy{Metric: "TIME_TO_LUNCH", ThresholdSeconds: 60}, actor)
+	assert.ErrorIs(t, err, repository.ErrSLAPolicyNotFound)
+
+	_, err = svc.UpdatePolicy(context.Background(), &entity.SLAPolicy{Metric: entity.SLATimeToPickup, ThresholdSeconds: 600, WarnBeforeSeconds: 600}, actor)
+	assert.ErrorIs(t, err, service.ErrInvalidSLAPolicy)
+
+	p, err := svc.UpdatePolicy(context.Background(), &entity.SLAPolicy{Metric: entity.SLATimeToPickup, ThresholdSeconds: 1800, WarnBeforeSeconds: 300, Enabled: true}, actor)
+	assert.NoError(t, err)
+	assert.Equal(t, &actor, p.UpdatedBy)
+	assert.Equal(t, p, repo.saved)
+}
+
+func TestSLAMetric_Milestones(t *testing.T) {
+	assert.Contains(t, entity.SLATimeToAssign.Milestones(), entity.StatusInTransit,
+		"заказ, сразу забранный курьером, назначен вовремя")
+	assert.Equal(t, []entity.OrderStatus{entity.StatusDelivered}, entity.SLATimeToDeliver.Milestones())
+}
+
+type fakeNotificationRepo struct {
+	repository.NotificationRepository
+	created []*entity.Notification
+}
+
+func (f *fakeNotificationRepo) Create(n *entity.Notification) error {
+	f.created = append(f.created, n)
+	return nil
+}
+
+func TestNotifyAdmins_ByPermission(t *testing.T) {
+	reader, owner, other := uuid.New(), uuid.New(), uuid.New()
+	admins := &fakeAdminRepo{admins: map[uuid.UUID]*entity.Admin{
+		reader: {UserID: reader, Permissions: entity.Permissions{entity.PermOrdersRead}},
+		owner:  {UserID: owner, Permissions: entity.Permissions{entity.PermAll}},
+		other:  {UserID: other, Permissions: entity.Permissions{entity.PermZonesManage}},
+	}}
+	repo := &fakeNotificationRepo{}
+	svc := service.NewNotificationService(repo, admins, nil)
+
+	assert.NoError(t, svc.NotifyAdmins(context.Background(), entity.PermOrdersRead, "SLA breach"))
+	var got []uuid.UUID
+	for _, n := range repo.created {
+		got = append(got, n.UserID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{reader, owner}, got)
+}
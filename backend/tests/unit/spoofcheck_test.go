@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/spoofcheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var spoofLimits = spoofcheck.Limits{MaxSpeed: 150 / 3.6, TeleportMeters: 5000, MaxClockSkew: time.Minute}
+
+func TestSpoofCheck_PlausibleMove(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := &entity.LocationFix{Location: entity.Coordinates{Latitude: 55.75, Longitude: 37.61}, RecordedAt: start}
+	// ~111 м за 10 секунд
+	fix := entity.LocationFix{Location: entity.Coordinates{Latitude: 55.751, Longitude: 37.61}, RecordedAt: start.Add(10 * time.Second)}
+
+	res := spoofcheck.Check(prev, fix, start, spoofLimits)
+	assert.False(t, res.Suspicious())
+	assert.InDelta(t, 11.1, *res.SpeedMps, 0.2)
+}
+
+func TestSpoofCheck_Anomalies(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := &entity.LocationFix{Location: entity.Coordinates{Latitude: 55.75, Longitude: 37.61}, RecordedAt: start}
+
+	tests := []struct {
+		name string
+		prev *entity.LocationFix
+		fix  entity.LocationFix
+		want []entity.AnomalyKind
+	}{
+		{
+			name: "null island without history",
+			fix:  entity.LocationFix{RecordedAt: start},
+			want: []entity.AnomalyKind{entity.AnomalyNullIsland},
+		},
+		{
+			name: "out of order",
+			prev: prev,
+			fix:  entity.LocationFix{Location: prev.Location, RecordedAt: start.Add(-time.Minute)},
+			want: []entity.AnomalyKind{entity.AnomalyOutOfOrder},
+		},
+		{
+			name: "impossible speed",
+			prev: prev,
+			// ~1.1 км за 10 секунд
+			fix:  entity.LocationFix{Location: entity.Coordinates{Latitude: 55.76, Longitude: 37.61}, RecordedAt: start.Add(10 * time.Second)},
+			want: []entity.AnomalyKind{entity.AnomalyImpossibleSpeed},
+		},
+		{
+			name: "teleport",
+			prev: prev,
+			// ~11 км за минуту
+			fix:  entity.LocationFix{Location: entity.Coordinates{Latitude: 55.85, Longitude: 37.61}, RecordedAt: start.Add(time.Minute)},
+			want: []entity.AnomalyKind{entity.AnomalyTeleport},
+		},
+		{
+			name: "future timestamp",
+			prev: prev,
+			// сутки вперёд сделали бы скачок на 11 км «медленным»
+			fix:  entity.LocationFix{Location: entity.Coordinates{Latitude: 55.85, Longitude: 37.61}, RecordedAt: start.Add(25 * time.Hour)},
+			want: []entity.AnomalyKind{entity.AnomalyFutureTimestamp},
+		},
+		{
+			name: "same timestamp",
+			prev: prev,
+			fix:  entity.LocationFix{Location: entity.Coordinates{Latitude: 55.76, Longitude: 37.61}, RecordedAt: start},
+			want: []entity.AnomalyKind{entity.AnomalyImpossibleSpeed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := spoofcheck.Check(tt.prev, tt.fix, start.Add(time.Hour), spoofLimits)
+			assert.Equal(t, tt.want, res.Kinds)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeSurgeRepo struct {
+	repository.SurgeRepository
+	zones []*entity.ZoneSurge
+	saved map[uuid.UUID]float64
+}
+
+func (f *fakeSurgeRepo) Load(_ context.Context, now time.Time) ([]*entity.ZoneSurge, error) {
+	return f.zones, nil
+}
+
+func (f *fakeSurgeRepo) Save(_ context.Context, s *entity.ZoneSurge) error {
+	f.saved[s.ZoneID] = s.Multiplier
+	return nil
+}
+
+type fakePricingRepo struct {
+	repository.PricingRepository
+	rules *entity.PricingRules
+}
+
+func (f *fakePricingRepo) GetActiveRules(_ context.Context) (*entity.PricingRules, error) {
+	return f.rules, nil
+}
+
+func TestSurgeUpdater_Smooths(t *testing.T) {
+	busy, calm := uuid.New(), uuid.New()
+	repo := &fakeSurgeRepo{
+		zones: []*entity.ZoneSurge{
+			{ZoneID: busy, OpenOrders: 12, AvailableCouriers: 2, Multiplier: 1},
+			{ZoneID: calm, OpenOrders: 1, AvailableCouriers: 4, Multiplier: 1.6},
+		},
+		saved: make(map[uuid.UUID]float64),
+	}
+	rules := &fakePricingRepo{rules: &entity.PricingRules{Surge: &entity.SurgeRules{
+		Enabled: true, Threshold: 2, Step: 0.25, Max: 1.8, Smoothing: 0.5,
+	}}}
+	updater := service.NewSurgeUpdater(repo, rules, time.Minute, zap.NewNop())
+
+	assert.NoError(t, updater.RunOnce(context.Background(), time.Now()))
+	// цель 1.8 (потолок) — за один шаг половина пути
+	assert.Equal(t, 1.4, repo.saved[busy])
+	assert.Equal(t, 1.3, repo.saved[calm])
+}
+
+func TestZoneSurge_Effective(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	override := 2.5
+	expires := now.Add(time.Hour)
+	s := &entity.ZoneSurge{Multiplier: 1.2, Override: &override, OverrideExpiresAt: &expires}
+
+	assert.Equal(t, 2.5, s.Effective(now))
+	assert.Equal(t, 1.2, s.Effective(expires), "истёкшее переопределение не действует")
+	assert.Equal(t, 1.0, (&entity.ZoneSurge{}).Effective(now))
+}
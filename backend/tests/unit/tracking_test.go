@@ -0,0 +1,212 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/controller"
+	"backend/internal/entity"
+	"backend/internal/geo"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"backend/internal/tracking"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackingToken(t *testing.T) {
+	secret := []byte("secret")
+	id := uuid.New()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	token := tracking.Sign(secret, id, now.Add(time.Hour))
+
+	got, err := tracking.Parse(secret, token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+	assert.NotContains(t, token, id.String())
+
+	_, err = tracking.Parse(secret, token, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, tracking.ErrExpiredToken)
+
+	_, err = tracking.Parse([]byte("other"), token, now)
+	assert.ErrorIs(t, err, tracking.ErrInvalidToken)
+
+	// подмена срока ломает подпись
+	forged := tracking.Sign([]byte("other"), id, now.Add(24*time.Hour))
+	_, err = tracking.Parse(secret, forged, now)
+	assert.ErrorIs(t, err, tracking.ErrInvalidToken)
+
+	_, err = tracking.Parse(secret, "not-a-token", now)
+	assert.ErrorIs(t, err, tracking.ErrInvalidToken)
+}
+
+func TestSnap(t *testing.T) {
+	a := entity.Coordinates{Latitude: 55.75580, Longitude: 37.61730}
+	b := entity.Coordinates{Latitude: 55.75585, Longitude: 37.61735}
+
+	sa := geo.Snap(a, 250)
+	assert.Equal(t, sa, geo.Snap(b, 250), "близкие точки попадают в одну ячейку")
+	assert.NotEqual(t, a, sa)
+	// центр ячейки не дальше её полудиагонали
+	assert.LessOrEqual(t, geo.Haversine(a, sa), 250*0.71)
+	assert.Equal(t, a, geo.Snap(a, 0))
+}
+
+// trackingOrderRepo хранит один заказ в памяти.
+type trackingOrderRepo struct {
+	repository.OrderRepository
+	order *entity.Order
+}
+
+func (r *trackingOrderRepo) GetByID(_ context.Context, id uuid.UUID) (*entity.Order, error) {
+	if r.order.ID != id {
+		return nil, repository.ErrOrderNotFound
+	}
+	return r.order, nil
+}
+
+type trackingCourierRepo struct {
+	repository.CourierRepository
+	fix *entity.LocationFix
+}
+
+func (r *trackingCourierRepo) LastLocation(_ context.Context, id uuid.UUID) (*entity.LocationFix, error) {
+	return r.fix, nil
+}
+
+type fakeTrackingRepo struct {
+	links map[uuid.UUID]*entity.TrackingLink
+}
+
+func (f *fakeTrackingRepo) Create(link *entity.TrackingLink) error {
+	f.links[link.ID] = link
+	return nil
+}
+
+func (f *fakeTrackingRepo) GetByID(id uuid.UUID) (*entity.TrackingLink, error) {
+	link, ok := f.links[id]
+	if !ok {
+		return nil, repository.ErrTrackingLinkNotFound
+	}
+	return link, nil
+}
+
+func (f *fakeTrackingRepo) RevokeByOrder(orderID uuid.UUID, at time.Time) (int64, error) {
+	var n int64
+	for _, link := range f.links {
+		if link.OrderID == orderID && link.RevokedAt == nil {
+			link.RevokedAt = &at
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newTrackingFixture() (service.TrackingService, *entity.Order) {
+	courierID := uuid.New()
+	eta := time.Now().Add(20 * time.Minute)
+	order := &entity.Order{
+		ID:                  uuid.New(),
+		ClientID:            uuid.New(),
+		CourierID:           &courierID,
+		Status:              entity.StatusInTransit,
+		DeliveryAddress:     "ул. Тверская, 1",
+		EstimatedDeliveryAt: &eta,
+		Stops: []entity.OrderStop{
+			{ID: uuid.New(), Kind: entity.StopDropoff, Address: "ул. Тверская, 1"},
+		},
+	}
+	fix := &entity.LocationFix{
+		CourierID:  courierID,
+		Location:   entity.Coordinates{Latitude: 55.75580, Longitude: 37.61730},
+		RecordedAt: time.Now(),
+	}
+	svc := service.NewTrackingService(
+		&fakeTrackingRepo{links: make(map[uuid.UUID]*entity.TrackingLink)},
+		&trackingOrderRepo{order: order},
+		&trackingCourierRepo{fix: fix},
+		service.TrackingOptions{Secret: []byte("secret"), TTL: time.Hour, LocationCell: 250},
+		nil,
+	)
+	return svc, order
+}
+
+func TestTrackingService(t *testing.T) {
+	svc, order := newTrackingFixture()
+
+	_, _, err := svc.CreateLink(context.Background(), uuid.New(), order.ID)
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound, "чужой заказ")
+
+	token, link, err := svc.CreateLink(context.Background(), order.ClientID, order.ID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), link.ExpiresAt, time.Minute)
+
+	view, err := svc.Track(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusInTransit, view.Status)
+	assert.Equal(t, order.EstimatedDeliveryAt, view.EstimatedDeliveryAt)
+	if assert.NotNil(t, view.CourierLocation) {
+		assert.NotEqual(t, 55.75580, view.CourierLocation.Latitude, "координаты огрублены")
+	}
+
+	// в публичном ответе нет адресов и внутренних идентификаторов
+	body, _ := json.Marshal(view)
+	for _, secret := range []string{order.ID.String(), order.ClientID.String(), order.CourierID.String(), "Тверская"} {
+		assert.NotContains(t, string(body), secret)
+	}
+
+	n, err := svc.RevokeLinks(context.Background(), order.ClientID, order.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	_, err = svc.Track(context.Background(), token)
+	assert.ErrorIs(t, err, service.ErrTrackingLinkExpired)
+
+	_, err = svc.Track(context.Background(), "garbage")
+	assert.ErrorIs(t, err, repository.ErrTrackingLinkNotFound)
+}
+
+func TestTrackingService_DeliveredHidesCourier(t *testing.T) {
+	svc, order := newTrackingFixture()
+	order.Status = entity.StatusDelivered
+
+	token, _, err := svc.CreateLink(context.Background(), order.ClientID, order.ID)
+	assert.NoError(t, err)
+	view, err := svc.Track(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Nil(t, view.CourierLocation)
+	assert.Nil(t, view.EstimatedDeliveryAt)
+}
+
+func TestTrackingController_Track(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, order := newTrackingFixture()
+	token, _, _ := svc.CreateLink(context.Background(), order.ClientID, order.ID)
+
+	r := gin.New()
+	r.GET("/track/:token", controller.NewTrackingController(svc, "").Track)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/track/"+token, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	req := httptest.NewRequest(http.MethodGet, "/track/"+token, nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "<!DOCTYPE html>"))
+	assert.Contains(t, w.Body.String(), "Курьер в пути")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/track/garbage", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
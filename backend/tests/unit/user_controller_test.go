@@ -3,6 +3,7 @@ package config_test
 import (
 	"bytes"
 	"encoding/json"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -16,7 +17,7 @@ import (
 
 type fakeUserService struct{}
 
-func (f *fakeUserService) Register(email, password string) (*entity.User, error) {
+func (f *fakeUserService) Register(_ context.Context, email, password string) (*entity.User, error) {
 	if email == "exists@example.com" {
 		return nil, errors.New("user already exists")
 	}
@@ -27,7 +28,7 @@ func (f *fakeUserService) Register(email, password string) (*entity.User, error)
 	}, nil
 }
 
-func (f *fakeUserService) Login(email, password string) (*entity.User, error) {
+func (f *fakeUserService) Login(_ context.Context, email, password string) (*entity.User, error) {
 	if email == "test@example.com" && password == "password123" {
 		return &entity.User{
 			ID:    uuid.MustParse("00000000-0000-0000-0000-000000000123"),
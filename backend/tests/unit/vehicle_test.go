@@ -0,0 +1,32 @@
+package config_test
+
+import (
+	"testing"
+
+	"backend/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleType_Spec(t *testing.T) {
+	spec, ok := entity.VehicleBike.Spec()
+	assert.True(t, ok)
+	assert.Equal(t, 15.0, spec.SpeedKmh)
+
+	_, ok = entity.VehicleType("TRAIN").Spec()
+	assert.False(t, ok)
+	assert.False(t, entity.VehicleType("").Valid())
+}
+
+func TestCourier_CanCarry(t *testing.T) {
+	bike := &entity.Courier{VehicleType: entity.VehicleBike, MaxWeightKg: 15, MaxVolumeL: 50}
+	parcel := &entity.Order{WeightKg: 30, LengthCm: 40, WidthCm: 30, HeightCm: 30}
+
+	assert.InDelta(t, 36, parcel.VolumeLiters(), 1e-9)
+	assert.False(t, bike.CanCarry(parcel.WeightKg, parcel.VolumeLiters()))
+	assert.True(t, bike.CanCarry(5, 20))
+
+	// уже взятые заказы уменьшают свободное место
+	bike.ActiveWeightKg, bike.ActiveVolumeL = 12, 10
+	assert.False(t, bike.CanCarry(5, 20))
+}
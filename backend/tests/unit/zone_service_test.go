@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"backend/internal/entity"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeZoneRepo struct {
+	zones        map[uuid.UUID]*entity.Zone
+	courierZones map[uuid.UUID][]uuid.UUID
+}
+
+func newFakeZoneRepo() *fakeZoneRepo {
+	return &fakeZoneRepo{
+		zones:        make(map[uuid.UUID]*entity.Zone),
+		courierZones: make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (f *fakeZoneRepo) Create(z *entity.Zone) error {
+	z.ID = uuid.New()
+	f.zones[z.ID] = z
+	return nil
+}
+
+func (f *fakeZoneRepo) GetByID(id uuid.UUID) (*entity.Zone, error) {
+	z, ok := f.zones[id]
+	if !ok {
+		return nil, repository.ErrZoneNotFound
+	}
+	return z, nil
+}
+
+func (f *fakeZoneRepo) GetAll() ([]*entity.Zone, error) {
+	var list []*entity.Zone
+	for _, z := range f.zones {
+		list = append(list, z)
+	}
+	return list, nil
+}
+
+func (f *fakeZoneRepo) Update(z *entity.Zone) error {
+	if _, ok := f.zones[z.ID]; !ok {
+		return repository.ErrZoneNotFound
+	}
+	f.zones[z.ID] = z
+	return nil
+}
+
+func (f *fakeZoneRepo) Delete(id uuid.UUID) error {
+	delete(f.zones, id)
+	return nil
+}
+
+func (f *fakeZoneRepo) FindByPoint(lat, lon float64) (*entity.Zone, error) {
+	return nil, repository.ErrZoneNotFound
+}
+
+func (f *fakeZoneRepo) SetCourierZones(courierID uuid.UUID, zoneIDs []uuid.UUID) error {
+	f.courierZones[courierID] = zoneIDs
+	return nil
+}
+
+const squareGeoJSON = `{"type":"Polygon","coordinates":[[[4.8,52.3],[5.0,52.3],[5.0,52.4],[4.8,52.4],[4.8,52.3]]]}`
+
+func TestZoneService_CreateZone(t *testing.T) {
+	svc := service.NewZoneService(newFakeZoneRepo(), zap.NewNop())
+
+	zone, err := svc.CreateZone(context.Background(), "Center", json.RawMessage(squareGeoJSON), true)
+	assert.NoError(t, err)
+	assert.Equal(t, "Center", zone.Name)
+
+	_, err = svc.CreateZone(context.Background(), "Point", json.RawMessage(`{"type":"Point","coordinates":[4.9,52.37]}`), true)
+	assert.ErrorIs(t, err, service.ErrInvalidZoneGeometry)
+}
+
+func TestZoneService_ImportZones(t *testing.T) {
+	repo := newFakeZoneRepo()
+	svc := service.NewZoneService(repo, zap.NewNop())
+
+	fc := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"North"},"geometry":` + squareGeoJSON + `},
+		{"type":"Feature","properties":{"name":"South"},"geometry":` + squareGeoJSON + `}
+	]}`
+	zones, err := svc.ImportZones(context.Background(), []byte(fc))
+	assert.NoError(t, err)
+	assert.Len(t, zones, 2)
+	assert.Len(t, repo.zones, 2)
+}
+
+func TestZoneService_ImportZones_AllOrNothing(t *testing.T) {
+	repo := newFakeZoneRepo()
+	svc := service.NewZoneService(repo, zap.NewNop())
+
+	fc := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"North"},"geometry":` + squareGeoJSON + `},
+		{"type":"Feature","properties":{},"geometry":` + squareGeoJSON + `}
+	]}`
+	_, err := svc.ImportZones(context.Background(), []byte(fc))
+	assert.ErrorIs(t, err, service.ErrInvalidZoneGeometry)
+	assert.Empty(t, repo.zones)
+}
+
+func TestZoneService_SetCourierZones_UnknownZone(t *testing.T) {
+	svc := service.NewZoneService(newFakeZoneRepo(), zap.NewNop())
+	err := svc.SetCourierZones(context.Background(), uuid.New(), []uuid.UUID{uuid.New()})
+	assert.ErrorIs(t, err, repository.ErrZoneNotFound)
+}